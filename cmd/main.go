@@ -2,14 +2,16 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"os"
 	"os/signal"
-	"strings"
 	"syscall"
 
 	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus"
+	"github.com/surrealwolf/proxmox-ve-mcp/internal/audit"
+	"github.com/surrealwolf/proxmox-ve-mcp/internal/config"
 	"github.com/surrealwolf/proxmox-ve-mcp/internal/mcp"
 	"github.com/surrealwolf/proxmox-ve-mcp/internal/proxmox"
 )
@@ -23,6 +25,7 @@ func init() {
 		FullTimestamp: true,
 	})
 
+	// The level is set again from the loaded configuration in main
 	if level, err := logrus.ParseLevel(os.Getenv("LOG_LEVEL")); err == nil {
 		logrus.SetLevel(level)
 	} else {
@@ -31,64 +34,123 @@ func init() {
 }
 
 func main() {
+	configPath := flag.String("config", os.Getenv("MCP_CONFIG_FILE"), "path to a YAML or TOML config file (env: MCP_CONFIG_FILE)")
+	profile := flag.String("profile", os.Getenv("MCP_PROFILE"), "configuration profile to apply (env: MCP_PROFILE)")
+	toolsSchema := flag.Bool("tools-schema", false, "print the JSON schema of all tools and exit")
+	flag.Parse()
+
+	if *toolsSchema {
+		data, err := mcp.ToolsSchema()
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		os.Stdout.Write(data)
+		return
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
-	// Get configuration from environment
-	baseURL := os.Getenv("PROXMOX_BASE_URL")
-	if baseURL == "" {
-		logrus.Fatal("PROXMOX_BASE_URL environment variable is required")
+	cfg, err := config.Load(*configPath, *profile)
+	if err != nil {
+		// Printed as is so that each validation problem is on its own line
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
-
-	apiUser := os.Getenv("PROXMOX_API_USER")
-	if apiUser == "" {
-		logrus.Fatal("PROXMOX_API_USER environment variable is required")
-	}
-
-	apiTokenID := os.Getenv("PROXMOX_API_TOKEN_ID")
-	if apiTokenID == "" {
-		logrus.Fatal("PROXMOX_API_TOKEN_ID environment variable is required")
+	if level, err := logrus.ParseLevel(cfg.LogLevel); err == nil {
+		logrus.SetLevel(level)
 	}
-
-	apiTokenSecret := os.Getenv("PROXMOX_API_TOKEN_SECRET")
-	if apiTokenSecret == "" {
-		logrus.Fatal("PROXMOX_API_TOKEN_SECRET environment variable is required")
+	if cfg.Profile != "" {
+		logrus.Infof("Using configuration profile %s", cfg.Profile)
 	}
 
-	// Combine user, token ID, and secret into full API token format (user@realm!tokenid=secret)
-	fullApiToken := fmt.Sprintf("%s!%s=%s", apiUser, apiTokenID, apiTokenSecret)
+	// Retry and circuit breaker tuning, applied to every cluster
+	retryPolicy := proxmox.DefaultRetryPolicy()
+	retryPolicy.MaxAttempts = cfg.Client.RetryAttempts
+	retryPolicy.RetryMutating = cfg.Client.RetryMutating
 
-	// Check for SSL verification flag (default is to verify)
-	skipSSLVerify := os.Getenv("PROXMOX_SKIP_SSL_VERIFY") == "true"
-	if skipSSLVerify {
-		logrus.Warn("SSL verification disabled - only use for self-signed certificates")
+	registry := proxmox.NewRegistry()
+	for _, cluster := range cfg.Clusters {
+		if cluster.SkipSSLVerify {
+			logrus.Warnf("SSL verification disabled for cluster %s - only use for self-signed certificates", cluster.Name)
+		}
+		if cluster.Password != "" {
+			logrus.Infof("Using ticket authentication for %s on cluster %s", cluster.APIUser, cluster.Name)
+		}
+		client := cluster.NewClient()
+		client.SetRetryPolicy(retryPolicy)
+		client.SetCircuitBreaker(cfg.Client.CircuitBreakerThreshold, cfg.Client.CircuitBreakerCooldown)
+		client.StartHealthChecks(ctx, cfg.Client.HealthCheckInterval)
+		if err := registry.Add(cluster.Name, cluster.Description, client); err != nil {
+			logrus.Fatal(err)
+		}
+	}
+	if cfg.DefaultCluster != "" {
+		if err := registry.SetDefault(cfg.DefaultCluster); err != nil {
+			logrus.Fatal(err)
+		}
 	}
 
-	proxmoxClient := proxmox.NewClient(baseURL, fullApiToken, skipSSLVerify)
+	auditLog, err := audit.New(audit.Options{
+		File:       cfg.Audit.File,
+		MaxSizeMB:  cfg.Audit.MaxSizeMB,
+		MaxBackups: cfg.Audit.MaxBackups,
+		Syslog:     cfg.Audit.Syslog,
+		SyslogTag:  cfg.Audit.SyslogTag,
+	})
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	defer auditLog.Close()
+	if cfg.Audit.File != "" {
+		logrus.Infof("Auditing tool calls to %s", cfg.Audit.File)
+	}
 
 	// Initialize MCP server
-	server := mcp.NewServer(proxmoxClient)
+	server := mcp.NewServer(registry, mcp.Options{
+		EnableAdvancedTools: cfg.MCP.AdvancedTools(),
+		Tools: mcp.ToolFilter{
+			ReadOnly: cfg.MCP.ReadOnly,
+			Allow:    cfg.MCP.AllowTools,
+			Deny:     cfg.MCP.DenyTools,
+		},
+		ConfirmDestructive:   cfg.MCP.ConfirmDestructive,
+		ConfirmationTTL:      cfg.MCP.ConfirmationTTL,
+		DryRun:               cfg.MCP.DryRun,
+		Audit:                auditLog,
+		Authorization:        accessRules(cfg.MCP.Authorization),
+		RateLimit:            cfg.MCP.RateLimit,
+		RateLimitBurst:       cfg.MCP.RateLimitBurst,
+		ToolTimeout:          cfg.MCP.ToolTimeout,
+		ResourcePollInterval: cfg.MCP.ResourcePollInterval,
+	})
+	if cfg.MCP.ReadOnly {
+		logrus.Info("Read-only mode: only tools that do not change anything are available")
+	}
+	if cfg.MCP.DryRun {
+		logrus.Info("Dry-run mode: mutating tools report the requests they would send without sending them")
+	}
 
 	// Determine transport mode
-	transport := strings.ToLower(os.Getenv("MCP_TRANSPORT"))
-	if transport == "" {
-		transport = "stdio"
-	}
+	transport := cfg.MCP.Transport
 
+	serverErr := make(chan error, 1)
 	switch transport {
 	case "http":
-		httpAddr := os.Getenv("MCP_HTTP_ADDR")
-		if httpAddr == "" {
-			httpAddr = ":8000"
+		httpOpts := mcp.HTTPOptions{
+			BearerTokens:    cfg.MCP.HTTP.AuthTokens,
+			OAuthIssuer:     cfg.MCP.HTTP.OAuthIssuer,
+			OAuthAudience:   cfg.MCP.HTTP.OAuthAudience,
+			OAuthJWKSURL:    cfg.MCP.HTTP.OAuthJWKSURL,
+			TLSCertFile:     cfg.MCP.HTTP.TLSCertFile,
+			TLSKeyFile:      cfg.MCP.HTTP.TLSKeyFile,
+			TLSClientCAFile: cfg.MCP.HTTP.TLSClientCA,
 		}
-		logrus.Infof("Starting Proxmox VE MCP Server on HTTP at %s", httpAddr)
 		go func() {
-			if err := server.ServeHTTP(httpAddr, ctx); err != nil {
-				logrus.WithError(err).Fatal("HTTP Server error")
-			}
+			serverErr <- server.ServeHTTP(ctx, cfg.MCP.HTTP.Addr, httpOpts)
 		}()
 	default:
 		logrus.Info("Starting Proxmox VE MCP Server on stdio transport")
@@ -100,8 +162,34 @@ func main() {
 	}
 
 	// Wait for shutdown signal
-	<-sigChan
+	select {
+	case <-sigChan:
+	case err := <-serverErr:
+		logrus.WithError(err).Fatal("HTTP Server error")
+	}
 	fmt.Println("\nShutting down gracefully...")
 	cancel()
+	if transport == "http" {
+		// Let the HTTP transport drain in-flight requests
+		if err := <-serverErr; err != nil {
+			logrus.WithError(err).Error("HTTP Server shutdown error")
+		}
+	}
 	logrus.Info("Proxmox VE MCP Server stopped")
 }
+
+// accessRules converts the configured authorization rules
+func accessRules(rules []config.AccessRule) []mcp.AccessRule {
+	accessRules := make([]mcp.AccessRule, 0, len(rules))
+	for _, rule := range rules {
+		accessRules = append(accessRules, mcp.AccessRule{
+			Identity: rule.Identity,
+			Tools: mcp.ToolFilter{
+				ReadOnly: rule.ReadOnly,
+				Allow:    rule.AllowTools,
+				Deny:     rule.DenyTools,
+			},
+		})
+	}
+	return accessRules
+}
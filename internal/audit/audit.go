@@ -0,0 +1,177 @@
+// Package audit records every tool invocation as a JSON line, to a rotated
+// file and optionally to syslog.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Outcomes of a tool invocation
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+// Entry is one audited tool invocation
+type Entry struct {
+	Time          time.Time `json:"time"`
+	SessionID     string    `json:"session_id,omitempty"`
+	ClientName    string    `json:"client_name,omitempty"`
+	ClientVersion string    `json:"client_version,omitempty"`
+	// Identity is the authenticated HTTP client (token, OAuth subject or certificate)
+	Identity   string                 `json:"identity,omitempty"`
+	Tool       string                 `json:"tool"`
+	Arguments  map[string]interface{} `json:"arguments,omitempty"`
+	DryRun     bool                   `json:"dry_run,omitempty"`
+	UPIDs      []string               `json:"upids,omitempty"`
+	Outcome    string                 `json:"outcome"`
+	Error      string                 `json:"error,omitempty"`
+	DurationMS int64                  `json:"duration_ms"`
+}
+
+// Options configures where audit entries are written
+type Options struct {
+	// File is the path of the JSON-lines audit log; empty disables it
+	File string
+	// MaxSizeMB rotates the file once it reaches this size; 0 disables rotation
+	MaxSizeMB int
+	// MaxBackups is the number of rotated files kept
+	MaxBackups int
+	// Syslog also sends every entry to the local syslog daemon
+	Syslog bool
+	// SyslogTag tags the syslog messages
+	SyslogTag string
+}
+
+// Logger writes audit entries. A nil Logger discards them.
+type Logger struct {
+	mu      sync.Mutex
+	writers []io.WriteCloser
+	logger  *logrus.Entry
+}
+
+// New opens the audit destinations of opts. It returns nil when none is
+// configured.
+func New(opts Options) (*Logger, error) {
+	l := &Logger{logger: logrus.WithField("component", "Audit")}
+	if opts.File != "" {
+		file, err := openRotatingFile(opts.File, int64(opts.MaxSizeMB)<<20, opts.MaxBackups)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open audit log: %w", err)
+		}
+		l.writers = append(l.writers, file)
+	}
+	if opts.Syslog {
+		writer, err := newSyslogWriter(opts.SyslogTag)
+		if err != nil {
+			l.Close()
+			return nil, fmt.Errorf("failed to connect to syslog: %w", err)
+		}
+		l.writers = append(l.writers, writer)
+	}
+	if len(l.writers) == 0 {
+		return nil, nil
+	}
+	return l, nil
+}
+
+// Record writes entry to every destination. Failures are logged, not
+// returned, so that auditing never fails a tool call.
+func (l *Logger) Record(entry Entry) {
+	if l == nil {
+		return
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		l.logger.WithError(err).Error("Failed to encode audit entry")
+		return
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, writer := range l.writers {
+		if _, err := writer.Write(line); err != nil {
+			l.logger.WithError(err).Error("Failed to write audit entry")
+		}
+	}
+}
+
+// Close closes every destination
+func (l *Logger) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var firstErr error
+	for _, writer := range l.writers {
+		if err := writer.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	l.writers = nil
+	return firstErr
+}
+
+// redacted replaces the values of secret arguments
+const redacted = "[REDACTED]"
+
+// secretKeys are argument names whose values are secret
+var secretKeys = map[string]bool{"password": true, "secret": true, "token": true, "totp": true}
+
+// secretSuffixes end further secret argument names, such as cipassword,
+// token_secret or confirm_token. Matching whole name parts keeps names like
+// tokenid readable.
+var secretSuffixes = []string{"password", "_secret", "-secret", "_token", "-token", "_totp"}
+
+// Redact returns a copy of args with the values of secret arguments, such as
+// passwords and tokens, replaced. Nested objects and lists are redacted too.
+func Redact(args map[string]interface{}) map[string]interface{} {
+	if args == nil {
+		return nil
+	}
+	out := make(map[string]interface{}, len(args))
+	for key, value := range args {
+		if isSecretKey(key) {
+			out[key] = redacted
+			continue
+		}
+		out[key] = redactValue(value)
+	}
+	return out
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return Redact(v)
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = redactValue(item)
+		}
+		return items
+	}
+	return value
+}
+
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	if secretKeys[key] {
+		return true
+	}
+	for _, suffix := range secretSuffixes {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
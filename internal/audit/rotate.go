@@ -0,0 +1,110 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+)
+
+// rotatingFile appends to a file and rotates it to path.1, path.2, ... once
+// it would exceed maxSize bytes
+type rotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+func openRotatingFile(path string, maxSize int64, maxBackups int) (*rotatingFile, error) {
+	f := &rotatingFile{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// open opens the current file for appending. Audit logs may hold sensitive
+// details, so they are only readable by the owner.
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+// Write implements io.Writer. Callers serialize writes.
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	var rotateErr error
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			rotateErr = fmt.Errorf("entry written, but rotating %s failed: %w", f.path, err)
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	if err != nil {
+		return n, err
+	}
+	return n, rotateErr
+}
+
+// rotate shifts the backups, dropping the oldest, and starts a new file. The
+// current file is renamed while still open, so that when rotation fails the
+// log keeps appending to it.
+func (f *rotatingFile) rotate() error {
+	if f.maxBackups == 0 {
+		return f.replace()
+	}
+
+	os.Remove(backupName(f.path, f.maxBackups))
+	for i := f.maxBackups - 1; i >= 1; i-- {
+		os.Rename(backupName(f.path, i), backupName(f.path, i+1))
+	}
+	// The file is already gone when opening its successor failed before
+	if err := os.Rename(f.path, backupName(f.path, 1)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	old := f.file
+	if err := f.open(); err != nil {
+		// Keep writing to the rotated file rather than losing entries
+		return err
+	}
+	return old.Close()
+}
+
+// replace starts a new file when no backups are kept. The new file is created
+// beside the current one and renamed over it, so that when either step fails
+// the log keeps appending to the current file, still at its path.
+func (f *rotatingFile) replace() error {
+	next := f.path + ".new"
+	file, err := os.OpenFile(next, os.O_CREATE|os.O_TRUNC|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(next, f.path); err != nil {
+		file.Close()
+		os.Remove(next)
+		return err
+	}
+
+	old := f.file
+	f.file, f.size = file, 0
+	return old.Close()
+}
+
+// Close implements io.Closer
+func (f *rotatingFile) Close() error {
+	return f.file.Close()
+}
+
+func backupName(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
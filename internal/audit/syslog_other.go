@@ -0,0 +1,13 @@
+//go:build windows || plan9
+
+package audit
+
+import (
+	"errors"
+	"io"
+)
+
+// newSyslogWriter reports that syslog is not available on this platform
+func newSyslogWriter(tag string) (io.WriteCloser, error) {
+	return nil, errors.New("syslog is not supported on this platform")
+}
@@ -0,0 +1,100 @@
+// Package config loads the configuration of the Proxmox VE MCP server
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/surrealwolf/proxmox-ve-mcp/internal/proxmox"
+)
+
+// Cluster describes how to reach one Proxmox cluster
+type Cluster struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	BaseURL     string `yaml:"base_url"`
+	// BaseURLs lists further member API URLs to fail over to
+	BaseURLs       []string `yaml:"base_urls"`
+	APIUser        string   `yaml:"api_user"`
+	APITokenID     string   `yaml:"api_token_id"`
+	APITokenSecret string   `yaml:"api_token_secret"`
+	Password       string   `yaml:"password"`
+	TOTPSecret     string   `yaml:"totp_secret"`
+	SkipSSLVerify  bool     `yaml:"skip_ssl_verify"`
+	// The secrets may be read from files instead, e.g. Docker or Kubernetes secrets
+	APITokenSecretFile string `yaml:"api_token_secret_file"`
+	PasswordFile       string `yaml:"password_file"`
+	TOTPSecretFile     string `yaml:"totp_secret_file"`
+}
+
+// ClustersFile is the layout of the clusters file
+type ClustersFile struct {
+	Default  string    `yaml:"default"`
+	Clusters []Cluster `yaml:"clusters"`
+}
+
+// LoadClustersFile reads a clusters file. The clusters are validated by Load
+// together with the rest of the configuration.
+func LoadClustersFile(path string) (*ClustersFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read clusters file: %w", err)
+	}
+
+	file := &ClustersFile{}
+	if err := decodeStrict(data, file); err != nil {
+		return nil, fmt.Errorf("failed to parse clusters file %s: %w", path, err)
+	}
+	return file, nil
+}
+
+// Validate checks that the cluster has an endpoint and complete credentials
+func (c Cluster) Validate() error {
+	switch {
+	case c.Name == "":
+		return fmt.Errorf("name is required")
+	case c.BaseURL == "" && len(c.BaseURLs) == 0:
+		return fmt.Errorf("%s: base_url or base_urls is required", c.Name)
+	case c.APIUser == "":
+		return fmt.Errorf("%s: api_user is required", c.Name)
+	case c.Password == "" && (c.APITokenID == "" || c.APITokenSecret == ""):
+		return fmt.Errorf("%s: api_token_id and api_token_secret, or password, are required", c.Name)
+	}
+	for _, u := range c.URLs() {
+		if err := validateURL(u); err != nil {
+			return fmt.Errorf("%s: base_url: %w", c.Name, err)
+		}
+	}
+	return nil
+}
+
+// URLs returns base_url followed by base_urls, without duplicates
+func (c Cluster) URLs() []string {
+	var urls []string
+	seen := map[string]bool{}
+	for _, u := range append([]string{c.BaseURL}, c.BaseURLs...) {
+		if u != "" && !seen[u] {
+			seen[u] = true
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// NewClient creates a Proxmox client for the cluster, using ticket
+// authentication when a password is configured and the API token otherwise
+func (c Cluster) NewClient() *proxmox.Client {
+	urls := c.URLs()
+
+	var client *proxmox.Client
+	if c.Password != "" {
+		auth := proxmox.NewTicketAuth(urls[0], c.APIUser, c.Password, c.TOTPSecret, c.SkipSSLVerify)
+		client = proxmox.NewClientWithAuth(urls[0], auth, c.SkipSSLVerify)
+	} else {
+		// Combine user, token ID, and secret into full API token format (user@realm!tokenid=secret)
+		token := fmt.Sprintf("%s!%s=%s", c.APIUser, c.APITokenID, c.APITokenSecret)
+		client = proxmox.NewClient(urls[0], token, c.SkipSSLVerify)
+	}
+	client.SetEndpoints(urls)
+	return client
+}
@@ -0,0 +1,613 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the complete server configuration. It is built from the defaults,
+// the config file, the selected profile and the environment, in that order.
+type Config struct {
+	// Profile selects an entry of Profiles; MCP_PROFILE or --profile override it
+	Profile        string    `yaml:"profile"`
+	LogLevel       string    `yaml:"log_level"`
+	DefaultCluster string    `yaml:"default_cluster"`
+	Clusters       []Cluster `yaml:"clusters"`
+	Client         Client    `yaml:"client"`
+	MCP            MCP       `yaml:"mcp"`
+	Audit          Audit     `yaml:"audit"`
+	// Profiles are partial configurations applied on top of the file
+	Profiles map[string]yaml.Node `yaml:"profiles"`
+}
+
+// Client tunes the Proxmox API clients of every cluster
+type Client struct {
+	RetryAttempts           int           `yaml:"retry_attempts"`
+	RetryMutating           bool          `yaml:"retry_mutating"`
+	CircuitBreakerThreshold int           `yaml:"circuit_breaker_threshold"`
+	CircuitBreakerCooldown  time.Duration `yaml:"circuit_breaker_cooldown"`
+	HealthCheckInterval     time.Duration `yaml:"health_check_interval"`
+}
+
+// MCP configures the MCP side of the server
+type MCP struct {
+	// Transport is stdio or http
+	Transport string `yaml:"transport"`
+	// ToolsMode is default or all; it takes precedence over EnableAdvancedTools
+	ToolsMode           string `yaml:"tools_mode"`
+	EnableAdvancedTools bool   `yaml:"enable_advanced_tools"`
+	// ReadOnly registers only tools that do not change anything
+	ReadOnly bool `yaml:"read_only"`
+	// AllowTools and DenyTools are tool names or globs such as delete_*
+	AllowTools []string `yaml:"allow_tools"`
+	DenyTools  []string `yaml:"deny_tools"`
+	// ConfirmDestructive makes destructive tools require confirmation, valid
+	// for ConfirmationTTL
+	ConfirmDestructive bool          `yaml:"confirm_destructive"`
+	ConfirmationTTL    time.Duration `yaml:"confirmation_ttl"`
+	// DryRun makes every mutating tool only report what it would do
+	DryRun bool `yaml:"dry_run"`
+	// ToolTimeout bounds every tool call; 0 disables it
+	ToolTimeout time.Duration `yaml:"tool_timeout"`
+	// ResourcePollInterval is how often subscribed resources are checked for
+	// changes; 0 disables resource subscriptions
+	ResourcePollInterval time.Duration `yaml:"resource_poll_interval"`
+	// RateLimit is the tool calls per minute allowed per client; 0 disables it
+	RateLimit      int `yaml:"rate_limit"`
+	RateLimitBurst int `yaml:"rate_limit_burst"`
+	// Authorization restricts the tools of HTTP clients by identity
+	Authorization []AccessRule `yaml:"authorization"`
+	HTTP          HTTP         `yaml:"http"`
+}
+
+// AccessRule grants the HTTP clients matching Identity a subset of the tools.
+// The first matching rule applies; clients matching no rule are denied.
+type AccessRule struct {
+	// Identity is a glob such as "jwt:alice", "cert:ops-*", "token" or "*"
+	Identity   string   `yaml:"identity"`
+	ReadOnly   bool     `yaml:"read_only"`
+	AllowTools []string `yaml:"allow_tools"`
+	DenyTools  []string `yaml:"deny_tools"`
+}
+
+// HTTP configures the HTTP transport
+type HTTP struct {
+	Addr           string   `yaml:"addr"`
+	AuthTokens     []string `yaml:"auth_tokens"`
+	AuthTokensFile string   `yaml:"auth_tokens_file"`
+	OAuthIssuer    string   `yaml:"oauth_issuer"`
+	OAuthAudience  string   `yaml:"oauth_audience"`
+	OAuthJWKSURL   string   `yaml:"oauth_jwks_url"`
+	TLSCertFile    string   `yaml:"tls_cert_file"`
+	TLSKeyFile     string   `yaml:"tls_key_file"`
+	TLSClientCA    string   `yaml:"tls_client_ca_file"`
+}
+
+// Audit configures the audit log of tool calls
+type Audit struct {
+	// File is the JSON-lines audit log; empty disables it
+	File       string `yaml:"file"`
+	MaxSizeMB  int    `yaml:"max_size_mb"`
+	MaxBackups int    `yaml:"max_backups"`
+	// Syslog also sends the entries to the local syslog daemon
+	Syslog    bool   `yaml:"syslog"`
+	SyslogTag string `yaml:"syslog_tag"`
+}
+
+// AdvancedTools reports whether the advanced tools are registered
+func (m MCP) AdvancedTools() bool {
+	switch m.ToolsMode {
+	case "all":
+		return true
+	case "default":
+		return false
+	}
+	return m.EnableAdvancedTools
+}
+
+// Default returns the configuration used when nothing is configured
+func Default() *Config {
+	return &Config{
+		LogLevel: "info",
+		Client: Client{
+			RetryAttempts:           3,
+			RetryMutating:           true,
+			CircuitBreakerThreshold: 5,
+			CircuitBreakerCooldown:  30 * time.Second,
+			HealthCheckInterval:     30 * time.Second,
+		},
+		MCP: MCP{
+			Transport:            "stdio",
+			ConfirmDestructive:   true,
+			ConfirmationTTL:      2 * time.Minute,
+			RateLimitBurst:       10,
+			ResourcePollInterval: 15 * time.Second,
+			HTTP:                 HTTP{Addr: ":8000"},
+		},
+		Audit: Audit{
+			MaxSizeMB:  100,
+			MaxBackups: 5,
+			SyslogTag:  "proxmox-ve-mcp",
+		},
+	}
+}
+
+// Load builds the configuration from the config file at path (YAML, or TOML
+// for a .toml extension; optional), the named profile and the environment,
+// then resolves secret files and validates the result. profile may be empty
+// to use the profile named in the file, if any.
+func Load(path, profile string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		if err := cfg.readFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if profile == "" {
+		profile = cfg.Profile
+	}
+	if profile != "" {
+		if err := cfg.applyProfile(profile); err != nil {
+			return nil, err
+		}
+	}
+
+	var errs []error
+	cfg.applyEnv(&errs)
+	cfg.normalize()
+
+	// Clusters of the clusters file, which PROXMOX_* variables do not override
+	if clustersPath := os.Getenv("PROXMOX_CLUSTERS_FILE"); clustersPath != "" {
+		file, err := LoadClustersFile(clustersPath)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Clusters = append(cfg.Clusters, file.Clusters...)
+		if file.Default != "" {
+			cfg.DefaultCluster = file.Default
+		}
+	}
+
+	cfg.resolveSecrets(&errs)
+	if len(errs) > 0 {
+		return nil, &ValidationError{Errors: errs}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// normalize lower-cases the settings whose values are matched
+// case-insensitively, as MCP_TRANSPORT=HTTP has always been accepted
+func (c *Config) normalize() {
+	c.MCP.Transport = strings.ToLower(strings.TrimSpace(c.MCP.Transport))
+	c.MCP.ToolsMode = strings.ToLower(strings.TrimSpace(c.MCP.ToolsMode))
+}
+
+// readFile decodes the config file over cfg
+func (c *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		// TOML is converted to YAML so both formats share the strict decoder
+		var doc map[string]interface{}
+		if _, err := toml.Decode(string(data), &doc); err != nil {
+			return fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+		if data, err = yaml.Marshal(doc); err != nil {
+			return fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	}
+
+	if err := decodeStrict(data, c); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// applyProfile decodes the named profile over c
+func (c *Config) applyProfile(name string) error {
+	node, ok := c.Profiles[name]
+	if !ok {
+		names := make([]string, 0, len(c.Profiles))
+		for profile := range c.Profiles {
+			names = append(names, profile)
+		}
+		return fmt.Errorf("unknown profile %q (available: %v)", name, names)
+	}
+
+	// Re-encode the profile so that unknown keys are rejected like in the file
+	data, err := yaml.Marshal(&node)
+	if err != nil {
+		return fmt.Errorf("profile %s: %w", name, err)
+	}
+	if err := decodeStrict(data, c); err != nil {
+		return fmt.Errorf("profile %s: %w", name, err)
+	}
+	c.Profile = name
+	return nil
+}
+
+// decodeStrict decodes YAML into v, rejecting keys that v does not define
+func decodeStrict(data []byte, v interface{}) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// applyEnv overrides the configuration with the environment. Every variable
+// NAME may instead be given as NAME_FILE, naming a file holding the value.
+func (c *Config) applyEnv(errs *[]error) {
+	str := func(name string, dst *string) {
+		if value, ok := lookupEnv(name, errs); ok {
+			*dst = value
+		}
+	}
+	boolean := func(name string, dst *bool) {
+		if value, ok := lookupEnv(name, errs); ok {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				*errs = append(*errs, fmt.Errorf("%s: %q is not a boolean (true or false)", name, value))
+				return
+			}
+			*dst = b
+		}
+	}
+	integer := func(name string, dst *int) {
+		if value, ok := lookupEnv(name, errs); ok {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				*errs = append(*errs, fmt.Errorf("%s: %q is not a number", name, value))
+				return
+			}
+			*dst = n
+		}
+	}
+	duration := func(name string, dst *time.Duration) {
+		if value, ok := lookupEnv(name, errs); ok {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				*errs = append(*errs, fmt.Errorf("%s: %q is not a duration", name, value))
+				return
+			}
+			*dst = d
+		}
+	}
+	list := func(name string, dst *[]string) {
+		if value, ok := lookupEnv(name, errs); ok {
+			*dst = splitList(value)
+		}
+	}
+
+	str("LOG_LEVEL", &c.LogLevel)
+
+	integer("PROXMOX_RETRY_ATTEMPTS", &c.Client.RetryAttempts)
+	boolean("PROXMOX_RETRY_MUTATING", &c.Client.RetryMutating)
+	integer("PROXMOX_CIRCUIT_BREAKER_THRESHOLD", &c.Client.CircuitBreakerThreshold)
+	duration("PROXMOX_CIRCUIT_BREAKER_COOLDOWN", &c.Client.CircuitBreakerCooldown)
+	duration("PROXMOX_HEALTH_CHECK_INTERVAL", &c.Client.HealthCheckInterval)
+
+	str("MCP_TRANSPORT", &c.MCP.Transport)
+	str("MCP_TOOLS_MODE", &c.MCP.ToolsMode)
+	boolean("MCP_ENABLE_ADVANCED_TOOLS", &c.MCP.EnableAdvancedTools)
+	boolean("MCP_READ_ONLY", &c.MCP.ReadOnly)
+	list("MCP_ALLOW_TOOLS", &c.MCP.AllowTools)
+	list("MCP_DENY_TOOLS", &c.MCP.DenyTools)
+	boolean("MCP_CONFIRM_DESTRUCTIVE", &c.MCP.ConfirmDestructive)
+	duration("MCP_CONFIRMATION_TTL", &c.MCP.ConfirmationTTL)
+	boolean("MCP_DRY_RUN", &c.MCP.DryRun)
+	duration("MCP_TOOL_TIMEOUT", &c.MCP.ToolTimeout)
+	duration("MCP_RESOURCE_POLL_INTERVAL", &c.MCP.ResourcePollInterval)
+	integer("MCP_RATE_LIMIT", &c.MCP.RateLimit)
+	integer("MCP_RATE_LIMIT_BURST", &c.MCP.RateLimitBurst)
+	str("MCP_HTTP_ADDR", &c.MCP.HTTP.Addr)
+	list("MCP_HTTP_AUTH_TOKENS", &c.MCP.HTTP.AuthTokens)
+	str("MCP_HTTP_OAUTH_ISSUER", &c.MCP.HTTP.OAuthIssuer)
+	str("MCP_HTTP_OAUTH_AUDIENCE", &c.MCP.HTTP.OAuthAudience)
+	str("MCP_HTTP_OAUTH_JWKS_URL", &c.MCP.HTTP.OAuthJWKSURL)
+	str("MCP_HTTP_TLS_CERT_FILE", &c.MCP.HTTP.TLSCertFile)
+	str("MCP_HTTP_TLS_KEY_FILE", &c.MCP.HTTP.TLSKeyFile)
+	str("MCP_HTTP_TLS_CLIENT_CA_FILE", &c.MCP.HTTP.TLSClientCA)
+
+	str("MCP_AUDIT_LOG_FILE", &c.Audit.File)
+	integer("MCP_AUDIT_MAX_SIZE_MB", &c.Audit.MaxSizeMB)
+	integer("MCP_AUDIT_MAX_BACKUPS", &c.Audit.MaxBackups)
+	boolean("MCP_AUDIT_SYSLOG", &c.Audit.Syslog)
+	str("MCP_AUDIT_SYSLOG_TAG", &c.Audit.SyslogTag)
+
+	// The PROXMOX_* connection variables describe the cluster named by
+	// PROXMOX_CLUSTER_NAME, the file's only cluster, or "default"
+	name, _ := lookupEnv("PROXMOX_CLUSTER_NAME", errs)
+	if name == "" {
+		name = "default"
+		if len(c.Clusters) == 1 {
+			name = c.Clusters[0].Name
+		}
+	}
+	cluster := c.cluster(name)
+	if cluster == nil {
+		baseURLs, _ := lookupEnv("PROXMOX_BASE_URL", errs)
+		if baseURLs == "" {
+			return
+		}
+		// A cluster defined only by the environment is the first, and thus
+		// default, cluster
+		c.Clusters = append([]Cluster{{Name: name}}, c.Clusters...)
+		cluster = &c.Clusters[0]
+	}
+
+	// PROXMOX_BASE_URL may list several member URLs separated by commas
+	if value, ok := lookupEnv("PROXMOX_BASE_URL", errs); ok {
+		urls := splitList(value)
+		cluster.BaseURL, cluster.BaseURLs = "", nil
+		if len(urls) > 0 {
+			cluster.BaseURL, cluster.BaseURLs = urls[0], urls[1:]
+		}
+	}
+	str("PROXMOX_API_USER", &cluster.APIUser)
+	str("PROXMOX_API_TOKEN_ID", &cluster.APITokenID)
+	str("PROXMOX_API_TOKEN_SECRET", &cluster.APITokenSecret)
+	str("PROXMOX_PASSWORD", &cluster.Password)
+	str("PROXMOX_TOTP_SECRET", &cluster.TOTPSecret)
+	boolean("PROXMOX_SKIP_SSL_VERIFY", &cluster.SkipSSLVerify)
+}
+
+// cluster returns the named cluster, or nil
+func (c *Config) cluster(name string) *Cluster {
+	for i := range c.Clusters {
+		if c.Clusters[i].Name == name {
+			return &c.Clusters[i]
+		}
+	}
+	return nil
+}
+
+// resolveSecrets reads the secrets given as files
+func (c *Config) resolveSecrets(errs *[]error) {
+	for i := range c.Clusters {
+		cluster := &c.Clusters[i]
+		prefix := fmt.Sprintf("clusters[%d]", i)
+		readSecret(prefix+".api_token_secret_file", cluster.APITokenSecretFile, &cluster.APITokenSecret, errs)
+		readSecret(prefix+".password_file", cluster.PasswordFile, &cluster.Password, errs)
+		readSecret(prefix+".totp_secret_file", cluster.TOTPSecretFile, &cluster.TOTPSecret, errs)
+	}
+
+	if c.MCP.HTTP.AuthTokensFile != "" && len(c.MCP.HTTP.AuthTokens) == 0 {
+		var tokens string
+		readSecret("mcp.http.auth_tokens_file", c.MCP.HTTP.AuthTokensFile, &tokens, errs)
+		// One token per line or comma-separated
+		c.MCP.HTTP.AuthTokens = splitList(strings.ReplaceAll(tokens, "\n", ","))
+	}
+}
+
+// readSecret sets *dst to the content of path unless a value is already set
+func readSecret(field, path string, dst *string, errs *[]error) {
+	if path == "" || *dst != "" {
+		return
+	}
+	value, err := readSecretFile(path)
+	if err != nil {
+		*errs = append(*errs, fmt.Errorf("%s: %w", field, err))
+		return
+	}
+	*dst = value
+}
+
+// readSecretFile reads a secret, dropping the trailing newline most editors
+// and secret stores add
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// lookupEnv returns the value of the environment variable name, or the
+// content of the file named by name_FILE
+func lookupEnv(name string, errs *[]error) (string, bool) {
+	if value, ok := os.LookupEnv(name); ok {
+		return value, true
+	}
+	path, ok := os.LookupEnv(name + "_FILE")
+	if !ok || path == "" {
+		return "", false
+	}
+	value, err := readSecretFile(path)
+	if err != nil {
+		*errs = append(*errs, fmt.Errorf("%s_FILE: %w", name, err))
+		return "", false
+	}
+	return value, true
+}
+
+// splitList parses a comma-separated value, ignoring empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// ValidationError lists every problem found in the configuration
+type ValidationError struct {
+	Errors []error
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	b.WriteString("invalid configuration:")
+	for _, err := range e.Errors {
+		b.WriteString("\n  - ")
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// Validate checks the configuration and reports all problems at once
+func (c *Config) Validate() error {
+	var errs []error
+	add := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if _, err := logrus.ParseLevel(c.LogLevel); err != nil {
+		add("log_level: %q is not a valid level (debug, info, warn, error)", c.LogLevel)
+	}
+
+	if len(c.Clusters) == 0 {
+		add("clusters: at least one cluster is required (set PROXMOX_BASE_URL or define clusters in the config file)")
+	}
+	seen := map[string]bool{}
+	for i, cluster := range c.Clusters {
+		if err := cluster.Validate(); err != nil {
+			add("clusters[%d]: %v", i, err)
+		}
+		if cluster.Name != "" && seen[cluster.Name] {
+			add("clusters[%d]: cluster %q is defined more than once", i, cluster.Name)
+		}
+		seen[cluster.Name] = true
+	}
+	if c.DefaultCluster != "" && !seen[c.DefaultCluster] {
+		add("default_cluster: cluster %q is not defined", c.DefaultCluster)
+	}
+
+	if c.Client.RetryAttempts < 1 {
+		add("client.retry_attempts: must be at least 1")
+	}
+	if c.Client.CircuitBreakerThreshold < 0 {
+		add("client.circuit_breaker_threshold: must not be negative (0 disables the breaker)")
+	}
+	if c.Client.CircuitBreakerCooldown < 0 {
+		add("client.circuit_breaker_cooldown: must not be negative")
+	}
+	if c.Client.HealthCheckInterval < 0 {
+		add("client.health_check_interval: must not be negative (0 disables health checks)")
+	}
+
+	switch c.MCP.Transport {
+	case "stdio", "http":
+	default:
+		add("mcp.transport: %q is not supported (stdio, http)", c.MCP.Transport)
+	}
+	if c.MCP.ConfirmDestructive && c.MCP.ConfirmationTTL <= 0 {
+		add("mcp.confirmation_ttl: must be positive")
+	}
+	switch c.MCP.ToolsMode {
+	case "", "default", "all":
+	default:
+		add("mcp.tools_mode: %q is not supported (default, all)", c.MCP.ToolsMode)
+	}
+	if c.MCP.ToolTimeout < 0 {
+		add("mcp.tool_timeout: must not be negative (0 disables it)")
+	}
+	if c.MCP.ResourcePollInterval < 0 {
+		add("mcp.resource_poll_interval: must not be negative (0 disables subscriptions)")
+	}
+	if c.MCP.RateLimit < 0 {
+		add("mcp.rate_limit: must not be negative (0 disables it)")
+	}
+	if c.MCP.RateLimit > 0 && c.MCP.RateLimitBurst < 1 {
+		add("mcp.rate_limit_burst: must be at least 1")
+	}
+	type patternField struct {
+		name     string
+		patterns []string
+	}
+	patternFields := []patternField{
+		{"mcp.allow_tools", c.MCP.AllowTools},
+		{"mcp.deny_tools", c.MCP.DenyTools},
+	}
+	for i, rule := range c.MCP.Authorization {
+		prefix := fmt.Sprintf("mcp.authorization[%d]", i)
+		if rule.Identity == "" {
+			add("%s.identity: is required (use \"*\" to match every client)", prefix)
+		}
+		patternFields = append(patternFields,
+			patternField{prefix + ".identity", []string{rule.Identity}},
+			patternField{prefix + ".allow_tools", rule.AllowTools},
+			patternField{prefix + ".deny_tools", rule.DenyTools},
+		)
+	}
+	for _, field := range patternFields {
+		for _, pattern := range field.patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				add("%s: invalid pattern %q", field.name, pattern)
+			}
+		}
+	}
+	httpCfg := c.MCP.HTTP
+	if (httpCfg.TLSCertFile == "") != (httpCfg.TLSKeyFile == "") {
+		add("mcp.http: tls_cert_file and tls_key_file must be set together")
+	}
+	if httpCfg.TLSClientCA != "" && httpCfg.TLSCertFile == "" {
+		add("mcp.http.tls_client_ca_file: requires tls_cert_file and tls_key_file")
+	}
+	if (httpCfg.OAuthAudience != "" || httpCfg.OAuthJWKSURL != "") && httpCfg.OAuthIssuer == "" {
+		add("mcp.http: oauth_audience and oauth_jwks_url require oauth_issuer")
+	}
+	if httpCfg.OAuthIssuer != "" && httpCfg.OAuthAudience == "" {
+		add("mcp.http.oauth_audience: required with oauth_issuer, or tokens the issuer minted for other clients would be accepted")
+	}
+	for _, field := range []struct{ name, value string }{
+		{"mcp.http.oauth_issuer", httpCfg.OAuthIssuer},
+		{"mcp.http.oauth_jwks_url", httpCfg.OAuthJWKSURL},
+	} {
+		if field.value != "" {
+			if err := validateURL(field.value); err != nil {
+				add("%s: %v", field.name, err)
+			}
+		}
+	}
+
+	if c.Audit.MaxSizeMB < 0 {
+		add("audit.max_size_mb: must not be negative (0 disables rotation)")
+	}
+	if c.Audit.MaxBackups < 0 {
+		add("audit.max_backups: must not be negative")
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// validateURL checks that value is an absolute http(s) URL
+func validateURL(value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return fmt.Errorf("%q is not a valid URL", value)
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("%q must be an http(s) URL with a host", value)
+	}
+	return nil
+}
@@ -0,0 +1,397 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Tool arguments are declared as Go structs. Every exported field with a json
+// tag is an argument; embedded structs contribute their fields. These tags
+// describe it in the input schema and drive validation:
+//
+//	description:"..."  the argument's description
+//	required:"true"    the argument must be present (and strings non-empty)
+//	enum:"a,b,c"       the allowed values
+//	default:"..."      the value used when the argument is omitted
+//	minimum:"n"        the smallest allowed number
+//	maximum:"n"        the largest allowed number
+//	guest:"role"       the argument identifies an existing guest: its node,
+//	                   its ID (qemu or lxc), its name or a tag; see guests.go
+
+// argsSchema is the input schema of a tool, derived from its argument struct
+type argsSchema struct {
+	fields []argField
+}
+
+// argField describes one argument
+type argField struct {
+	name        string
+	kind        string // JSON Schema type
+	itemKind    string // element type of arrays
+	description string
+	required    bool
+	enum        []string
+	def         interface{}
+	minimum     *float64
+	maximum     *float64
+	guest       string // role in identifying a guest
+}
+
+// schemaOf derives the schema of an argument struct type. Invalid tags are
+// programming errors and panic at registration.
+func schemaOf(t reflect.Type) *argsSchema {
+	schema := &argsSchema{}
+	schema.addFields(t)
+	return schema
+}
+
+func (a *argsSchema) addFields(t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, hasTag := field.Tag.Lookup("json")
+		if field.Anonymous && !hasTag {
+			a.addFields(field.Type)
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if !field.IsExported() || name == "" || name == "-" {
+			continue
+		}
+
+		arg := argField{
+			name:        name,
+			kind:        jsonKind(field.Type),
+			description: field.Tag.Get("description"),
+			required:    field.Tag.Get("required") == "true",
+			guest:       field.Tag.Get("guest"),
+		}
+		if arg.kind == "array" {
+			arg.itemKind = jsonKind(field.Type.Elem())
+		}
+		if enum := field.Tag.Get("enum"); enum != "" {
+			arg.enum = strings.Split(enum, ",")
+		}
+		if def, ok := field.Tag.Lookup("default"); ok {
+			value, err := coerce(arg.kind, def)
+			if err != nil {
+				panic(fmt.Sprintf("%s.%s: invalid default %q", t.Name(), field.Name, def))
+			}
+			arg.def = value
+		}
+		arg.minimum = parseBound(t, field, "minimum")
+		arg.maximum = parseBound(t, field, "maximum")
+		a.fields = append(a.fields, arg)
+	}
+}
+
+func parseBound(t reflect.Type, field reflect.StructField, tag string) *float64 {
+	value, ok := field.Tag.Lookup(tag)
+	if !ok {
+		return nil
+	}
+	bound, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		panic(fmt.Sprintf("%s.%s: invalid %s %q", t.Name(), field.Name, tag, value))
+	}
+	return &bound
+}
+
+// jsonKind maps a Go type to its JSON Schema type
+func jsonKind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// has reports whether the tool takes the argument name
+func (a *argsSchema) has(name string) bool {
+	for _, field := range a.fields {
+		if field.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Properties returns the JSON Schema properties of the arguments
+func (a *argsSchema) Properties() map[string]any {
+	properties := make(map[string]any, len(a.fields))
+	for _, field := range a.fields {
+		property := map[string]any{"type": field.kind}
+		if field.description != "" {
+			property["description"] = field.description
+		}
+		if field.itemKind != "" {
+			property["items"] = map[string]any{"type": field.itemKind}
+		}
+		if field.enum != nil {
+			property["enum"] = field.enum
+		}
+		if field.def != nil {
+			property["default"] = field.def
+		}
+		if field.minimum != nil {
+			property["minimum"] = *field.minimum
+		}
+		if field.maximum != nil {
+			property["maximum"] = *field.maximum
+		}
+		properties[field.name] = property
+	}
+	return properties
+}
+
+// Required returns the names of the required arguments
+func (a *argsSchema) Required() []string {
+	var required []string
+	for _, field := range a.fields {
+		if field.required {
+			required = append(required, field.name)
+		}
+	}
+	sort.Strings(required)
+	return required
+}
+
+// ArgumentError lists every problem found in the arguments of a tool call
+type ArgumentError struct {
+	Problems []string
+}
+
+// Error implements the error interface
+func (e *ArgumentError) Error() string {
+	return "invalid arguments: " + strings.Join(e.Problems, "; ")
+}
+
+// validate checks arguments against the schema and returns them with values
+// coerced to their declared types and defaults filled in. Numbers and booleans
+// sent as strings are accepted, as are lists sent as comma- or space-separated
+// strings.
+func (a *argsSchema) validate(arguments map[string]any) (map[string]any, error) {
+	values := make(map[string]any, len(a.fields))
+	var problems []string
+	for _, field := range a.fields {
+		raw, present := arguments[field.name]
+		if !present || raw == nil {
+			if field.required {
+				problems = append(problems, field.name+" is required")
+			} else if field.def != nil {
+				values[field.name] = field.def
+			}
+			continue
+		}
+
+		value, err := coerce(field.kind, raw)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s %v", field.name, err))
+			continue
+		}
+		if field.required && isEmpty(value) {
+			problems = append(problems, field.name+" is required")
+			continue
+		}
+		if problem := field.check(value); problem != "" {
+			problems = append(problems, problem)
+			continue
+		}
+		values[field.name] = value
+	}
+
+	if len(problems) > 0 {
+		return nil, &ArgumentError{Problems: problems}
+	}
+	return values, nil
+}
+
+// check applies the enum and range constraints of the field to value
+func (f argField) check(value any) string {
+	if f.enum != nil {
+		text := fmt.Sprint(value)
+		allowed := false
+		for _, option := range f.enum {
+			if option == text {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Sprintf("%s must be one of %s", f.name, strings.Join(f.enum, ", "))
+		}
+	}
+	if number, ok := value.(float64); ok {
+		if f.minimum != nil && number < *f.minimum {
+			return fmt.Sprintf("%s must be at least %g", f.name, *f.minimum)
+		}
+		if f.maximum != nil && number > *f.maximum {
+			return fmt.Sprintf("%s must be at most %g", f.name, *f.maximum)
+		}
+	}
+	return ""
+}
+
+// coerce converts a JSON value to the given JSON Schema type
+func coerce(kind string, raw any) (any, error) {
+	switch kind {
+	case "string":
+		switch v := raw.(type) {
+		case string:
+			return v, nil
+		case float64, bool, json.Number:
+			return fmt.Sprint(v), nil
+		}
+		return nil, fmt.Errorf("must be a string")
+	case "integer", "number":
+		var number float64
+		switch v := raw.(type) {
+		case float64:
+			number = v
+		case int:
+			number = float64(v)
+		case json.Number:
+			n, err := v.Float64()
+			if err != nil {
+				return nil, fmt.Errorf("must be a number")
+			}
+			number = n
+		case string:
+			n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, fmt.Errorf("must be a number")
+			}
+			number = n
+		default:
+			return nil, fmt.Errorf("must be a number")
+		}
+		if kind == "integer" && number != math.Trunc(number) {
+			return nil, fmt.Errorf("must be an integer")
+		}
+		return number, nil
+	case "boolean":
+		switch v := raw.(type) {
+		case bool:
+			return v, nil
+		case float64:
+			if v == 0 || v == 1 {
+				return v == 1, nil
+			}
+		case string:
+			if b, err := strconv.ParseBool(v); err == nil {
+				return b, nil
+			}
+		}
+		return nil, fmt.Errorf("must be a boolean")
+	case "array":
+		switch v := raw.(type) {
+		case []any:
+			return v, nil
+		case string:
+			var items []any
+			for _, item := range strings.FieldsFunc(v, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+				items = append(items, item)
+			}
+			return items, nil
+		}
+		return nil, fmt.Errorf("must be a list")
+	default:
+		if v, ok := raw.(map[string]any); ok {
+			return v, nil
+		}
+		return nil, fmt.Errorf("must be an object")
+	}
+}
+
+func isEmpty(value any) bool {
+	switch v := value.(type) {
+	case string:
+		return v == ""
+	case []any:
+		return len(v) == 0
+	case map[string]any:
+		return len(v) == 0
+	}
+	return false
+}
+
+// bind validates arguments and decodes them into target, a pointer to the
+// argument struct the schema was derived from
+func (a *argsSchema) bind(arguments map[string]any, target any) error {
+	values, err := a.validate(arguments)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return &ArgumentError{Problems: []string{err.Error()}}
+	}
+	return nil
+}
+
+// toolHandler is a tool handler together with the schema of its arguments
+// and further options of the tool, such as its output schema
+type toolHandler struct {
+	schema  *argsSchema
+	handler server.ToolHandlerFunc
+	options []mcp.ToolOption
+}
+
+// typed adapts a handler that takes its arguments as a struct of type T. The
+// input schema is derived from T, and calls with invalid arguments are
+// rejected with an error listing every problem before handler runs.
+func typed[T any](handler mcp.TypedToolHandlerFunc[T], options ...mcp.ToolOption) toolHandler {
+	schema := schemaOf(reflect.TypeOf((*T)(nil)).Elem())
+	return toolHandler{
+		schema:  schema,
+		options: options,
+		handler: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var args T
+			if err := schema.bind(request.GetArguments(), &args); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			return handler(ctx, request, args)
+		},
+	}
+}
+
+// withValidation rejects calls with invalid arguments before they are
+// confirmed, dry-run or sent to Proxmox
+func (s *Server) withValidation(name string, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	schema, ok := s.schemas[name]
+	if !ok {
+		return next
+	}
+
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if _, err := schema.validate(request.GetArguments()); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return next(ctx, request)
+	}
+}
+
+// noArgs is the argument struct of tools without arguments
+type noArgs struct{}
@@ -0,0 +1,231 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testArgs exercises every kind of argument and constraint
+type testArgs struct {
+	NodeName string         `json:"node_name" description:"Node" required:"true"`
+	VMID     int            `json:"vmid" minimum:"100" maximum:"999999999" required:"true"`
+	Memory   int            `json:"memory" default:"2048" minimum:"16"`
+	Ratio    float64        `json:"ratio" maximum:"1"`
+	Cache    string         `json:"cache" enum:"none,writeback"`
+	Force    bool           `json:"force"`
+	Disks    []string       `json:"disks"`
+	Options  map[string]any `json:"options"`
+	internal string
+}
+
+func TestArgsSchemaValidate(t *testing.T) {
+	schema := schemaOf(reflect.TypeOf(testArgs{}))
+
+	tests := []struct {
+		name     string
+		args     map[string]any
+		want     map[string]any
+		problems []string
+	}{
+		{
+			name: "defaults",
+			args: map[string]any{"node_name": "pve1", "vmid": float64(100)},
+			want: map[string]any{"node_name": "pve1", "vmid": float64(100), "memory": float64(2048)},
+		},
+		{
+			name: "values sent as strings",
+			args: map[string]any{"node_name": "pve1", "vmid": "100", "memory": " 4096 ", "ratio": "0.5", "force": "true", "disks": "scsi0, scsi1 unused0"},
+			want: map[string]any{
+				"node_name": "pve1", "vmid": float64(100), "memory": float64(4096), "ratio": 0.5, "force": true,
+				"disks": []any{"scsi0", "scsi1", "unused0"},
+			},
+		},
+		{
+			name: "native types",
+			args: map[string]any{"node_name": "pve1", "vmid": json.Number("101"), "force": float64(1), "cache": "none", "disks": []any{"scsi0"}, "options": map[string]any{"a": "b"}},
+			want: map[string]any{
+				"node_name": "pve1", "vmid": float64(101), "memory": float64(2048), "force": true, "cache": "none",
+				"disks": []any{"scsi0"}, "options": map[string]any{"a": "b"},
+			},
+		},
+		{
+			name: "numbers and booleans as strings for string arguments",
+			args: map[string]any{"node_name": float64(7), "vmid": 100, "cache": "writeback"},
+			want: map[string]any{"node_name": "7", "vmid": float64(100), "memory": float64(2048), "cache": "writeback"},
+		},
+		{
+			name:     "missing required",
+			args:     map[string]any{"node_name": ""},
+			problems: []string{"node_name is required", "vmid is required"},
+		},
+		{
+			name:     "null required",
+			args:     map[string]any{"node_name": nil, "vmid": float64(100)},
+			problems: []string{"node_name is required"},
+		},
+		{
+			name:     "bounds",
+			args:     map[string]any{"node_name": "pve1", "vmid": float64(99), "memory": float64(8), "ratio": 1.5},
+			problems: []string{"vmid must be at least 100", "memory must be at least 16", "ratio must be at most 1"},
+		},
+		{
+			name:     "wrong types",
+			args:     map[string]any{"node_name": []any{"pve1"}, "vmid": "one hundred", "force": "maybe", "disks": float64(1), "options": "a=b"},
+			problems: []string{"node_name must be a string", "vmid must be a number", "force must be a boolean", "disks must be a list", "options must be an object"},
+		},
+		{
+			name:     "fraction for an integer",
+			args:     map[string]any{"node_name": "pve1", "vmid": 100.5},
+			problems: []string{"vmid must be an integer"},
+		},
+		{
+			name:     "boolean out of 0 and 1",
+			args:     map[string]any{"node_name": "pve1", "vmid": float64(100), "force": float64(2)},
+			problems: []string{"force must be a boolean"},
+		},
+		{
+			name:     "enum",
+			args:     map[string]any{"node_name": "pve1", "vmid": float64(100), "cache": "unsafe"},
+			problems: []string{"cache must be one of none, writeback"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := schema.validate(tt.args)
+			if tt.problems != nil {
+				var argErr *ArgumentError
+				if !errors.As(err, &argErr) {
+					t.Fatalf("validate() error = %v, want an ArgumentError", err)
+				}
+				if !reflect.DeepEqual(argErr.Problems, tt.problems) {
+					t.Errorf("validate() problems = %q, want %q", argErr.Problems, tt.problems)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("validate() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArgsSchemaBind(t *testing.T) {
+	schema := schemaOf(reflect.TypeOf(testArgs{}))
+
+	var args testArgs
+	if err := schema.bind(map[string]any{"node_name": "pve1", "vmid": "100", "force": "1", "disks": "scsi0,scsi1"}, &args); err != nil {
+		t.Fatalf("bind() error = %v", err)
+	}
+	want := testArgs{NodeName: "pve1", VMID: 100, Memory: 2048, Force: true, Disks: []string{"scsi0", "scsi1"}}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("bind() = %+v, want %+v", args, want)
+	}
+
+	err := schema.bind(map[string]any{"vmid": float64(5)}, &args)
+	if err == nil || !strings.HasPrefix(err.Error(), "invalid arguments: node_name is required; vmid must be at least 100") {
+		t.Errorf("bind() error = %v", err)
+	}
+}
+
+func TestArgsSchemaProperties(t *testing.T) {
+	schema := schemaOf(reflect.TypeOf(testArgs{}))
+
+	properties := schema.Properties()
+	if _, ok := properties["internal"]; ok {
+		t.Error("unexported field became an argument")
+	}
+	want := map[string]map[string]any{
+		"node_name": {"type": "string", "description": "Node"},
+		"vmid":      {"type": "integer", "minimum": float64(100), "maximum": float64(999999999)},
+		"memory":    {"type": "integer", "default": float64(2048), "minimum": float64(16)},
+		"ratio":     {"type": "number", "maximum": float64(1)},
+		"cache":     {"type": "string", "enum": []string{"none", "writeback"}},
+		"force":     {"type": "boolean"},
+		"disks":     {"type": "array", "items": map[string]any{"type": "string"}},
+		"options":   {"type": "object"},
+	}
+	if len(properties) != len(want) {
+		t.Errorf("Properties() has %d arguments, want %d", len(properties), len(want))
+	}
+	for name, property := range want {
+		if got := properties[name]; !reflect.DeepEqual(got, map[string]any(property)) {
+			t.Errorf("Properties()[%s] = %v, want %v", name, got, property)
+		}
+	}
+	if got := schema.Required(); !reflect.DeepEqual(got, []string{"node_name", "vmid"}) {
+		t.Errorf("Required() = %v", got)
+	}
+}
+
+func TestCoerce(t *testing.T) {
+	tests := []struct {
+		kind    string
+		raw     any
+		want    any
+		wantErr string
+	}{
+		{"string", "pve1", "pve1", ""},
+		{"string", float64(100), "100", ""},
+		{"string", true, "true", ""},
+		{"string", json.Number("1.5"), "1.5", ""},
+		{"string", []any{"a"}, nil, "must be a string"},
+		{"integer", float64(100), float64(100), ""},
+		{"integer", 100, float64(100), ""},
+		{"integer", json.Number("100"), float64(100), ""},
+		{"integer", " 100 ", float64(100), ""},
+		{"integer", "1e3", float64(1000), ""},
+		{"integer", 1.5, nil, "must be an integer"},
+		{"integer", "1.5", nil, "must be an integer"},
+		{"integer", "lots", nil, "must be a number"},
+		{"integer", json.Number("x"), nil, "must be a number"},
+		{"integer", true, nil, "must be a number"},
+		{"number", "0.25", 0.25, ""},
+		{"number", 1.5, 1.5, ""},
+		{"boolean", true, true, ""},
+		{"boolean", float64(0), false, ""},
+		{"boolean", float64(1), true, ""},
+		{"boolean", "1", true, ""},
+		{"boolean", "false", false, ""},
+		{"boolean", float64(2), nil, "must be a boolean"},
+		{"boolean", "yes", nil, "must be a boolean"},
+		{"array", []any{"a", "b"}, []any{"a", "b"}, ""},
+		{"array", "a,b c", []any{"a", "b", "c"}, ""},
+		{"array", map[string]any{}, nil, "must be a list"},
+		{"object", map[string]any{"a": "b"}, map[string]any{"a": "b"}, ""},
+		{"object", "a=b", nil, "must be an object"},
+	}
+	for _, tt := range tests {
+		got, err := coerce(tt.kind, tt.raw)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("coerce(%s, %#v) error = %v, want %q", tt.kind, tt.raw, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("coerce(%s, %#v) error = %v", tt.kind, tt.raw, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("coerce(%s, %#v) = %#v, want %#v", tt.kind, tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestSchemaOfPanicsOnInvalidDefault(t *testing.T) {
+	type badDefault struct {
+		Memory int `json:"memory" default:"lots"`
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("schemaOf() accepted an invalid default")
+		}
+	}()
+	schemaOf(reflect.TypeOf(badDefault{}))
+}
@@ -0,0 +1,90 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/surrealwolf/proxmox-ve-mcp/internal/audit"
+)
+
+// upidPattern matches the task IDs Proxmox returns for asynchronous operations
+var upidPattern = regexp.MustCompile(`UPID:[^\s"\\]+`)
+
+// withAudit records every call of a tool in the audit log
+func (s *Server) withAudit(name string, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	if s.options.Audit == nil {
+		return next
+	}
+
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		result, err := next(ctx, request)
+
+		entry := audit.Entry{
+			Time:       start.UTC(),
+			Identity:   AuthIdentity(ctx),
+			Tool:       name,
+			Arguments:  audit.Redact(request.GetArguments()),
+			DryRun:     !isReadOnlyTool(name) && (s.options.DryRun || request.GetBool("dry_run", false)),
+			Outcome:    audit.OutcomeSuccess,
+			DurationMS: time.Since(start).Milliseconds(),
+		}
+		if session := server.ClientSessionFromContext(ctx); session != nil {
+			entry.SessionID = session.SessionID()
+			if withInfo, ok := session.(server.SessionWithClientInfo); ok {
+				info := withInfo.GetClientInfo()
+				entry.ClientName, entry.ClientVersion = info.Name, info.Version
+			}
+		}
+		switch {
+		case err != nil:
+			entry.Outcome, entry.Error = audit.OutcomeError, err.Error()
+		case result != nil && result.IsError:
+			entry.Outcome, entry.Error = audit.OutcomeError, resultText(result)
+		}
+		entry.UPIDs = resultUPIDs(result)
+
+		s.options.Audit.Record(entry)
+		return result, err
+	}
+}
+
+// resultText joins the text content of a result
+func resultText(result *mcp.CallToolResult) string {
+	text := ""
+	for _, content := range result.Content {
+		if textContent, ok := content.(mcp.TextContent); ok {
+			if text != "" {
+				text += "\n"
+			}
+			text += textContent.Text
+		}
+	}
+	return text
+}
+
+// resultUPIDs returns the task IDs found in a result
+func resultUPIDs(result *mcp.CallToolResult) []string {
+	if result == nil {
+		return nil
+	}
+	data, err := json.Marshal(result.StructuredContent)
+	if err != nil {
+		return nil
+	}
+	text := string(data) + "\n" + resultText(result)
+
+	var upids []string
+	seen := map[string]bool{}
+	for _, upid := range upidPattern.FindAllString(text, -1) {
+		if !seen[upid] {
+			seen[upid] = true
+			upids = append(upids, upid)
+		}
+	}
+	return upids
+}
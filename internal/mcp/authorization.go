@@ -0,0 +1,71 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// AccessRule grants the clients matching Identity the tools Tools permits
+type AccessRule struct {
+	// Identity is a glob matched against the authenticated identity of the
+	// HTTP client, e.g. "jwt:alice", "cert:ops-*" or "token"; "*" matches
+	// every client
+	Identity string
+	Tools    ToolFilter
+}
+
+// matches reports whether the rule applies to identity. Clients that present
+// both a certificate and a token match on either part.
+func (r AccessRule) matches(identity string) bool {
+	if matched, _ := path.Match(r.Identity, identity); matched {
+		return true
+	}
+	for _, part := range strings.Split(identity, ",") {
+		if matched, _ := path.Match(r.Identity, part); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// authorize returns the rule that applies to identity, the first matching one
+func (s *Server) authorize(identity string) (AccessRule, bool) {
+	for _, rule := range s.options.Authorization {
+		if rule.matches(identity) {
+			return rule, true
+		}
+	}
+	return AccessRule{}, false
+}
+
+// mayCall reports whether the client of ctx is granted tool. Without rules
+// every client is.
+func (s *Server) mayCall(ctx context.Context, tool string) bool {
+	if len(s.options.Authorization) == 0 {
+		return true
+	}
+	rule, ok := s.authorize(AuthIdentity(ctx))
+	return ok && rule.Tools.permits(tool)
+}
+
+// withAuthorization rejects calls of tools the client is not granted. When
+// rules are configured, clients that match none of them are denied.
+func (s *Server) withAuthorization(name string, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	if len(s.options.Authorization) == 0 {
+		return next
+	}
+
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		identity := AuthIdentity(ctx)
+		if rule, ok := s.authorize(identity); !ok || !rule.Tools.permits(name) {
+			s.logger.WithField("tool", name).Warnf("Denied tool call for client %q", identity)
+			return mcp.NewToolResultError(fmt.Sprintf("not authorized to call %s", name)), nil
+		}
+		return next(ctx, request)
+	}
+}
@@ -0,0 +1,115 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/surrealwolf/proxmox-ve-mcp/internal/proxmox"
+)
+
+// getVMCloudInit handles the get_vm_cloudinit tool
+func (s *Server) getVMCloudInit(ctx context.Context, request mcp.CallToolRequest, args vmCloudInitArgs) (*mcp.CallToolResult, error) {
+	client := s.client(ctx)
+	config, err := client.GetVMCloudInit(ctx, args.NodeName, args.VMID)
+	if err != nil {
+		return toolError("Failed to get cloud-init settings", err), nil
+	}
+	pending, err := client.GetVMCloudInitPending(ctx, args.NodeName, args.VMID)
+	if err != nil {
+		return toolError("Failed to get cloud-init settings", err), nil
+	}
+
+	dump := map[string]string{}
+	if args.Section != "" {
+		dump[args.Section], err = client.DumpVMCloudInit(ctx, args.NodeName, args.VMID, args.Section)
+	} else {
+		dump, err = client.DumpVMCloudInitAll(ctx, args.NodeName, args.VMID)
+	}
+	if err != nil {
+		return toolError("Failed to get cloud-init data", err), nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]interface{}{
+		"vmid":      args.VMID,
+		"node":      args.NodeName,
+		"config":    config,
+		"pending":   pending,
+		"generated": dump,
+	})
+}
+
+// setVMCloudInit handles the set_vm_cloudinit tool
+func (s *Server) setVMCloudInit(ctx context.Context, request mcp.CallToolRequest, args setVMCloudInitArgs) (*mcp.CallToolResult, error) {
+	config := proxmox.CloudInitConfig{
+		User:         args.User,
+		Password:     args.Password,
+		SSHKeys:      args.SSHKeys,
+		Nameserver:   args.Nameserver,
+		SearchDomain: args.SearchDomain,
+		Custom:       args.Custom,
+		Type:         args.Type,
+		Delete:       args.Delete,
+	}
+	ipConfig, err := parseIPConfig(args.IPConfig)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	config.IPConfig = ipConfig
+
+	client := s.client(ctx)
+	result, err := client.SetVMCloudInit(ctx, args.NodeName, args.VMID, config)
+	if err != nil {
+		return toolError("Failed to set cloud-init settings", err), nil
+	}
+	if args.Regenerate {
+		if _, err := client.RegenerateVMCloudInit(ctx, args.NodeName, args.VMID); err != nil {
+			return toolError("Cloud-init settings saved but regenerating the drive failed", err), nil
+		}
+	}
+
+	return mcp.NewToolResultJSON(map[string]interface{}{
+		"action":      "set_cloudinit",
+		"vmid":        args.VMID,
+		"node":        args.NodeName,
+		"regenerated": args.Regenerate,
+		"result":      result,
+	})
+}
+
+// regenerateVMCloudInit handles the regenerate_vm_cloudinit tool
+func (s *Server) regenerateVMCloudInit(ctx context.Context, request mcp.CallToolRequest, args vmArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).RegenerateVMCloudInit(ctx, args.NodeName, args.VMID)
+	if err != nil {
+		return toolError("Failed to regenerate cloud-init drive", err), nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]interface{}{
+		"action": "regenerate_cloudinit",
+		"vmid":   args.VMID,
+		"node":   args.NodeName,
+		"result": result,
+	})
+}
+
+// parseIPConfig converts the ip_config argument, settings keyed by interface
+// number, to the ipconfigN settings of a CloudInitConfig
+func parseIPConfig(arg map[string]interface{}) (map[int]string, error) {
+	if len(arg) == 0 {
+		return nil, nil
+	}
+	ipConfig := make(map[int]string, len(arg))
+	for key, value := range arg {
+		n, err := strconv.Atoi(key)
+		if err != nil || n < 0 || n > 31 {
+			return nil, fmt.Errorf("ip_config: invalid interface number %q, expected 0 to 31", key)
+		}
+		text, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("ip_config: the settings of interface %d must be a string such as \"ip=dhcp\"", n)
+		}
+		ipConfig[n] = text
+	}
+	return ipConfig, nil
+}
@@ -0,0 +1,49 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/surrealwolf/proxmox-ve-mcp/internal/proxmox"
+)
+
+// clusterClientKey is the context key of the Proxmox client selected for a tool call
+type clusterClientKey struct{}
+
+// withClusterArg adds the optional cluster argument to a tool's properties
+func withClusterArg(properties map[string]any) map[string]any {
+	properties["cluster"] = map[string]any{"type": "string", "description": "Cluster to operate on (optional, default cluster if omitted; see list_clusters)"}
+	return properties
+}
+
+// withCluster resolves the cluster argument of a tool call and makes the
+// matching client available to the handler through s.client
+func (s *Server) withCluster(name string, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := s.clusters.Get(request.GetString("cluster", ""))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return next(context.WithValue(ctx, clusterClientKey{}, client), request)
+	}
+}
+
+// client returns the Proxmox client selected for the current tool call, or
+// the default cluster's client
+func (s *Server) client(ctx context.Context) *proxmox.Client {
+	if client, ok := ctx.Value(clusterClientKey{}).(*proxmox.Client); ok {
+		return client
+	}
+	return s.clusters.Default()
+}
+
+// listClusters handles the list_clusters tool
+func (s *Server) listClusters(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	clusters := s.clusters.Clusters()
+	return mcp.NewToolResultJSON(map[string]interface{}{
+		"message":  "Clusters retrieved successfully",
+		"count":    len(clusters),
+		"clusters": clusters,
+	})
+}
@@ -0,0 +1,442 @@
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/surrealwolf/proxmox-ve-mcp/internal/proxmox"
+)
+
+// defaultConfirmationTTL is how long a confirmation token stays valid when
+// no TTL is configured
+const defaultConfirmationTTL = 2 * time.Minute
+
+// impactFunc describes what a destructive tool call would do. It returns a
+// one-line summary and the details looked up from the cluster.
+type impactFunc func(ctx context.Context, s *Server, request mcp.CallToolRequest) (string, map[string]interface{})
+
+// destructiveTools lists the tools that require confirmation and how to
+// describe their impact
+var destructiveTools = map[string]impactFunc{
+	"delete_vm":                  guestImpact("qemu", "permanently delete %s"),
+	"delete_vm_snapshot":         guestImpact("qemu", "delete a snapshot of %s"),
+	"detach_vm_disk":             guestImpact("qemu", "detach disks from %s"),
+	"move_vm_disk":               guestImpact("qemu", "move a disk of %s"),
+	"restore_vm_snapshot":        guestImpact("qemu", "roll %s back to a snapshot, discarding all later changes"),
+	"delete_container":           guestImpact("lxc", "permanently delete %s"),
+	"delete_container_snapshot":  guestImpact("lxc", "delete a snapshot of %s"),
+	"restore_container_snapshot": guestImpact("lxc", "roll %s back to a snapshot, discarding all later changes"),
+	"restore_vm_backup":          backupImpact("qemu"),
+	"restore_container_backup":   backupImpact("lxc"),
+	"reboot_node":                nodeImpact("reboot"),
+	"shutdown_node":              nodeImpact("shut down"),
+	"apply_node_updates":         nodeImpact("install updates on"),
+	"remove_node_from_cluster":   nodeImpact("remove from the cluster"),
+	"delete_backup":              argsImpact("permanently delete backup", "backup_id"),
+	"delete_storage":             argsImpact("remove storage", "storage"),
+	"delete_pool":                argsImpact("remove pool", "poolid"),
+	"delete_user":                argsImpact("delete user", "userid"),
+	"delete_group":               argsImpact("delete group", "groupid"),
+	"delete_role":                argsImpact("delete role", "roleid"),
+	"delete_api_token":           argsImpact("delete API token", "tokenid"),
+	"delete_firewall_rule":       argsImpact("delete firewall rule", "position"),
+}
+
+// confirmationNeeded lists the destructive tools that only destroy data with
+// some arguments. Calls without them run unconfirmed.
+var confirmationNeeded = map[string]func(request mcp.CallToolRequest) bool{
+	"move_vm_disk": func(request mcp.CallToolRequest) bool { return request.GetBool("delete_source", false) },
+}
+
+// isDestructiveTool reports whether a tool requires confirmation
+func isDestructiveTool(name string) bool {
+	_, ok := destructiveTools[name]
+	return ok
+}
+
+// withConfirmToken adds the confirm_token argument to a destructive tool
+func withConfirmToken(properties map[string]any) map[string]any {
+	properties["confirm_token"] = map[string]any{"type": "string", "description": "Token returned by a previous call of this tool with the same arguments, confirming the operation"}
+	return properties
+}
+
+// pendingConfirmation is an issued, not yet redeemed confirmation token
+type pendingConfirmation struct {
+	caller  string
+	tool    string
+	args    string
+	expires time.Time
+}
+
+// confirmationStore holds the pending confirmation tokens
+type confirmationStore struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	pending map[string]pendingConfirmation
+}
+
+func newConfirmationStore(ttl time.Duration) *confirmationStore {
+	if ttl <= 0 {
+		ttl = defaultConfirmationTTL
+	}
+	return &confirmationStore{ttl: ttl, pending: make(map[string]pendingConfirmation)}
+}
+
+// issue creates a token confirming the call of tool with the given arguments
+// by caller, as identified by callerKey
+func (cs *confirmationStore) issue(caller, tool, args string) (string, time.Time, error) {
+	var raw [16]byte
+	if _, err := rand.Read(raw[:]); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate confirmation token: %w", err)
+	}
+	token := hex.EncodeToString(raw[:])
+	expires := time.Now().Add(cs.ttl)
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	for key, pending := range cs.pending {
+		if time.Now().After(pending.expires) {
+			delete(cs.pending, key)
+		}
+	}
+	cs.pending[token] = pendingConfirmation{caller: caller, tool: tool, args: args, expires: expires}
+	return token, expires, nil
+}
+
+// redeem consumes a token, failing unless it was issued to the same caller for
+// the same tool and arguments and has not expired
+func (cs *confirmationStore) redeem(caller, token, tool, args string) error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	pending, ok := cs.pending[token]
+	if !ok {
+		return fmt.Errorf("unknown or already used confirmation token")
+	}
+	delete(cs.pending, token)
+
+	switch {
+	case pending.caller != caller:
+		return fmt.Errorf("confirmation token was issued to a different client")
+	case time.Now().After(pending.expires):
+		return fmt.Errorf("confirmation token expired, call %s again without confirm_token to get a new one", tool)
+	case pending.tool != tool || pending.args != args:
+		return fmt.Errorf("confirmation token was issued for a different call; the tool and arguments must not change")
+	}
+	return nil
+}
+
+// argumentsDigest identifies the arguments of a call, ignoring confirm_token
+func argumentsDigest(request mcp.CallToolRequest) string {
+	args := map[string]any{}
+	for key, value := range request.GetArguments() {
+		if key != "confirm_token" {
+			args[key] = value
+		}
+	}
+	// encoding/json sorts map keys, which makes the encoding canonical
+	data, _ := json.Marshal(args)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// withConfirmation makes a destructive tool ask for confirmation before it
+// runs: through MCP elicitation when the client supports it, and otherwise by
+// returning a confirmation token that must be passed back within the TTL
+func (s *Server) withConfirmation(name string, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	describe, ok := destructiveTools[name]
+	if !ok || !s.options.ConfirmDestructive {
+		return next
+	}
+
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if proxmox.IsDryRun(ctx) {
+			// Nothing is changed, so there is nothing to confirm
+			return next(ctx, request)
+		}
+		if needed, ok := confirmationNeeded[name]; ok && !needed(request) {
+			return next(ctx, request)
+		}
+		caller, args := callerKey(ctx), argumentsDigest(request)
+
+		if token := request.GetString("confirm_token", ""); token != "" {
+			if err := s.confirmations.redeem(caller, token, name, args); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			s.logger.Infof("Confirmed %s with token", name)
+			return next(ctx, request)
+		}
+
+		summary, impact := describe(ctx, s, request)
+
+		if clientSupportsElicitation(ctx) {
+			confirmed, err := s.elicitConfirmation(ctx, summary)
+			if err == nil {
+				if !confirmed {
+					return mcp.NewToolResultError(fmt.Sprintf("%s was not confirmed by the user", name)), nil
+				}
+				s.logger.Infof("Confirmed %s through elicitation", name)
+				return next(ctx, request)
+			}
+			s.logger.WithError(err).Debug("Elicitation failed, falling back to a confirmation token")
+		}
+
+		token, expires, err := s.confirmations.issue(caller, name, args)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		ttl := time.Until(expires).Round(time.Second)
+		return mcp.NewToolResultStructured(map[string]interface{}{
+			"confirmation_required": true,
+			"tool":                  name,
+			"summary":               summary,
+			"impact":                impact,
+			"confirm_token":         token,
+			"expires_at":            expires.UTC().Format(time.RFC3339),
+			"expires_in_seconds":    int(ttl.Seconds()),
+		}, fmt.Sprintf("Confirmation required: %s\nTo proceed, call %s again with the same arguments and confirm_token=%q within %s.",
+			summary, name, token, ttl)), nil
+	}
+}
+
+// clientSupportsElicitation reports whether the client of the current session
+// declared the elicitation capability
+func clientSupportsElicitation(ctx context.Context) bool {
+	session, ok := server.ClientSessionFromContext(ctx).(server.SessionWithClientInfo)
+	return ok && session.GetClientCapabilities().Elicitation != nil
+}
+
+// elicitConfirmation asks the user to confirm an operation
+func (s *Server) elicitConfirmation(ctx context.Context, summary string) (bool, error) {
+	result, err := s.server.RequestElicitation(ctx, mcp.ElicitationRequest{
+		Params: mcp.ElicitationParams{
+			Message: summary + "\n\nDo you want to proceed?",
+			RequestedSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"confirm": map[string]any{"type": "boolean", "title": "Proceed", "description": "Confirm the operation"},
+				},
+				"required": []string{"confirm"},
+			},
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	if result.Action != mcp.ElicitationResponseActionAccept {
+		return false, nil
+	}
+	content, _ := result.Content.(map[string]any)
+	confirmed, _ := content["confirm"].(bool)
+	return confirmed, nil
+}
+
+// guestImpact describes an operation on a VM or container: its name, whether
+// it is running and which backups exist. verb is a format with one %s for
+// the guest.
+func guestImpact(guestType, verb string) impactFunc {
+	return func(ctx context.Context, s *Server, request mcp.CallToolRequest) (string, map[string]interface{}) {
+		nodeName := request.GetString("node_name", "")
+		idArg, kind := "vmid", "VM"
+		if guestType == "lxc" {
+			idArg, kind = "container_id", "container"
+		}
+		vmID := request.GetInt(idArg, 0)
+		impact := map[string]interface{}{"node": nodeName, "vmid": vmID, "type": guestType}
+		if snapName := request.GetString("snap_name", ""); snapName != "" {
+			impact["snapshot"] = snapName
+		}
+		if disks := request.GetStringSlice("disks", nil); len(disks) > 0 {
+			impact["disks"] = disks
+			impact["delete_volumes"] = request.GetBool("delete_volumes", false)
+		}
+		if disk := request.GetString("disk", ""); disk != "" {
+			// move_vm_disk, which only asks for confirmation with delete_source
+			impact["disks"] = []string{disk}
+			impact["delete_volumes"] = request.GetBool("delete_source", false)
+		}
+
+		var name, status string
+		if guestType == "lxc" {
+			if ct, err := s.client(ctx).GetContainer(ctx, nodeName, vmID); err == nil {
+				name, status = ct.Name, ct.Status
+			} else {
+				impact["lookup_error"] = err.Error()
+			}
+		} else {
+			if vm, err := s.client(ctx).GetVM(ctx, nodeName, vmID); err == nil {
+				name, status = vm.Name, vm.Status
+			} else {
+				impact["lookup_error"] = err.Error()
+			}
+		}
+		impact["name"] = name
+		impact["status"] = status
+		impact["running"] = status == "running"
+		if disks, ok := impact["disks"].([]string); ok && impact["delete_volumes"] == true {
+			if volumes, err := diskVolumes(ctx, s.client(ctx), nodeName, vmID, disks); err == nil {
+				impact["volumes"] = volumes
+			} else {
+				impact["volumes_error"] = err.Error()
+			}
+		}
+
+		guest := fmt.Sprintf("%s %d", kind, vmID)
+		if name != "" {
+			guest += fmt.Sprintf(" (%s)", name)
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "This will "+verb+" on node %s.", guest, nodeName)
+		if snapName, ok := impact["snapshot"]; ok {
+			fmt.Fprintf(&b, " Snapshot: %s.", snapName)
+		}
+		if disks, ok := impact["disks"].([]string); ok {
+			fmt.Fprintf(&b, " Disks: %s.", strings.Join(disks, ", "))
+			if volumes, ok := impact["volumes"].([]string); ok {
+				fmt.Fprintf(&b, " Volumes that will be destroyed: %s.", strings.Join(volumes, ", "))
+			} else if impact["delete_volumes"] == true {
+				b.WriteString(" Their volumes will be destroyed.")
+			}
+		}
+		if status != "" {
+			fmt.Fprintf(&b, " It is %s.", status)
+		} else {
+			b.WriteString(" Its state could not be checked.")
+		}
+
+		if backups, err := s.client(ctx).FindGuestBackups(ctx, nodeName, vmID); err == nil {
+			volumes := make([]string, 0, len(backups))
+			for _, backup := range backups {
+				volumes = append(volumes, backup.VolID)
+			}
+			impact["backups"] = volumes
+			if len(volumes) == 0 {
+				b.WriteString(" No backups exist.")
+			} else {
+				fmt.Fprintf(&b, " %d backup(s) exist.", len(volumes))
+			}
+		} else {
+			impact["backups_error"] = err.Error()
+			b.WriteString(" Backups could not be checked.")
+		}
+		return b.String(), impact
+	}
+}
+
+// diskVolumes returns the volumes behind the given disk slots of a VM
+func diskVolumes(ctx context.Context, client *proxmox.Client, nodeName string, vmID int, slots []string) ([]string, error) {
+	config, err := client.GetParsedVMConfig(ctx, nodeName, vmID)
+	if err != nil {
+		return nil, err
+	}
+	volumes := make([]string, 0, len(slots))
+	for _, slot := range slots {
+		disk := config.Disk(slot)
+		if disk == nil {
+			return nil, fmt.Errorf("VM %d has no disk %s", vmID, slot)
+		}
+		volumes = append(volumes, disk.Volume)
+	}
+	return volumes, nil
+}
+
+// backupArchiveVMID extracts the guest ID from a vzdump archive name such as
+// local:backup/vzdump-qemu-100-2024_01_01-00_00_00.vma.zst
+var backupArchiveVMID = regexp.MustCompile(`vzdump-(?:qemu|lxc|openvz)-(\d+)-`)
+
+// backupImpact describes a restore, including the guest it would overwrite
+func backupImpact(guestType string) impactFunc {
+	return func(ctx context.Context, s *Server, request mcp.CallToolRequest) (string, map[string]interface{}) {
+		nodeName := request.GetString("node_name", "")
+		backupID := request.GetString("backup_id", "")
+		impact := map[string]interface{}{"node": nodeName, "backup": backupID, "storage": request.GetString("storage", "")}
+		summary := fmt.Sprintf("This will restore backup %s on node %s.", backupID, nodeName)
+
+		match := backupArchiveVMID.FindStringSubmatch(backupID)
+		if match == nil {
+			return summary, impact
+		}
+		vmID, _ := strconv.Atoi(match[1])
+		impact["vmid"] = vmID
+
+		var name, status string
+		var err error
+		if guestType == "lxc" {
+			var ct *proxmox.Container
+			ct, err = s.client(ctx).GetContainer(ctx, nodeName, vmID)
+			if err == nil {
+				name, status = ct.Name, ct.Status
+			}
+		} else {
+			var vm *proxmox.VM
+			vm, err = s.client(ctx).GetVM(ctx, nodeName, vmID)
+			if err == nil {
+				name, status = vm.Name, vm.Status
+			}
+		}
+		if err != nil {
+			summary += fmt.Sprintf(" Guest %d does not exist on the node or could not be checked.", vmID)
+			return summary, impact
+		}
+		impact["existing_guest"] = map[string]interface{}{"name": name, "status": status, "running": status == "running"}
+		summary += fmt.Sprintf(" Existing guest %d (%s, %s) would be overwritten.", vmID, name, status)
+		return summary, impact
+	}
+}
+
+// nodeImpact describes a node operation and the guests running on the node
+func nodeImpact(verb string) impactFunc {
+	return func(ctx context.Context, s *Server, request mcp.CallToolRequest) (string, map[string]interface{}) {
+		nodeName := request.GetString("node_name", "")
+		impact := map[string]interface{}{"node": nodeName}
+		summary := fmt.Sprintf("This will %s node %s.", verb, nodeName)
+
+		var running []string
+		if vms, err := s.client(ctx).GetVMs(ctx, nodeName); err == nil {
+			for _, vm := range vms {
+				if vm.Status == "running" {
+					running = append(running, fmt.Sprintf("VM %d (%s)", vm.VMID, vm.Name))
+				}
+			}
+		} else {
+			impact["lookup_error"] = err.Error()
+		}
+		if containers, err := s.client(ctx).GetContainers(ctx, nodeName); err == nil {
+			for _, ct := range containers {
+				if ct.Status == "running" {
+					running = append(running, fmt.Sprintf("container %d (%s)", ct.VMID, ct.Name))
+				}
+			}
+		}
+		impact["running_guests"] = running
+		if len(running) > 0 {
+			summary += fmt.Sprintf(" %d guest(s) are running on it: %s.", len(running), strings.Join(running, ", "))
+		}
+		return summary, impact
+	}
+}
+
+// argsImpact describes an operation by the object named in one argument
+func argsImpact(verb, arg string) impactFunc {
+	return func(_ context.Context, _ *Server, request mcp.CallToolRequest) (string, map[string]interface{}) {
+		value := request.GetString(arg, "")
+		impact := map[string]interface{}{}
+		for key, v := range request.GetArguments() {
+			if key != "confirm_token" {
+				impact[key] = v
+			}
+		}
+		return fmt.Sprintf("This will %s %s.", verb, value), impact
+	}
+}
@@ -0,0 +1,146 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/surrealwolf/proxmox-ve-mcp/internal/proxmox"
+)
+
+// listVMDisks handles the list_vm_disks tool
+func (s *Server) listVMDisks(ctx context.Context, request mcp.CallToolRequest, args vmArgs) (*mcp.CallToolResult, error) {
+	disks, err := s.client(ctx).GetVMDisks(ctx, args.NodeName, args.VMID)
+	if err != nil {
+		return toolError("Failed to list VM disks", err), nil
+	}
+
+	return mcp.NewToolResultJSON(vmDisksResult{
+		Disks: disks,
+		Count: len(disks),
+		VMID:  args.VMID,
+		Node:  args.NodeName,
+	})
+}
+
+// resizeVMDisk handles the resize_vm_disk tool
+func (s *Server) resizeVMDisk(ctx context.Context, request mcp.CallToolRequest, args resizeVMDiskArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).ResizeVMDisk(ctx, args.NodeName, args.VMID, args.Disk, args.Size)
+	if err != nil {
+		return toolError("Failed to resize disk", err), nil
+	}
+	result, err = s.awaitTask(ctx, args.taskWait, result)
+	if err != nil {
+		return toolError("Failed to resize disk", err), nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]interface{}{
+		"action": "resize_disk",
+		"vmid":   args.VMID,
+		"node":   args.NodeName,
+		"disk":   args.Disk,
+		"size":   args.Size,
+		"result": result,
+	})
+}
+
+// setVMDiskOptions handles the set_vm_disk_options tool. Only the options
+// given in the call change; the disk keeps all others.
+func (s *Server) setVMDiskOptions(ctx context.Context, request mcp.CallToolRequest, args setVMDiskOptionsArgs) (*mcp.CallToolResult, error) {
+	given := request.GetArguments()
+	setBool := func(name string, value bool, flag **bool) {
+		if _, ok := given[name]; ok {
+			*flag = &value
+		}
+	}
+
+	var disk *proxmox.Disk
+	_, result, err := s.client(ctx).EditVMConfig(ctx, args.NodeName, args.VMID, func(config *proxmox.VMConfig) error {
+		if disk = config.Disk(args.Disk); disk == nil {
+			return fmt.Errorf("VM %d has no disk %s", args.VMID, args.Disk)
+		}
+		if args.Cache != "" {
+			disk.Cache = args.Cache
+		}
+		if args.Discard != "" {
+			disk.Discard = args.Discard
+		}
+		setBool("iothread", args.IOThread, &disk.IOThread)
+		setBool("ssd", args.SSD, &disk.SSD)
+		setBool("backup", args.Backup, &disk.Backup)
+		return nil
+	})
+	if err != nil {
+		return toolError("Failed to set disk options", err), nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]interface{}{
+		"action": "set_disk_options",
+		"vmid":   args.VMID,
+		"node":   args.NodeName,
+		"disk":   disk,
+		"value":  disk.String(),
+		"result": result,
+	})
+}
+
+// moveVMDisk handles the move_vm_disk tool
+func (s *Server) moveVMDisk(ctx context.Context, request mcp.CallToolRequest, args moveVMDiskArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).MoveVMDisk(ctx, args.NodeName, args.VMID, args.Disk, args.Storage, args.Format, args.DeleteSource)
+	if err != nil {
+		return toolError("Failed to move disk", err), nil
+	}
+	result, err = s.awaitTask(ctx, args.taskWait, result)
+	if err != nil {
+		return toolError("Failed to move disk", err), nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]interface{}{
+		"action":         "move_disk",
+		"vmid":           args.VMID,
+		"node":           args.NodeName,
+		"disk":           args.Disk,
+		"target_storage": args.Storage,
+		"delete_source":  args.DeleteSource,
+		"result":         result,
+	})
+}
+
+// detachVMDisk handles the detach_vm_disk tool
+func (s *Server) detachVMDisk(ctx context.Context, request mcp.CallToolRequest, args detachVMDiskArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).UnlinkVMDisks(ctx, args.NodeName, args.VMID, args.Disks, args.DeleteVolumes)
+	if err != nil {
+		return toolError("Failed to detach disks", err), nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]interface{}{
+		"action":          "detach_disks",
+		"vmid":            args.VMID,
+		"node":            args.NodeName,
+		"disks":           args.Disks,
+		"volumes_deleted": args.DeleteVolumes,
+		"result":          result,
+	})
+}
+
+// importVMDisk handles the import_vm_disk tool
+func (s *Server) importVMDisk(ctx context.Context, request mcp.CallToolRequest, args importVMDiskArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).ImportVMDisk(ctx, args.NodeName, args.VMID, args.Disk, args.Source, args.Storage, args.Format)
+	if err != nil {
+		return toolError("Failed to import disk", err), nil
+	}
+	result, err = s.awaitTask(ctx, args.taskWait, result)
+	if err != nil {
+		return toolError("Failed to import disk", err), nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]interface{}{
+		"action":  "import_disk",
+		"vmid":    args.VMID,
+		"node":    args.NodeName,
+		"disk":    args.Disk,
+		"source":  args.Source,
+		"storage": args.Storage,
+		"result":  result,
+	})
+}
@@ -0,0 +1,73 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/surrealwolf/proxmox-ve-mcp/internal/proxmox"
+)
+
+// withDryRunArg adds the dry_run argument to a mutating tool
+func withDryRunArg(properties map[string]any) map[string]any {
+	properties["dry_run"] = map[string]any{"type": "boolean", "description": "Validate the call and return the Proxmox requests it would send, without changing anything (optional)"}
+	return properties
+}
+
+// withDryRun runs a mutating tool in dry-run mode when the server runs in
+// dry-run mode or the call sets dry_run. The handler validates its input and
+// performs its reads as usual, while its writes are recorded and returned
+// together with checks of their targets.
+func (s *Server) withDryRun(name string, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	if isReadOnlyTool(name) {
+		return next
+	}
+
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if !s.options.DryRun && !request.GetBool("dry_run", false) {
+			return next(ctx, request)
+		}
+
+		dryCtx, dryRun := proxmox.WithDryRun(ctx)
+		result, err := next(dryCtx, request)
+		if err != nil {
+			return result, err
+		}
+
+		requests := dryRun.Requests()
+		if len(requests) == 0 && result != nil && result.IsError {
+			// The input was rejected before any write
+			return result, nil
+		}
+
+		checks := s.client(ctx).CheckPlan(ctx, requests)
+		valid := true
+		var text strings.Builder
+		fmt.Fprintf(&text, "Dry run of %s, nothing was changed.\n", name)
+		for _, planned := range requests {
+			fmt.Fprintf(&text, "Would send %s %s\n", planned.Method, planned.Endpoint)
+		}
+		for _, check := range checks {
+			status := "ok"
+			if !check.OK {
+				status = "FAILED"
+				valid = false
+			}
+			fmt.Fprintf(&text, "Check %s %s: %s", check.Check, check.Target, status)
+			if check.Detail != "" {
+				fmt.Fprintf(&text, " (%s)", check.Detail)
+			}
+			text.WriteString("\n")
+		}
+
+		return mcp.NewToolResultStructured(map[string]interface{}{
+			"dry_run":  true,
+			"tool":     name,
+			"valid":    valid,
+			"requests": requests,
+			"checks":   checks,
+		}, text.String()), nil
+	}
+}
@@ -0,0 +1,100 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/surrealwolf/proxmox-ve-mcp/internal/proxmox"
+)
+
+// Tools acting on an existing VM or container tag the arguments identifying
+// it with guest:"...". node_name is optional for them: the guest is looked up
+// in cluster/resources by its ID, or by name or tag when no ID is given, and
+// the call proceeds with the node it currently runs on. Calls keep working
+// after a guest was migrated, and clients need not know the node at all.
+
+// guestRef names the arguments of a tool that identify a guest
+type guestRef struct {
+	guestType string // qemu or lxc
+	node      string
+	id        string
+	name      string // optional
+	tag       string // optional
+}
+
+// guestRef returns the guest arguments of the schema, or nil when the tool
+// does not act on an existing guest
+func (a *argsSchema) guestRef() *guestRef {
+	ref := &guestRef{}
+	for _, field := range a.fields {
+		switch field.guest {
+		case "node":
+			ref.node = field.name
+		case "qemu", "lxc":
+			ref.id = field.name
+			ref.guestType = field.guest
+		case "name":
+			ref.name = field.name
+		case "tag":
+			ref.tag = field.name
+		}
+	}
+	if ref.node == "" || ref.id == "" {
+		return nil
+	}
+	return ref
+}
+
+// selector returns the guest selected by the arguments of a call
+func (ref *guestRef) selector(arguments map[string]any) proxmox.GuestSelector {
+	sel := proxmox.GuestSelector{Type: ref.guestType}
+	sel.Node, _ = arguments[ref.node].(string)
+	if id, err := coerce("integer", arguments[ref.id]); err == nil {
+		sel.VMID = int(id.(float64))
+	}
+	if ref.name != "" {
+		sel.Name, _ = arguments[ref.name].(string)
+	}
+	if ref.tag != "" {
+		sel.Tag, _ = arguments[ref.tag].(string)
+	}
+	return sel
+}
+
+// withGuest completes the guest arguments of a call that omits the node or
+// identifies the guest by name or tag. A node given with a name or tag
+// narrows the lookup to that node.
+func (s *Server) withGuest(name string, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	schema, ok := s.schemas[name]
+	if !ok {
+		return next
+	}
+	ref := schema.guestRef()
+	if ref == nil {
+		return next
+	}
+
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		sel := ref.selector(arguments)
+		if sel.Node != "" && sel.VMID > 0 {
+			return next(ctx, request)
+		}
+
+		guest, err := s.client(ctx).ResolveGuest(ctx, sel)
+		if err != nil {
+			return toolError("Failed to identify the "+proxmox.GuestSelector{Type: ref.guestType}.String(), err), nil
+		}
+
+		resolved := make(map[string]any, len(arguments)+2)
+		for key, value := range arguments {
+			resolved[key] = value
+		}
+		resolved[ref.node] = guest.Node
+		resolved[ref.id] = guest.VMID
+		request.Params.Arguments = resolved
+		s.logger.WithField("tool", name).Debugf("Resolved %s to %s", sel, guest)
+		return next(ctx, request)
+	}
+}
@@ -0,0 +1,482 @@
+package mcp
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// jwksRefreshInterval limits how often an unknown key ID triggers a JWKS refetch
+	jwksRefreshInterval = time.Minute
+	// jwtClockSkew is the leeway applied to exp and nbf claims
+	jwtClockSkew = 30 * time.Second
+)
+
+// HTTPOptions configures security of the HTTP transport
+type HTTPOptions struct {
+	// BearerTokens are static tokens accepted in the Authorization header
+	BearerTokens []string
+	// OAuthIssuer enables JWT validation for tokens issued by this issuer
+	OAuthIssuer string
+	// OAuthAudience is the required aud claim; tokens without it are rejected
+	OAuthAudience string
+	// OAuthJWKSURL overrides JWKS discovery through the issuer's OpenID configuration
+	OAuthJWKSURL string
+	// TLSCertFile and TLSKeyFile enable HTTPS
+	TLSCertFile string
+	TLSKeyFile  string
+	// TLSClientCAFile enables mutual TLS; /mcp then requires a client certificate signed by this CA
+	TLSClientCAFile string
+}
+
+// authEnabled reports whether any form of client authentication is configured
+func (o HTTPOptions) authEnabled() bool {
+	return len(o.BearerTokens) > 0 || o.OAuthIssuer != "" || o.TLSClientCAFile != ""
+}
+
+// tlsConfig builds the server TLS configuration, or nil when TLS is disabled
+func (o HTTPOptions) tlsConfig() (*tls.Config, error) {
+	if o.TLSCertFile == "" && o.TLSKeyFile == "" {
+		if o.TLSClientCAFile != "" {
+			return nil, fmt.Errorf("mutual TLS requires a TLS certificate and key")
+		}
+		return nil, nil
+	}
+	if o.TLSCertFile == "" || o.TLSKeyFile == "" {
+		return nil, fmt.Errorf("both TLS certificate and key files must be provided")
+	}
+
+	cert, err := tls.LoadX509KeyPair(o.TLSCertFile, o.TLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if o.TLSClientCAFile != "" {
+		caPEM, err := os.ReadFile(o.TLSClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in client CA file %s", o.TLSClientCAFile)
+		}
+		config.ClientCAs = pool
+		// Certificates are verified when presented; requireAuth enforces them on /mcp
+		// so that /health stays reachable without one.
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return config, nil
+}
+
+// authIdentityKey is the context key of the authenticated client identity
+type authIdentityKey struct{}
+
+// AuthIdentity returns the identity of the HTTP client that issued the request, if any
+func AuthIdentity(ctx context.Context) string {
+	identity, _ := ctx.Value(authIdentityKey{}).(string)
+	return identity
+}
+
+// httpAuthenticator validates client credentials on the MCP endpoint
+type httpAuthenticator struct {
+	tokens     [][]byte
+	jwt        *jwtValidator
+	requireTLS bool
+}
+
+func newHTTPAuthenticator(opts HTTPOptions) *httpAuthenticator {
+	auth := &httpAuthenticator{requireTLS: opts.TLSClientCAFile != ""}
+	for _, token := range opts.BearerTokens {
+		if token != "" {
+			auth.tokens = append(auth.tokens, []byte(token))
+		}
+	}
+	if opts.OAuthIssuer != "" {
+		auth.jwt = newJWTValidator(opts.OAuthIssuer, opts.OAuthAudience, opts.OAuthJWKSURL)
+	}
+	return auth
+}
+
+// requireAuth rejects requests to next that carry no valid credentials
+func (a *httpAuthenticator) requireAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, err := a.authenticate(r)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="proxmox-ve-mcp", error="invalid_token"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), authIdentityKey{}, identity)))
+	})
+}
+
+// authenticate returns the client identity for r
+func (a *httpAuthenticator) authenticate(r *http.Request) (string, error) {
+	identity := ""
+	if a.requireTLS {
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+			return "", fmt.Errorf("client certificate required")
+		}
+		identity = "cert:" + r.TLS.VerifiedChains[0][0].Subject.CommonName
+	}
+
+	if len(a.tokens) == 0 && a.jwt == nil {
+		return identity, nil
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return "", fmt.Errorf("missing bearer token")
+	}
+
+	for _, allowed := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(token), allowed) == 1 {
+			return joinIdentity(identity, "token"), nil
+		}
+	}
+
+	if a.jwt != nil {
+		subject, err := a.jwt.validate(r.Context(), token)
+		if err != nil {
+			return "", err
+		}
+		return joinIdentity(identity, "jwt:"+subject), nil
+	}
+
+	return "", fmt.Errorf("invalid bearer token")
+}
+
+func joinIdentity(certIdentity, tokenIdentity string) string {
+	if certIdentity == "" {
+		return tokenIdentity
+	}
+	return certIdentity + "," + tokenIdentity
+}
+
+// jwtValidator verifies JWT access tokens against an issuer's JWKS
+type jwtValidator struct {
+	issuer     string
+	audience   string
+	jwksURL    string
+	httpClient *http.Client
+
+	mu      sync.RWMutex
+	keys    map[string]crypto.PublicKey
+	fetched time.Time
+}
+
+func newJWTValidator(issuer, audience, jwksURL string) *jwtValidator {
+	return &jwtValidator{
+		issuer:     issuer,
+		audience:   audience,
+		jwksURL:    jwksURL,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		keys:       map[string]crypto.PublicKey{},
+	}
+}
+
+// jwtHeader is the JOSE header of a token
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// jwtClaims holds the registered claims checked by the validator
+type jwtClaims struct {
+	Issuer    string          `json:"iss"`
+	Subject   string          `json:"sub"`
+	Audience  json.RawMessage `json:"aud"`
+	ExpiresAt int64           `json:"exp"`
+	NotBefore int64           `json:"nbf"`
+}
+
+// validate checks the signature and claims of token and returns its subject
+func (v *jwtValidator) validate(ctx context.Context, token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("malformed token")
+	}
+
+	var header jwtHeader
+	if err := decodeJWTSegment(parts[0], &header); err != nil {
+		return "", fmt.Errorf("invalid token header: %w", err)
+	}
+
+	key, err := v.key(ctx, header.Kid)
+	if err != nil {
+		return "", err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", fmt.Errorf("invalid token signature encoding: %w", err)
+	}
+	if err := verifyJWTSignature(header.Alg, key, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return "", err
+	}
+
+	var claims jwtClaims
+	if err := decodeJWTSegment(parts[1], &claims); err != nil {
+		return "", fmt.Errorf("invalid token claims: %w", err)
+	}
+
+	now := time.Now()
+	if claims.Issuer != v.issuer {
+		return "", fmt.Errorf("unexpected token issuer %q", claims.Issuer)
+	}
+	if claims.ExpiresAt == 0 || now.After(time.Unix(claims.ExpiresAt, 0).Add(jwtClockSkew)) {
+		return "", fmt.Errorf("token expired")
+	}
+	if claims.NotBefore != 0 && now.Add(jwtClockSkew).Before(time.Unix(claims.NotBefore, 0)) {
+		return "", fmt.Errorf("token not yet valid")
+	}
+	if v.audience == "" || !audienceContains(claims.Audience, v.audience) {
+		return "", fmt.Errorf("token audience does not include %q", v.audience)
+	}
+
+	return claims.Subject, nil
+}
+
+// key returns the verification key for kid, refetching the JWKS when the key is unknown
+func (v *jwtValidator) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	v.mu.RLock()
+	key, ok := v.lookup(kid)
+	stale := time.Since(v.fetched) > jwksRefreshInterval
+	v.mu.RUnlock()
+	if ok {
+		return key, nil
+	}
+	if !stale {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if key, ok := v.lookup(kid); ok {
+		return key, nil
+	}
+	if err := v.refresh(ctx); err != nil {
+		return nil, err
+	}
+	if key, ok := v.lookup(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// lookup finds a key by ID; a token without kid matches a single-key JWKS
+func (v *jwtValidator) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, true
+		}
+	}
+	key, ok := v.keys[kid]
+	return key, ok
+}
+
+// refresh downloads the JWKS, discovering its URL from the issuer if needed
+func (v *jwtValidator) refresh(ctx context.Context) error {
+	v.fetched = time.Now()
+
+	if v.jwksURL == "" {
+		var discovery struct {
+			JWKSURI string `json:"jwks_uri"`
+		}
+		discoveryURL := strings.TrimSuffix(v.issuer, "/") + "/.well-known/openid-configuration"
+		if err := v.getJSON(ctx, discoveryURL, &discovery); err != nil {
+			return fmt.Errorf("OpenID discovery failed: %w", err)
+		}
+		if discovery.JWKSURI == "" {
+			return fmt.Errorf("OpenID configuration of %s has no jwks_uri", v.issuer)
+		}
+		v.jwksURL = discovery.JWKSURI
+	}
+
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := v.getJSON(ctx, v.jwksURL, &jwks); err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+
+	keys := map[string]crypto.PublicKey{}
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	v.keys = keys
+	return nil
+}
+
+func (v *jwtValidator) getJSON(ctx context.Context, url string, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: status %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// jsonWebKey is an RSA or EC public key in JWK format
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// verifyJWTSignature checks signature over signed using the algorithm named in the header
+func verifyJWTSignature(alg string, key crypto.PublicKey, signed, signature []byte) error {
+	var h hash.Hash
+	var hashID crypto.Hash
+	switch alg {
+	case "RS256", "ES256", "PS256":
+		h, hashID = sha256.New(), crypto.SHA256
+	case "RS384", "ES384", "PS384":
+		h, hashID = sha512.New384(), crypto.SHA384
+	case "RS512", "ES512", "PS512":
+		h, hashID = sha512.New(), crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported token algorithm %q", alg)
+	}
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	switch pub := key.(type) {
+	case *rsa.PublicKey:
+		switch alg[:2] {
+		case "RS":
+			return rsa.VerifyPKCS1v15(pub, hashID, digest, signature)
+		case "PS":
+			return rsa.VerifyPSS(pub, hashID, digest, signature, nil)
+		}
+	case *ecdsa.PublicKey:
+		if alg[:2] != "ES" {
+			break
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return fmt.Errorf("invalid ECDSA signature length")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return errors.New("invalid token signature")
+		}
+		return nil
+	}
+	return fmt.Errorf("algorithm %q does not match signing key type", alg)
+}
+
+func decodeJWTSegment(segment string, result interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, result)
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+// audienceContains reports whether the aud claim (string or array) includes audience
+func audienceContains(raw json.RawMessage, audience string) bool {
+	var single string
+	if json.Unmarshal(raw, &single) == nil {
+		return single == audience
+	}
+	var list []string
+	if json.Unmarshal(raw, &list) == nil {
+		for _, aud := range list {
+			if aud == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+package mcp
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testIssuer   = "https://auth.example.com/realms/proxmox"
+	testAudience = "proxmox-ve-mcp"
+)
+
+// testJWKS serves an RSA and an EC signing key and returns a validator for them
+func testJWKS(t *testing.T) (*jwtValidator, *rsa.PrivateKey, *ecdsa.PrivateKey) {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b64 := func(n *big.Int) string { return base64.RawURLEncoding.EncodeToString(n.Bytes()) }
+	jwks := map[string]interface{}{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa", "use": "sig", "n": b64(rsaKey.N), "e": b64(big.NewInt(int64(rsaKey.E)))},
+		{"kty": "EC", "kid": "ec", "crv": "P-256", "x": b64(ecKey.X), "y": b64(ecKey.Y)},
+	}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(jwks)
+	}))
+	t.Cleanup(server.Close)
+
+	return newJWTValidator(testIssuer, testAudience, server.URL), rsaKey, ecKey
+}
+
+// signJWT builds a token with the given header and claims, signing it with key
+func signJWT(t *testing.T, header, claims map[string]interface{}, key interface{}) string {
+	t.Helper()
+	segment := func(v interface{}) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signed := segment(header) + "." + segment(claims)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	var err error
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		if header["alg"] == "PS256" {
+			signature, err = rsa.SignPSS(rand.Reader, k, crypto.SHA256, digest[:], nil)
+		} else {
+			signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		}
+	case *ecdsa.PrivateKey:
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, k, digest[:])
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestJWTValidator(t *testing.T) {
+	validator, rsaKey, ecKey := testJWKS(t)
+	now := time.Now()
+	claims := func(change func(map[string]interface{})) map[string]interface{} {
+		c := map[string]interface{}{
+			"iss": testIssuer,
+			"sub": "alice",
+			"aud": []string{"account", testAudience},
+			"exp": now.Add(time.Hour).Unix(),
+			"nbf": now.Add(-time.Minute).Unix(),
+		}
+		if change != nil {
+			change(c)
+		}
+		return c
+	}
+	rs256 := map[string]interface{}{"alg": "RS256", "kid": "rsa"}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr string
+	}{
+		{"RS256", signJWT(t, rs256, claims(nil), rsaKey), ""},
+		{"PS256", signJWT(t, map[string]interface{}{"alg": "PS256", "kid": "rsa"}, claims(nil), rsaKey), ""},
+		{"ES256", signJWT(t, map[string]interface{}{"alg": "ES256", "kid": "ec"}, claims(nil), ecKey), ""},
+		{"single audience", signJWT(t, rs256, claims(func(c map[string]interface{}) { c["aud"] = testAudience }), rsaKey), ""},
+		{"alg none", unsigned(signJWT(t, map[string]interface{}{"alg": "none", "kid": "rsa"}, claims(nil), []byte("x"))), "unsupported token algorithm"},
+		{"HS256 with the public key as secret", signJWT(t, map[string]interface{}{"alg": "HS256", "kid": "rsa"}, claims(nil), rsaKey.PublicKey.N.Bytes()), "unsupported token algorithm"},
+		{"RS256 header on EC key", signJWT(t, map[string]interface{}{"alg": "RS256", "kid": "ec"}, claims(nil), rsaKey), "does not match signing key type"},
+		{"wrong issuer", signJWT(t, rs256, claims(func(c map[string]interface{}) { c["iss"] = "https://evil.example.com" }), rsaKey), "unexpected token issuer"},
+		{"wrong audience", signJWT(t, rs256, claims(func(c map[string]interface{}) { c["aud"] = "other-client" }), rsaKey), "audience"},
+		{"no audience", signJWT(t, rs256, claims(func(c map[string]interface{}) { delete(c, "aud") }), rsaKey), "audience"},
+		{"expired", signJWT(t, rs256, claims(func(c map[string]interface{}) { c["exp"] = now.Add(-time.Hour).Unix() }), rsaKey), "token expired"},
+		{"no expiry", signJWT(t, rs256, claims(func(c map[string]interface{}) { delete(c, "exp") }), rsaKey), "token expired"},
+		{"not yet valid", signJWT(t, rs256, claims(func(c map[string]interface{}) { c["nbf"] = now.Add(time.Hour).Unix() }), rsaKey), "not yet valid"},
+		{"unknown kid", signJWT(t, map[string]interface{}{"alg": "RS256", "kid": "other"}, claims(nil), rsaKey), "unknown signing key"},
+		{"tampered claims", tamper(signJWT(t, rs256, claims(nil), rsaKey)), "verification error"},
+		{"malformed", "not-a-token", "malformed token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subject, err := validator.validate(context.Background(), tt.token)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validate() error = %v", err)
+				}
+				if subject != "alice" {
+					t.Errorf("validate() subject = %q, want alice", subject)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// unsigned drops the signature of a token, as alg none tokens carry none
+func unsigned(token string) string {
+	return token[:strings.LastIndex(token, ".")+1]
+}
+
+// tamper replaces the claims of a signed token, keeping its signature
+func tamper(token string) string {
+	parts := strings.Split(token, ".")
+	claims, _ := json.Marshal(map[string]interface{}{"iss": testIssuer, "sub": "mallory", "aud": testAudience, "exp": time.Now().Add(time.Hour).Unix()})
+	parts[1] = base64.RawURLEncoding.EncodeToString(claims)
+	return strings.Join(parts, ".")
+}
+
+func TestJWTValidatorRequiresAudience(t *testing.T) {
+	validator, rsaKey, _ := testJWKS(t)
+	validator.audience = ""
+	token := signJWT(t, map[string]interface{}{"alg": "RS256", "kid": "rsa"}, map[string]interface{}{
+		"iss": testIssuer, "sub": "alice", "aud": "any-client", "exp": time.Now().Add(time.Hour).Unix(),
+	}, rsaKey)
+	if _, err := validator.validate(context.Background(), token); err == nil {
+		t.Error("validate() accepted a token without a configured audience")
+	}
+}
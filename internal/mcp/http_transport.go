@@ -0,0 +1,322 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// httpShutdownTimeout bounds how long in-flight requests may run after ctx is cancelled
+	httpShutdownTimeout = 10 * time.Second
+	// sseHeartbeatInterval keeps idle SSE streams alive through proxies and load balancers
+	sseHeartbeatInterval = 30 * time.Second
+	// sseReplayLimit is the number of events retained per session for resumption
+	sseReplayLimit = 256
+	// sseReplayTTL is how long events of an idle session are retained
+	sseReplayTTL = time.Hour
+)
+
+// ServeHTTP starts the MCP server with the Streamable HTTP transport.
+// POST /mcp carries client requests, GET /mcp opens an SSE stream for
+// server-to-client messages and DELETE /mcp terminates a session. GET
+// /metrics serves tool call metrics for Prometheus. When opts configures
+// bearer tokens, OAuth or mutual TLS, /mcp and /metrics require valid client
+// credentials; /health is always unauthenticated. The server shuts down
+// gracefully when ctx is cancelled.
+func (s *Server) ServeHTTP(ctx context.Context, addr string, opts HTTPOptions) error {
+	tlsConfig, err := opts.tlsConfig()
+	if err != nil {
+		return err
+	}
+
+	scheme := "HTTP"
+	if tlsConfig != nil {
+		scheme = "HTTPS"
+	}
+	s.logger.Infof("Starting Proxmox VE MCP Server on %s at %s", scheme, addr)
+	if !opts.authEnabled() {
+		s.logger.Warn("HTTP transport has no client authentication configured")
+	}
+
+	streamable := server.NewStreamableHTTPServer(s.server,
+		server.WithSessionIdManager(&server.InsecureStatefulSessionIdManager{}),
+		server.WithHeartbeatInterval(sseHeartbeatInterval),
+		server.WithLogger(s.logger),
+	)
+
+	mux := http.NewServeMux()
+	var mcpHandler http.Handler = newSSEEventStore(sseReplayLimit, sseReplayTTL).wrap(s.subscriptionHandler(streamable))
+	var metricsHandler http.Handler = s.metrics
+	if opts.authEnabled() {
+		auth := newHTTPAuthenticator(opts)
+		mcpHandler = auth.requireAuth(mcpHandler)
+		metricsHandler = auth.requireAuth(metricsHandler)
+	}
+	mux.Handle("/mcp", mcpHandler)
+	mux.Handle("/metrics", metricsHandler)
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"status": "healthy"})
+	})
+
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		TLSConfig:         tlsConfig,
+		BaseContext: func(_ net.Listener) context.Context {
+			return ctx
+		},
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		if tlsConfig != nil {
+			errCh <- httpServer.ListenAndServeTLS("", "")
+			return
+		}
+		errCh <- httpServer.ListenAndServe()
+	}()
+	go s.watchResources(ctx)
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	s.logger.Info("Shutting down HTTP transport")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), httpShutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("HTTP server shutdown: %w", err)
+	}
+	if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// sseEventStore retains recent SSE events per session so that a client that
+// reconnects its GET stream with a Last-Event-ID header receives the messages
+// it missed while disconnected.
+type sseEventStore struct {
+	mu       sync.Mutex
+	limit    int
+	ttl      time.Duration
+	sessions map[string]*sseSessionEvents
+}
+
+// sseSessionEvents is the replay buffer of a single MCP session
+type sseSessionEvents struct {
+	nextID   uint64
+	events   []sseEvent
+	lastSeen time.Time
+}
+
+// sseEvent is a stored SSE frame without its id line
+type sseEvent struct {
+	id    uint64
+	frame []byte
+}
+
+func newSSEEventStore(limit int, ttl time.Duration) *sseEventStore {
+	return &sseEventStore{
+		limit:    limit,
+		ttl:      ttl,
+		sessions: make(map[string]*sseSessionEvents),
+	}
+}
+
+// wrap adds event IDs and replay support to the GET stream of next
+func (st *sseEventStore) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sessionID := r.Header.Get(server.HeaderKeySessionID)
+		if sessionID == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			st.prune()
+			var replay []sseEvent
+			if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+				replay = st.since(sessionID, lastEventID)
+			}
+			w = &sseRecorder{ResponseWriter: w, store: st, sessionID: sessionID, replay: replay}
+		case http.MethodDelete:
+			st.forget(sessionID)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// append stores a frame for the session and returns its event ID
+func (st *sseEventStore) append(sessionID string, frame []byte) uint64 {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	events, ok := st.sessions[sessionID]
+	if !ok {
+		events = &sseSessionEvents{}
+		st.sessions[sessionID] = events
+	}
+	events.nextID++
+	events.lastSeen = time.Now()
+	events.events = append(events.events, sseEvent{id: events.nextID, frame: frame})
+	if len(events.events) > st.limit {
+		events.events = events.events[len(events.events)-st.limit:]
+	}
+	return events.nextID
+}
+
+// since returns the stored events of a session that follow lastEventID
+func (st *sseEventStore) since(sessionID, lastEventID string) []sseEvent {
+	lastID, err := strconv.ParseUint(lastEventID, 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	events, ok := st.sessions[sessionID]
+	if !ok {
+		return nil
+	}
+	events.lastSeen = time.Now()
+
+	var replay []sseEvent
+	for _, event := range events.events {
+		if event.id > lastID {
+			replay = append(replay, event)
+		}
+	}
+	return replay
+}
+
+// forget drops the replay buffer of a terminated session
+func (st *sseEventStore) forget(sessionID string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	delete(st.sessions, sessionID)
+}
+
+// prune drops replay buffers of sessions idle for longer than the TTL
+func (st *sseEventStore) prune() {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	cutoff := time.Now().Add(-st.ttl)
+	for sessionID, events := range st.sessions {
+		if events.lastSeen.Before(cutoff) {
+			delete(st.sessions, sessionID)
+		}
+	}
+}
+
+// sseRecorder intercepts SSE frames written to a GET stream, assigns each an
+// event ID and records it in the session's replay buffer. Heartbeat pings are
+// passed through untagged, as replaying them after a reconnect is pointless.
+type sseRecorder struct {
+	http.ResponseWriter
+	store     *sseEventStore
+	sessionID string
+	replay    []sseEvent
+	pending   []byte
+}
+
+// WriteHeader sends the response headers followed by any events to replay
+func (rw *sseRecorder) WriteHeader(statusCode int) {
+	rw.ResponseWriter.WriteHeader(statusCode)
+	if statusCode != http.StatusOK || !rw.isEventStream() {
+		rw.replay = nil
+		return
+	}
+	for _, event := range rw.replay {
+		rw.writeFrame(event.id, event.frame)
+	}
+	rw.replay = nil
+}
+
+// Write splits the stream into SSE frames and tags each with an event ID
+func (rw *sseRecorder) Write(p []byte) (int, error) {
+	if !rw.isEventStream() {
+		return rw.ResponseWriter.Write(p)
+	}
+
+	rw.pending = append(rw.pending, p...)
+	for {
+		end := bytes.Index(rw.pending, []byte("\n\n"))
+		if end < 0 {
+			break
+		}
+		frame := append([]byte(nil), rw.pending[:end+2]...)
+		rw.pending = rw.pending[end+2:]
+		if isPingFrame(frame) {
+			if _, err := rw.ResponseWriter.Write(frame); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		if err := rw.writeFrame(rw.store.append(rw.sessionID, frame), frame); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush implements http.Flusher so streaming keeps working through the wrapper
+func (rw *sseRecorder) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (rw *sseRecorder) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+func (rw *sseRecorder) isEventStream() bool {
+	return rw.Header().Get("Content-Type") == "text/event-stream"
+}
+
+func (rw *sseRecorder) writeFrame(id uint64, frame []byte) error {
+	if _, err := fmt.Fprintf(rw.ResponseWriter, "id: %d\n", id); err != nil {
+		return err
+	}
+	_, err := rw.ResponseWriter.Write(frame)
+	return err
+}
+
+// isPingFrame reports whether an SSE frame carries a heartbeat ping request
+func isPingFrame(frame []byte) bool {
+	for _, line := range bytes.Split(frame, []byte("\n")) {
+		data, found := bytes.CutPrefix(line, []byte("data: "))
+		if !found {
+			continue
+		}
+		var message struct {
+			Method string `json:"method"`
+		}
+		return json.Unmarshal(data, &message) == nil && message.Method == "ping"
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// toolMetrics counts tool calls per tool and outcome
+type toolMetrics struct {
+	mu    sync.Mutex
+	tools map[string]*toolStats
+}
+
+type toolStats struct {
+	calls    map[string]uint64
+	duration time.Duration
+}
+
+func newToolMetrics() *toolMetrics {
+	return &toolMetrics{tools: map[string]*toolStats{}}
+}
+
+func (m *toolMetrics) observe(tool, outcome string, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats, ok := m.tools[tool]
+	if !ok {
+		stats = &toolStats{calls: map[string]uint64{}}
+		m.tools[tool] = stats
+	}
+	stats.calls[outcome]++
+	stats.duration += duration
+}
+
+// writePrometheus writes the metrics in the Prometheus text format
+func (m *toolMetrics) writePrometheus(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tools := make([]string, 0, len(m.tools))
+	for tool := range m.tools {
+		tools = append(tools, tool)
+	}
+	sort.Strings(tools)
+
+	fmt.Fprintln(w, "# HELP proxmox_mcp_tool_calls_total Tool calls by tool and outcome.")
+	fmt.Fprintln(w, "# TYPE proxmox_mcp_tool_calls_total counter")
+	for _, tool := range tools {
+		for _, outcome := range []string{"success", "error"} {
+			fmt.Fprintf(w, "proxmox_mcp_tool_calls_total{tool=%q,outcome=%q} %d\n", tool, outcome, m.tools[tool].calls[outcome])
+		}
+	}
+	fmt.Fprintln(w, "# HELP proxmox_mcp_tool_duration_seconds_total Time spent in tool calls by tool.")
+	fmt.Fprintln(w, "# TYPE proxmox_mcp_tool_duration_seconds_total counter")
+	for _, tool := range tools {
+		fmt.Fprintf(w, "proxmox_mcp_tool_duration_seconds_total{tool=%q} %g\n", tool, m.tools[tool].duration.Seconds())
+	}
+}
+
+// ServeHTTP serves the metrics for Prometheus
+func (m *toolMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	m.writePrometheus(w)
+}
+
+// withMetrics counts every call and its duration
+func (s *Server) withMetrics(name string, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		result, err := next(ctx, request)
+
+		outcome := "success"
+		if err != nil || (result != nil && result.IsError) {
+			outcome = "error"
+		}
+		s.metrics.observe(name, outcome, time.Since(start))
+		return result, err
+	}
+}
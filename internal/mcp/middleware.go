@@ -0,0 +1,130 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/surrealwolf/proxmox-ve-mcp/internal/proxmox"
+)
+
+// ToolMiddleware wraps the handler of the named tool. Middleware that does
+// not apply to a tool returns next unchanged.
+type ToolMiddleware func(name string, next server.ToolHandlerFunc) server.ToolHandlerFunc
+
+// middleware returns the chain every tool handler is wrapped in, outermost
+// first. Calls are logged, measured and audited before anything else runs so
+// that rejected calls are recorded too; panics below the audit are recovered
+// into error results.
+func (s *Server) middleware() []ToolMiddleware {
+	return []ToolMiddleware{
+		s.withLogging,
+		s.withMetrics,
+		s.withAudit,
+		s.withRecovery,
+		s.withAuthorization,
+		s.withRateLimit,
+		s.withTimeout,
+		s.withValidation,
+		s.withCluster,
+		s.withGuest,
+		s.withDryRun,
+		s.withConfirmation,
+	}
+}
+
+// wrapTool applies the middleware chain to the handler of the named tool
+func (s *Server) wrapTool(name string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	chain := s.middleware()
+	for i := len(chain) - 1; i >= 0; i-- {
+		handler = chain[i](name, handler)
+	}
+	return handler
+}
+
+// withLogging logs every call with its outcome and duration
+func (s *Server) withLogging(name string, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		s.logger.Debugf("Tool called: %s", name)
+		start := time.Now()
+		result, err := next(ctx, request)
+
+		logger := s.logger.WithField("tool", name).WithField("duration", time.Since(start).Round(time.Millisecond))
+		switch {
+		case err != nil:
+			logger.WithError(err).Warn("Tool call failed")
+		case result != nil && result.IsError:
+			logger.Debugf("Tool call returned an error: %s", resultText(result))
+		default:
+			logger.Debug("Tool call finished")
+		}
+		return result, err
+	}
+}
+
+// withRecovery turns a panic in a tool into an error result instead of
+// taking the whole server down
+func (s *Server) withRecovery(name string, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (result *mcp.CallToolResult, err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				s.logger.WithField("tool", name).Errorf("Tool panicked: %v\n%s", recovered, debug.Stack())
+				result, err = mcp.NewToolResultError(fmt.Sprintf("internal error in %s: %v", name, recovered)), nil
+			}
+		}()
+		return next(ctx, request)
+	}
+}
+
+// templateTaskCount is the number of tasks create_template_from_image may
+// wait for: download, VM creation, disk resize and template conversion
+const templateTaskCount = 4
+
+// withTimeout bounds the run time of every call when a tool timeout is set.
+// Calls that wait for Proxmox tasks get the time they asked to wait on top.
+func (s *Server) withTimeout(name string, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	if s.options.ToolTimeout <= 0 {
+		return next
+	}
+
+	schema := s.schemas[name]
+	waits := schema != nil && schema.has("wait")
+
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		timeout := s.options.ToolTimeout
+		if waits || name == "create_template_from_image" {
+			timeout += taskWaitTime(name, request)
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		result, err := next(ctx, request)
+		if ctx.Err() == context.DeadlineExceeded {
+			return mcp.NewToolResultError(fmt.Sprintf("%s did not finish within %s; requests already sent to Proxmox may still complete", name, timeout)), nil
+		}
+		return result, err
+	}
+}
+
+// taskWaitTime returns how long a call may wait for Proxmox tasks: the
+// timeout_seconds of a call with wait set, and the per-task timeout of each
+// step of create_template_from_image
+func taskWaitTime(name string, request mcp.CallToolRequest) time.Duration {
+	if name == "create_template_from_image" {
+		perTask := proxmox.DefaultTemplateTaskTimeout
+		if seconds := request.GetInt("task_timeout_seconds", 0); seconds > 0 {
+			perTask = time.Duration(seconds) * time.Second
+		}
+		return templateTaskCount * perTask
+	}
+	if !request.GetBool("wait", false) {
+		return 0
+	}
+	if seconds := request.GetInt("timeout_seconds", 0); seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return defaultTaskWaitTimeout
+}
@@ -0,0 +1,419 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/surrealwolf/proxmox-ve-mcp/internal/proxmox"
+)
+
+// promptDefinition describes an MCP prompt: one of the playbooks of
+// .github/skills, completed with the current state of the cluster when the
+// prompt is requested
+type promptDefinition struct {
+	name        string
+	description string
+	arguments   []promptArgument
+	// tools are the tools whose data the prompt includes. The prompt is
+	// registered when they pass the tool filter and is available to the
+	// clients that may call them.
+	tools []string
+	build func(ctx context.Context, client *proxmox.Client, args map[string]string) (string, error)
+}
+
+// promptArgument is an argument of a prompt. Every prompt also accepts an
+// optional cluster argument.
+type promptArgument struct {
+	name        string
+	description string
+	required    bool
+}
+
+// promptDefinitions returns every prompt the server exposes
+func (s *Server) promptDefinitions() []promptDefinition {
+	return []promptDefinition{
+		{
+			name:        "investigate_slow_vm",
+			description: "Investigate why a VM is slow, from its allocation, usage, disks, node load and recent tasks",
+			arguments: []promptArgument{
+				{name: "vmid", description: "ID of the slow VM", required: true},
+			},
+			tools: []string{"get_vm_status", "get_vm_config", "get_node_status", "get_node_tasks"},
+			build: investigateSlowVMPrompt,
+		},
+		{
+			name:        "prepare_node_maintenance",
+			description: "Plan moving the guests off a node so it can be updated or rebooted",
+			arguments: []promptArgument{
+				{name: "node", description: "Node that goes into maintenance", required: true},
+			},
+			tools: []string{"get_cluster_resources", "get_node_status", "get_ha_status"},
+			build: prepareNodeMaintenancePrompt,
+		},
+		{
+			name:        "create_template_from_image",
+			description: "Build a cloud-init VM template from a cloud image",
+			arguments: []promptArgument{
+				{name: "node", description: "Node to create the template on", required: true},
+				{name: "vmid", description: "ID of the new template", required: true},
+				{name: "image_url", description: "URL of the cloud image, e.g. an Ubuntu cloud image", required: true},
+				{name: "storage", description: "Storage for the template disk (default: chosen from the node's storage)"},
+				{name: "name", description: "Name of the template"},
+			},
+			tools: []string{"get_cluster_resources", "get_node_storage", "get_node_network"},
+			build: createTemplatePrompt,
+		},
+		{
+			name:        "recover_guest",
+			description: "Recover a VM or container from a snapshot or backup",
+			arguments: []promptArgument{
+				{name: "vmid", description: "ID of the VM or container to recover", required: true},
+				{name: "node", description: "Node to look for backups from when the guest no longer exists"},
+			},
+			tools: []string{"get_cluster_resources", "list_backups", "list_vm_snapshots", "list_container_snapshots"},
+			build: recoverGuestPrompt,
+		},
+		{
+			name:        "cluster_health_check",
+			description: "Check quorum, nodes, storage and recent task failures of the cluster",
+			tools:       []string{"get_cluster_status", "get_cluster_resources", "get_cluster_tasks"},
+			build:       clusterHealthPrompt,
+		},
+	}
+}
+
+// registerPrompts registers the prompts whose tools pass the tool filter
+func (s *Server) registerPrompts() {
+	for _, def := range s.promptDefinitions() {
+		permitted := true
+		for _, tool := range def.tools {
+			permitted = permitted && s.options.Tools.permits(tool)
+		}
+		if !permitted {
+			continue
+		}
+
+		options := []mcp.PromptOption{mcp.WithPromptDescription(def.description)}
+		for _, arg := range def.arguments {
+			argOptions := []mcp.ArgumentOption{mcp.ArgumentDescription(arg.description)}
+			if arg.required {
+				argOptions = append(argOptions, mcp.RequiredArgument())
+			}
+			options = append(options, mcp.WithArgument(arg.name, argOptions...))
+		}
+		options = append(options, mcp.WithArgument("cluster", mcp.ArgumentDescription("Cluster to operate on (optional, default cluster if omitted)")))
+
+		def := def
+		s.server.AddPrompt(mcp.NewPrompt(def.name, options...), func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			return s.getPrompt(ctx, def, request.Params.Arguments)
+		})
+	}
+}
+
+// getPrompt builds a prompt for the client of ctx
+func (s *Server) getPrompt(ctx context.Context, def promptDefinition, args map[string]string) (*mcp.GetPromptResult, error) {
+	for _, tool := range def.tools {
+		if !s.mayCall(ctx, tool) {
+			s.logger.WithField("prompt", def.name).Warnf("Denied prompt for client %q", AuthIdentity(ctx))
+			return nil, fmt.Errorf("not authorized to use prompt %s", def.name)
+		}
+	}
+
+	var missing []string
+	for _, arg := range def.arguments {
+		if arg.required && strings.TrimSpace(args[arg.name]) == "" {
+			missing = append(missing, arg.name+" is required")
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &ArgumentError{Problems: missing}
+	}
+
+	client, err := s.clusters.Get(args["cluster"])
+	if err != nil {
+		return nil, err
+	}
+	text, err := def.build(ctx, client, args)
+	if err != nil {
+		return nil, err
+	}
+	return mcp.NewGetPromptResult(def.description, []mcp.PromptMessage{
+		mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text)),
+	}), nil
+}
+
+// promptText is the text of a prompt: the playbook followed by the current
+// state of the cluster, one JSON block per item
+type promptText struct {
+	playbook strings.Builder
+	state    strings.Builder
+}
+
+func (p *promptText) line(format string, args ...interface{}) {
+	fmt.Fprintf(&p.playbook, format+"\n", args...)
+}
+
+// add appends an item of the current state. Items that could not be read are
+// included with the error, so the playbook can still be followed.
+func (p *promptText) add(title string, value interface{}, err error) {
+	fmt.Fprintf(&p.state, "\n### %s\n\n", title)
+	if err != nil {
+		fmt.Fprintf(&p.state, "Unavailable: %v\n", err)
+		return
+	}
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		fmt.Fprintf(&p.state, "Unavailable: %v\n", err)
+		return
+	}
+	fmt.Fprintf(&p.state, "```json\n%s\n```\n", data)
+}
+
+func (p *promptText) String() string {
+	return p.playbook.String() + "\n## Current state\n" + p.state.String()
+}
+
+// pick returns the given fields of cluster/resources entries
+func pick(resources []map[string]interface{}, fields ...string) []map[string]interface{} {
+	picked := make([]map[string]interface{}, 0, len(resources))
+	for _, resource := range resources {
+		entry := make(map[string]interface{}, len(fields))
+		for _, field := range fields {
+			if value, ok := resource[field]; ok {
+				entry[field] = value
+			}
+		}
+		picked = append(picked, entry)
+	}
+	return picked
+}
+
+func investigateSlowVMPrompt(ctx context.Context, client *proxmox.Client, args map[string]string) (string, error) {
+	vmid, node, err := guestNode(ctx, client, "qemu", args["vmid"])
+	if err != nil {
+		return "", err
+	}
+
+	var p promptText
+	p.line("Investigate why VM %d on node %s is slow. Do not change anything without asking first.", vmid, node)
+	p.line("")
+	p.line("1. Compare the VM's CPU and memory usage below with its allocation (cores, sockets, memory, balloon). Sustained CPU near 100%% of its cores or memory near its maximum means the VM is undersized.")
+	p.line("2. Check whether node %s is overloaded: CPU usage and IO wait, load average against its core count, memory and swap usage.", node)
+	p.line("3. Review the disk configuration: bus (virtio-scsi is preferred over ide and sata), cache mode, iothread and discard, and the storage each disk lives on.")
+	p.line("4. Look at the recent tasks of the VM for backups, snapshots, migrations or clones that may compete for IO.")
+	p.line("5. If needed, use get_vm_stats and get_node_stats for usage over time.")
+	p.line("6. Report the likely causes, most likely first, and recommend changes such as update_vm_config for more resources or better disk settings, or migrate_vm to a less loaded node.")
+
+	status, err := client.GetVM(ctx, node, vmid)
+	p.add("VM status", status, err)
+	config, err := client.GetVMConfig(ctx, node, vmid)
+	p.add("VM configuration", config, err)
+	nodeStatus, err := client.GetNode(ctx, node)
+	p.add("Node "+node+" status", nodeStatus, err)
+	tasks, err := client.GetNodeTasks(ctx, node, proxmox.TaskFilter{VMID: vmid, Limit: 10})
+	p.add("Recent tasks of the VM", tasks, err)
+	return p.String(), nil
+}
+
+func prepareNodeMaintenancePrompt(ctx context.Context, client *proxmox.Client, args map[string]string) (string, error) {
+	node := strings.TrimSpace(args["node"])
+	nodes, err := client.GetClusterResourcesOfType(ctx, "node")
+	if err != nil {
+		return "", err
+	}
+	var others []map[string]interface{}
+	found := false
+	for _, resource := range nodes {
+		if resource["node"] == node {
+			found = true
+		} else {
+			others = append(others, resource)
+		}
+	}
+	if !found {
+		return "", fmt.Errorf("node %s not found", node)
+	}
+
+	all, err := client.GetGuests(ctx)
+	if err != nil {
+		return "", err
+	}
+	guests := []proxmox.Guest{}
+	for _, guest := range all {
+		if guest.Node == node {
+			guests = append(guests, guest)
+		}
+	}
+
+	var p promptText
+	p.line("Prepare node %s for maintenance so it can be updated or rebooted without downtime for its guests.", node)
+	p.line("")
+	p.line("1. Review the guests on %s below. Stopped guests can stay; running ones must be moved or shut down.", node)
+	p.line("2. For each running VM pick a target among the other online nodes with enough free memory (maxmem - mem) and plan migrate_vm with online=true. Spread the VMs so no target is overcommitted.")
+	p.line("3. Running containers cannot be live-migrated; plan a shutdown_container before the maintenance and a start_container after it.")
+	p.line("4. Guests managed by HA (hastate set) are relocated by the HA manager; check get_ha_status and do not migrate them by hand while HA acts on them.")
+	p.line("5. Present the plan and wait for approval. Then run it, passing wait=true so each migration finishes before the next starts, and confirm with get_node_tasks that nothing is still running on %s.", node)
+	p.line("6. Only then run the maintenance itself, e.g. apply_node_updates and reboot_node, and afterwards move the guests back if the user wants.")
+
+	nodeStatus, err := client.GetNode(ctx, node)
+	p.add("Node "+node+" status", nodeStatus, err)
+	p.add("Guests on "+node, guests, nil)
+	p.add("Other nodes", pick(others, "node", "status", "cpu", "maxcpu", "mem", "maxmem"), nil)
+	ha, err := client.GetHAStatus(ctx)
+	p.add("HA status", ha, err)
+	return p.String(), nil
+}
+
+func createTemplatePrompt(ctx context.Context, client *proxmox.Client, args map[string]string) (string, error) {
+	node := strings.TrimSpace(args["node"])
+	vmid, err := parseVMID(args["vmid"])
+	if err != nil {
+		return "", err
+	}
+	name := strings.TrimSpace(args["name"])
+	if name == "" {
+		name = fmt.Sprintf("template-%d", vmid)
+	}
+	storage, diskStorage := strings.TrimSpace(args["storage"]), strings.TrimSpace(args["storage"])
+	if storage == "" {
+		storage = "a storage with images content from the list below"
+		diskStorage = "<disk storage>"
+	}
+
+	var p promptText
+	p.line("Create a cloud-init VM template %q with ID %d on node %s from the cloud image %s, using %s for its disk.", name, vmid, node, args["image_url"], storage)
+	p.line("")
+	p.line("1. Check below that VMID %d is free, and pick a storage with import content for the image, a storage with images content for the disk and the network bridge (usually vmbr0).", vmid)
+	p.line("2. Build the template with create_template_from_image: node_name=%s, vmid=%d, name=%s, image_url=%s, image_storage=<import storage>, disk_storage=%s, bridge=<bridge>. It downloads the image, creates the VM with the image imported as scsi0, a cloud-init drive and a serial console, sets the boot order and converts the VM to a template.", node, vmid, name, args["image_url"], diskStorage)
+	p.line("3. Optionally pass disk_size (e.g. 20G) and cloud-init defaults (user, ssh_keys, ip_config {\"0\": \"ip=dhcp\"}) in the same call.")
+	p.line("4. If it fails, report the failed step and its task log from the result. The partial VM has already been removed (rolled_back), and a downloaded image is reused on retry.")
+	p.line("5. Verify the template: clone_vm a test VM, start it, confirm it boots and cloud-init applies, then delete the test VM.")
+
+	guests, err := client.FindGuests(ctx, proxmox.GuestSelector{VMID: vmid})
+	if len(guests) > 0 {
+		p.add(fmt.Sprintf("VMID %d is already in use", vmid), guests[0], nil)
+	} else {
+		p.add(fmt.Sprintf("VMID %d", vmid), map[string]interface{}{"vmid": vmid, "in_use": false}, err)
+	}
+	storages, err := client.GetNodeStorage(ctx, node)
+	p.add("Storage of "+node, storages, err)
+	network, err := client.GetNodeNetwork(ctx, node)
+	p.add("Network bridges of "+node, bridges(network), err)
+	return p.String(), nil
+}
+
+// bridges returns the bridge interfaces of a node's network configuration
+func bridges(network interface{}) []interface{} {
+	interfaces, _ := network.([]interface{})
+	found := []interface{}{}
+	for _, item := range interfaces {
+		if iface, ok := item.(map[string]interface{}); ok && iface["type"] == "bridge" {
+			found = append(found, iface)
+		}
+	}
+	return found
+}
+
+func recoverGuestPrompt(ctx context.Context, client *proxmox.Client, args map[string]string) (string, error) {
+	vmid, err := parseVMID(args["vmid"])
+	if err != nil {
+		return "", err
+	}
+	guests, err := client.FindGuests(ctx, proxmox.GuestSelector{VMID: vmid})
+	if err != nil {
+		return "", err
+	}
+	var guest *proxmox.Guest
+	if len(guests) > 0 {
+		guest = &guests[0]
+	}
+
+	node := strings.TrimSpace(args["node"])
+	if guest != nil {
+		node = guest.Node
+	}
+	if node == "" {
+		nodes, err := client.GetClusterResourcesOfType(ctx, "node")
+		if err != nil {
+			return "", err
+		}
+		for _, resource := range nodes {
+			if resource["status"] == "online" {
+				node, _ = resource["node"].(string)
+				break
+			}
+		}
+		if node == "" {
+			return "", fmt.Errorf("no online node to look for backups from")
+		}
+	}
+
+	var p promptText
+	p.line("Recover guest %d. Do not restore or roll back anything before the user has confirmed the recovery point.", vmid)
+	p.line("")
+	var steps []string
+	if guest != nil {
+		p.line("The guest still exists on node %s (see below).", node)
+		steps = append(steps,
+			"If a snapshot predates the problem, rolling back to it is the fastest recovery: restore_vm_snapshot or restore_container_snapshot. Everything after the snapshot is lost.",
+			"Otherwise restore a backup. Restoring over an existing guest replaces its disks, so suggest a backup of the current state first (create_vm_backup or create_container_backup) unless the user declines.",
+		)
+	} else {
+		p.line("No guest with this ID exists any more, so it has to be restored from a backup.")
+	}
+	steps = append(steps,
+		"Pick the most recent backup created before the problem (ctime is a Unix timestamp) and confirm it with the user.",
+		fmt.Sprintf("Restore it with restore_vm_backup for qemu backups or restore_container_backup for lxc backups on node %s, passing wait=true.", node),
+		"Check the task log if the restore fails, then start the guest and verify its status.",
+		"Summarise what was restored, from which point in time, and what data may have been lost.",
+	)
+	for i, step := range steps {
+		p.line("%d. %s", i+1, step)
+	}
+
+	if guest != nil {
+		p.add("Guest", guest, nil)
+		var snapshots []map[string]interface{}
+		if guest.Type == "lxc" {
+			snapshots, err = client.ListContainerSnapshots(ctx, node, vmid)
+		} else {
+			snapshots, err = client.ListVMSnapshots(ctx, node, vmid)
+		}
+		p.add("Snapshots", snapshots, err)
+	}
+	backups, err := client.FindGuestBackups(ctx, node, vmid)
+	sort.Slice(backups, func(i, j int) bool { return backups[i].CTime > backups[j].CTime })
+	p.add("Backups visible from "+node+", newest first", backups, err)
+	return p.String(), nil
+}
+
+func clusterHealthPrompt(ctx context.Context, client *proxmox.Client, _ map[string]string) (string, error) {
+	var p promptText
+	p.line("Check the health of the Proxmox cluster and write a short report.")
+	p.line("")
+	p.line("1. Quorum: the cluster entry must report quorate, and every node should be online. Flag offline nodes first.")
+	p.line("2. Node load: flag nodes above 80%% CPU or memory, and large differences between nodes that suggest rebalancing with migrate_vm.")
+	p.line("3. Storage: flag storage above 80%% usage (disk against maxdisk) and storage that is not available.")
+	p.line("4. Tasks: review the recent failed tasks below; use get_task_log on them for the cause.")
+	p.line("5. End with a list of issues ordered by severity and a recommendation for each. Only report; change nothing.")
+
+	status, err := client.GetClusterStatus(ctx)
+	p.add("Cluster status", status, err)
+	nodes, err := client.GetClusterResourcesOfType(ctx, "node")
+	p.add("Nodes", pick(nodes, "node", "status", "cpu", "maxcpu", "mem", "maxmem", "uptime"), err)
+	storages, err := client.GetClusterResourcesOfType(ctx, "storage")
+	p.add("Storage", pick(storages, "storage", "node", "status", "disk", "maxdisk", "shared"), err)
+	tasks, err := client.GetClusterTasks(ctx)
+	failed := []proxmox.Task{}
+	for _, task := range tasks {
+		if task.Status != "" && task.Status != "OK" && len(failed) < 20 {
+			failed = append(failed, task)
+		}
+	}
+	p.add("Recent failed tasks", failed, err)
+	return p.String(), nil
+}
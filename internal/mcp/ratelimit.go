@@ -0,0 +1,96 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// rateLimiter is a token bucket per client
+type rateLimiter struct {
+	mu      sync.Mutex
+	rate    float64 // tokens per second
+	burst   float64
+	buckets map[string]*tokenBucket
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// maxIdleBuckets is the number of buckets above which full buckets are dropped
+const maxIdleBuckets = 1024
+
+func newRateLimiter(perMinute, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rate:    float64(perMinute) / 60,
+		burst:   float64(burst),
+		buckets: map[string]*tokenBucket{},
+	}
+}
+
+// allow takes a token from the bucket of key, or returns how long to wait
+// for the next one
+func (l *rateLimiter) allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if len(l.buckets) > maxIdleBuckets {
+		for k, bucket := range l.buckets {
+			if bucket.tokens+now.Sub(bucket.last).Seconds()*l.rate >= l.burst {
+				delete(l.buckets, k)
+			}
+		}
+	}
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = bucket
+	}
+	bucket.tokens = math.Min(l.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*l.rate)
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		wait := time.Duration((1 - bucket.tokens) / l.rate * float64(time.Second))
+		return false, wait
+	}
+	bucket.tokens--
+	return true, 0
+}
+
+// callerKey identifies the client of a call: its authenticated identity, or
+// its MCP session
+func callerKey(ctx context.Context) string {
+	if identity := AuthIdentity(ctx); identity != "" {
+		return identity
+	}
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
+
+// withRateLimit limits the calls per minute of every client
+func (s *Server) withRateLimit(name string, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	if s.rateLimiter == nil {
+		return next
+	}
+
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if ok, wait := s.rateLimiter.allow(callerKey(ctx)); !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("rate limit of %d calls per minute exceeded, retry in %s", s.options.RateLimit, wait.Truncate(time.Second)+time.Second)), nil
+		}
+		return next(ctx, request)
+	}
+}
@@ -0,0 +1,243 @@
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/surrealwolf/proxmox-ve-mcp/internal/proxmox"
+)
+
+// resourceDefinition describes an MCP resource, or a resource template when
+// uri contains variables such as {node}. Every URI takes an optional
+// ?cluster= query naming the cluster to read from, the default cluster
+// otherwise. A client may read a resource when it may call tool, the tool
+// that returns the same data.
+type resourceDefinition struct {
+	uri         string
+	name        string
+	description string
+	tool        string
+	// volatile lists the fields, at any depth, that change on every read
+	// (usage figures and counters) and are ignored when looking for changes
+	volatile []string
+	read     func(ctx context.Context, client *proxmox.Client, vars map[string]string) (interface{}, error)
+	template *mcp.URITemplate
+}
+
+// resourceDefinitions returns every resource the server exposes
+func (s *Server) resourceDefinitions() []resourceDefinition {
+	return []resourceDefinition{
+		{
+			uri:         "proxmox://cluster/resources",
+			name:        "Cluster resources",
+			description: "Nodes, VMs, containers and storage of the cluster with their status",
+			tool:        "get_cluster_resources",
+			volatile:    []string{"cpu", "mem", "disk", "uptime", "netin", "netout", "diskread", "diskwrite"},
+			read: func(ctx context.Context, client *proxmox.Client, _ map[string]string) (interface{}, error) {
+				return client.GetClusterResources(ctx)
+			},
+		},
+		{
+			uri:         "proxmox://nodes/{node}/status",
+			name:        "Node status",
+			description: "Status of a node: versions, CPU, memory, swap and root filesystem",
+			tool:        "get_node_status",
+			volatile:    []string{"uptime", "cpu", "idle", "wait", "memory", "swap", "rootfs", "loadavg"},
+			read: func(ctx context.Context, client *proxmox.Client, vars map[string]string) (interface{}, error) {
+				return client.GetNode(ctx, vars["node"])
+			},
+		},
+		{
+			uri:         "proxmox://vms/{vmid}/config",
+			name:        "VM configuration",
+			description: "Configuration of a VM, on whichever node it runs",
+			tool:        "get_vm_config",
+			read: func(ctx context.Context, client *proxmox.Client, vars map[string]string) (interface{}, error) {
+				vmid, node, err := guestNode(ctx, client, "qemu", vars["vmid"])
+				if err != nil {
+					return nil, err
+				}
+				return client.GetVMConfig(ctx, node, vmid)
+			},
+		},
+		{
+			uri:         "proxmox://containers/{vmid}/config",
+			name:        "Container configuration",
+			description: "Configuration of an LXC container, on whichever node it runs",
+			tool:        "get_container_config",
+			read: func(ctx context.Context, client *proxmox.Client, vars map[string]string) (interface{}, error) {
+				vmid, node, err := guestNode(ctx, client, "lxc", vars["vmid"])
+				if err != nil {
+					return nil, err
+				}
+				return client.GetContainerConfig(ctx, node, vmid)
+			},
+		},
+		{
+			uri:         "proxmox://tasks/{upid}/log",
+			name:        "Task log",
+			description: "Log lines of a task identified by its UPID; subscribe to follow a running task",
+			tool:        "get_task_log",
+			read: func(ctx context.Context, client *proxmox.Client, vars map[string]string) (interface{}, error) {
+				return client.GetFullTaskLog(ctx, vars["upid"])
+			},
+		},
+	}
+}
+
+// registerResources registers the resources whose tool passes the tool
+// filter, so read-only mode and allow/deny lists apply to them as well
+func (s *Server) registerResources() {
+	for _, def := range s.resourceDefinitions() {
+		if !s.options.Tools.permits(def.tool) {
+			continue
+		}
+
+		def := def
+		handler := func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return s.readResource(ctx, request.Params.URI)
+		}
+		// Resources without variables are listed as such; their template
+		// only routes the URIs that name a cluster
+		if !strings.Contains(def.uri, "{") {
+			s.server.AddResource(mcp.NewResource(def.uri, def.name,
+				mcp.WithResourceDescription(def.description),
+				mcp.WithMIMEType("application/json"),
+			), handler)
+		}
+		template := mcp.NewResourceTemplate(def.uri+"{?cluster}", def.name,
+			mcp.WithTemplateDescription(def.description),
+			mcp.WithTemplateMIMEType("application/json"),
+		)
+		def.template = template.URITemplate
+		s.server.AddResourceTemplate(template, handler)
+		s.resources = append(s.resources, def)
+	}
+}
+
+// resolveResource returns the definition matching uri and the values of its
+// template variables
+func (s *Server) resolveResource(uri string) (resourceDefinition, map[string]string, bool) {
+	for _, def := range s.resources {
+		if !def.template.Regexp().MatchString(uri) {
+			continue
+		}
+		vars := make(map[string]string)
+		for name, value := range def.template.Match(uri) {
+			vars[name] = value.String()
+		}
+		return def, vars, true
+	}
+	return resourceDefinition{}, nil, false
+}
+
+// readResource reads uri on behalf of the client of ctx
+func (s *Server) readResource(ctx context.Context, uri string) ([]mcp.ResourceContents, error) {
+	def, vars, ok := s.resolveResource(uri)
+	if !ok {
+		return nil, fmt.Errorf("unknown resource %s", uri)
+	}
+	if err := s.authorizeResource(ctx, def); err != nil {
+		return nil, err
+	}
+	return s.resourceContents(ctx, def, vars, uri)
+}
+
+// resourceContents reads uri, a resource of def with the given variables,
+// from the cluster the URI names
+func (s *Server) resourceContents(ctx context.Context, def resourceDefinition, vars map[string]string, uri string) ([]mcp.ResourceContents, error) {
+	client, err := s.clusters.Get(vars["cluster"])
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", uri, err)
+	}
+	value, err := def.read(ctx, client, vars)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", uri, err)
+	}
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding %s: %w", uri, err)
+	}
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{URI: uri, MIMEType: "application/json", Text: string(data)},
+	}, nil
+}
+
+// authorizeResource rejects clients that may not call the tool of def
+func (s *Server) authorizeResource(ctx context.Context, def resourceDefinition) error {
+	if !s.mayCall(ctx, def.tool) {
+		s.logger.WithField("resource", def.uri).Warnf("Denied resource read for client %q", AuthIdentity(ctx))
+		return fmt.Errorf("not authorized to read %s", def.uri)
+	}
+	return nil
+}
+
+// resourceDigest fingerprints the contents of a resource of def, ignoring
+// its volatile fields
+func resourceDigest(def resourceDefinition, contents []mcp.ResourceContents) string {
+	hash := sha256.New()
+	for _, content := range contents {
+		text, ok := content.(mcp.TextResourceContents)
+		if !ok {
+			continue
+		}
+		var value interface{}
+		if err := json.Unmarshal([]byte(text.Text), &value); err != nil {
+			hash.Write([]byte(text.Text))
+			continue
+		}
+		data, _ := json.Marshal(withoutFields(value, def.volatile))
+		hash.Write(data)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// withoutFields removes the named object fields from a decoded JSON value
+func withoutFields(value interface{}, fields []string) interface{} {
+	if len(fields) == 0 {
+		return value
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, field := range fields {
+			delete(v, field)
+		}
+		for key, item := range v {
+			v[key] = withoutFields(item, fields)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = withoutFields(item, fields)
+		}
+	}
+	return value
+}
+
+// guestNode looks up the node a guest of the given type (qemu or lxc) runs on
+func guestNode(ctx context.Context, client *proxmox.Client, guestType, id string) (int, string, error) {
+	vmid, err := parseVMID(id)
+	if err != nil {
+		return 0, "", err
+	}
+
+	guest, err := client.ResolveGuest(ctx, proxmox.GuestSelector{VMID: vmid, Type: guestType})
+	if err != nil {
+		return 0, "", err
+	}
+	return guest.VMID, guest.Node, nil
+}
+
+// parseVMID parses a VMID given as text, e.g. in a resource URI
+func parseVMID(id string) (int, error) {
+	vmid, err := strconv.Atoi(strings.TrimSpace(id))
+	if err != nil || vmid <= 0 {
+		return 0, fmt.Errorf("invalid VMID %q", id)
+	}
+	return vmid, nil
+}
@@ -0,0 +1,55 @@
+package mcp
+
+import "github.com/surrealwolf/proxmox-ve-mcp/internal/proxmox"
+
+// Structured results of the main query tools. Their JSON Schema is published
+// as the tool's output schema, so the field names and JSON tags are part of
+// the tool's contract.
+
+type nodesResult struct {
+	Nodes []proxmox.Node `json:"nodes"`
+	Count int            `json:"count"`
+}
+
+type vmsResult struct {
+	VMs   []proxmox.VM `json:"vms"`
+	Count int          `json:"count"`
+	Node  string       `json:"node"`
+}
+
+type vmDisksResult struct {
+	Disks []proxmox.Disk `json:"disks"`
+	Count int            `json:"count"`
+	VMID  int            `json:"vmid"`
+	Node  string         `json:"node"`
+}
+
+type containersResult struct {
+	Containers []proxmox.Container `json:"containers"`
+	Count      int                 `json:"count"`
+	Node       string              `json:"node"`
+}
+
+type storageResult struct {
+	Storage []proxmox.Storage `json:"storage"`
+	Node    string            `json:"node,omitempty"`
+	Count   int               `json:"count"`
+}
+
+type tasksResult struct {
+	Message string         `json:"message"`
+	Node    string         `json:"node,omitempty"`
+	Tasks   []proxmox.Task `json:"tasks"`
+}
+
+type taskStatusResult struct {
+	Message string                 `json:"message"`
+	TaskID  string                 `json:"task_id"`
+	Status  map[string]interface{} `json:"status"`
+}
+
+type backupsResult struct {
+	Backups []proxmox.Backup `json:"backups"`
+	Storage string           `json:"storage"`
+	Count   int              `json:"count"`
+}
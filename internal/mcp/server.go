@@ -1,36 +1,96 @@
 package mcp
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/sirupsen/logrus"
+	"github.com/surrealwolf/proxmox-ve-mcp/internal/audit"
 	"github.com/surrealwolf/proxmox-ve-mcp/internal/proxmox"
 )
 
 // Server represents the MCP server
 type Server struct {
-	proxmoxClient *proxmox.Client
+	clusters      *proxmox.Registry
+	options       Options
+	confirmations *confirmationStore
+	metrics       *toolMetrics
+	rateLimiter   *rateLimiter
+	schemas       map[string]*argsSchema
+	resources     []resourceDefinition
+	subscriptions *resourceSubscriptions
 	server        *server.MCPServer
 	logger        *logrus.Entry
 }
 
+// Options controls which tools the server exposes and how they behave
+type Options struct {
+	// EnableAdvancedTools registers the advanced tools next to the default ones
+	EnableAdvancedTools bool
+	// Tools restricts the registered tools, e.g. to read-only ones
+	Tools ToolFilter
+	// ConfirmDestructive makes destructive tools ask for confirmation, through
+	// elicitation or a confirmation token, before they run
+	ConfirmDestructive bool
+	// ConfirmationTTL is how long a confirmation token stays valid
+	ConfirmationTTL time.Duration
+	// DryRun makes every mutating tool return the requests it would send
+	// instead of sending them
+	DryRun bool
+	// Audit records every tool call; nil disables auditing
+	Audit *audit.Logger
+	// Authorization restricts the tools each HTTP client may call; empty
+	// allows every client every registered tool
+	Authorization []AccessRule
+	// RateLimit is the number of tool calls per minute allowed per client,
+	// with bursts of up to RateLimitBurst calls; 0 disables rate limiting
+	RateLimit      int
+	RateLimitBurst int
+	// ToolTimeout bounds the run time of a tool call; 0 disables it
+	ToolTimeout time.Duration
+	// ResourcePollInterval is how often subscribed resources are checked for
+	// changes; 0 disables resource subscriptions
+	ResourcePollInterval time.Duration
+}
+
 // NewServer creates a new MCP server
-func NewServer(proxmoxClient *proxmox.Client) *Server {
+func NewServer(clusters *proxmox.Registry, options Options) *Server {
 	s := &Server{
-		proxmoxClient: proxmoxClient,
-		server:        server.NewMCPServer("proxmox-ve-mcp", "0.1.0"),
+		clusters:      clusters,
+		options:       options,
+		confirmations: newConfirmationStore(options.ConfirmationTTL),
+		metrics:       newToolMetrics(),
+		schemas:       make(map[string]*argsSchema),
 		logger:        logrus.WithField("component", "MCPServer"),
 	}
 
+	if options.RateLimit > 0 {
+		s.rateLimiter = newRateLimiter(options.RateLimit, options.RateLimitBurst)
+	}
+
+	hooks := &server.Hooks{}
+	if options.ResourcePollInterval > 0 {
+		s.subscriptions = newResourceSubscriptions()
+		hooks.AddOnUnregisterSession(func(_ context.Context, session server.ClientSession) {
+			s.subscriptions.drop(session.SessionID())
+		})
+	}
+	s.server = server.NewMCPServer("proxmox-ve-mcp", "0.1.0",
+		server.WithResourceCapabilities(s.subscriptions != nil, false),
+		server.WithHooks(hooks),
+	)
+
 	s.registerTools()
+	s.registerResources()
+	s.registerPrompts()
 	return s
 }
 
@@ -49,6 +109,71 @@ func getArrayLength(v interface{}) int {
 	return 0
 }
 
+// defaultTaskWaitTimeout is used when a tool is asked to wait without a timeout
+const defaultTaskWaitTimeout = 5 * time.Minute
+
+// awaitTask waits for the task identified by result (a UPID) when the wait
+// option was requested. A task that finishes unsuccessfully is returned as an
+// error carrying its exit status and log tail.
+func (s *Server) awaitTask(ctx context.Context, wait taskWait, result interface{}) (interface{}, error) {
+	upid, ok := result.(string)
+	if !wait.Wait || !ok || !strings.HasPrefix(upid, "UPID:") {
+		return result, nil
+	}
+
+	timeout := defaultTaskWaitTimeout
+	if wait.TimeoutSeconds > 0 {
+		timeout = time.Duration(wait.TimeoutSeconds) * time.Second
+	}
+
+	task, err := s.client(ctx).WaitForTask(ctx, upid, proxmox.TaskWaitOptions{Timeout: timeout})
+	if err != nil {
+		return nil, fmt.Errorf("waiting for task %s: %w", upid, err)
+	}
+	if task.Status == "stopped" && !task.Success {
+		return nil, fmt.Errorf("task %s failed: %s\n%s", upid, task.ExitStatus, strings.Join(task.Log, "\n"))
+	}
+	return task, nil
+}
+
+// toolError builds the error result of a failed tool call. Proxmox API errors
+// are returned as structured content carrying the HTTP status, the reported
+// message, per-parameter errors and their classification; an ambiguous guest
+// name or tag lists the guests it matches.
+func toolError(message string, err error) *mcp.CallToolResult {
+	text := fmt.Sprintf("%s: %v", message, err)
+
+	var ambiguous *proxmox.AmbiguousGuestError
+	if errors.As(err, &ambiguous) {
+		result := mcp.NewToolResultStructured(map[string]interface{}{
+			"error":   message,
+			"message": ambiguous.Error(),
+			"matches": ambiguous.Matches,
+		}, text)
+		result.IsError = true
+		return result
+	}
+
+	var apiErr *proxmox.APIError
+	if !errors.As(err, &apiErr) {
+		return mcp.NewToolResultError(text)
+	}
+
+	result := mcp.NewToolResultStructured(map[string]interface{}{
+		"error":             message,
+		"status":            apiErr.StatusCode,
+		"message":           apiErr.Message,
+		"errors":            apiErr.Errors,
+		"method":            apiErr.Method,
+		"endpoint":          apiErr.Endpoint,
+		"not_found":         proxmox.IsNotFound(err),
+		"permission_denied": proxmox.IsPermissionDenied(err),
+		"retryable":         proxmox.IsRetryable(err),
+	}, text)
+	result.IsError = true
+	return result
+}
+
 // ToolCategory represents the category/priority of a tool
 type ToolCategory string
 
@@ -61,564 +186,235 @@ const (
 type ToolDefinition struct {
 	Name        string
 	Description string
-	Handler     server.ToolHandlerFunc
-	Properties  map[string]any
+	Tool        toolHandler
 	Category    ToolCategory
 }
 
-func (s *Server) registerTools() {
-	// Check environment variables for tool filtering
-	enableAdvanced := os.Getenv("MCP_ENABLE_ADVANCED_TOOLS") == "true"
-	toolMode := strings.ToLower(os.Getenv("MCP_TOOLS_MODE"))
-	
-	// If mode is "all", enable everything
-	if toolMode == "all" {
-		enableAdvanced = true
-	}
-	
-	// If mode is "default", disable advanced
-	if toolMode == "default" {
-		enableAdvanced = false
-	}
-
-	tools := []server.ServerTool{}
+// toolDefinitions returns the definitions of all tools, whether enabled or not
+func (s *Server) toolDefinitions() []ToolDefinition {
 	toolDefs := []ToolDefinition{}
 
 	// Helper to create tool definitions (lazy - not registered yet)
 	// Default category is CategoryDefault unless specified
-	addToolDefault := func(name, desc string, handler server.ToolHandlerFunc, properties map[string]any) {
+	addToolDefault := func(name, desc string, tool toolHandler) {
 		toolDefs = append(toolDefs, ToolDefinition{
 			Name:        name,
 			Description: desc,
-			Handler:     handler,
-			Properties:  properties,
+			Tool:        tool,
 			Category:    CategoryDefault,
 		})
 	}
-	addToolAdvanced := func(name, desc string, handler server.ToolHandlerFunc, properties map[string]any) {
+	addToolAdvanced := func(name, desc string, tool toolHandler) {
 		toolDefs = append(toolDefs, ToolDefinition{
 			Name:        name,
 			Description: desc,
-			Handler:     handler,
-			Properties:  properties,
+			Tool:        tool,
 			Category:    CategoryAdvanced,
 		})
 	}
 	// Alias for backward compatibility - defaults to default category
 	addTool := addToolDefault
 
-	// Helper to register a tool (only if it should be enabled)
-	registerTool := func(def ToolDefinition) {
-		if def.Category == CategoryDefault || enableAdvanced {
-			tools = append(tools, server.ServerTool{
-				Tool: mcp.Tool{
-					Name:        def.Name,
-					Description: def.Description,
-					InputSchema: mcp.ToolInputSchema{
-						Type:       "object",
-						Properties: def.Properties,
-					},
-				},
-				Handler: def.Handler,
-			})
-		}
-	}
-
 	// ============ DEFAULT TOOLS (Common operations) ============
 	// Cluster and Node Management - Default
-	addTool("get_nodes", "Get all nodes in the Proxmox cluster", s.getNodes, map[string]any{})
-	addTool("get_node_status", "Get detailed status information for a specific node", s.getNodeStatus, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Name of the node"},
-	})
-	addTool("get_cluster_resources", "Get all cluster resources (nodes, VMs, containers)", s.getClusterResources, map[string]any{})
-	addTool("get_cluster_status", "Get cluster-wide status information", s.getClusterStatus, map[string]any{})
+	addTool("get_nodes", "Get all nodes in the Proxmox cluster", typed(s.getNodes, mcp.WithOutputSchema[nodesResult]()))
+	addTool("get_node_status", "Get detailed status information for a specific node", typed(s.getNodeStatus, mcp.WithOutputSchema[proxmox.NodeStatus]()))
+	addTool("get_cluster_resources", "Get all cluster resources (nodes, VMs, containers)", typed(s.getClusterResources))
+	addTool("get_cluster_status", "Get cluster-wide status information", typed(s.getClusterStatus))
 
 	// Storage Management
-	addTool("get_storage", "Get all storage devices in the cluster", s.getStorage, map[string]any{})
-	addTool("get_node_storage", "Get storage devices for a specific node", s.getNodeStorage, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Name of the node"},
-	})
+	addTool("get_storage", "Get all storage devices in the cluster", typed(s.getStorage, mcp.WithOutputSchema[storageResult]()))
+	addTool("get_node_storage", "Get storage devices for a specific node", typed(s.getNodeStorage, mcp.WithOutputSchema[storageResult]()))
 
 	// Virtual Machine Management - Query
-	addTool("get_vms", "Get all VMs on a specific node", s.getVMs, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Name of the node"},
-	})
-	addTool("get_vm_status", "Get detailed status of a specific VM", s.getVMStatus, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Name of the node"},
-		"vmid":      map[string]any{"type": "integer", "description": "VM ID"},
-	})
+	addTool("get_vms", "Get all VMs on a specific node", typed(s.getVMs, mcp.WithOutputSchema[vmsResult]()))
+	addTool("get_vm_status", "Get detailed status of a specific VM", typed(s.getVMStatus, mcp.WithOutputSchema[proxmox.VM]()))
 
 	// Virtual Machine Management - Control
-	addTool("start_vm", "Start a virtual machine", s.startVM, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Name of the node"},
-		"vmid":      map[string]any{"type": "integer", "description": "VM ID"},
-	})
-	addTool("stop_vm", "Stop a virtual machine (immediate)", s.stopVM, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Name of the node"},
-		"vmid":      map[string]any{"type": "integer", "description": "VM ID"},
-	})
-	addTool("shutdown_vm", "Gracefully shutdown a virtual machine", s.shutdownVM, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Name of the node"},
-		"vmid":      map[string]any{"type": "integer", "description": "VM ID"},
-	})
-	addTool("reboot_vm", "Reboot a virtual machine", s.rebootVM, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Name of the node"},
-		"vmid":      map[string]any{"type": "integer", "description": "VM ID"},
-	})
-	addTool("get_vm_config", "Get full configuration of a virtual machine", s.getVMConfig, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Name of the node"},
-		"vmid":      map[string]any{"type": "integer", "description": "VM ID"},
-	})
-	addTool("delete_vm", "Delete a virtual machine", s.deleteVM, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Name of the node"},
-		"vmid":      map[string]any{"type": "integer", "description": "VM ID"},
-		"force":     map[string]any{"type": "boolean", "description": "Force delete even if running (optional)"},
-	})
-	addTool("suspend_vm", "Suspend (pause) a virtual machine", s.suspendVM, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Name of the node"},
-		"vmid":      map[string]any{"type": "integer", "description": "VM ID"},
-	})
-	addTool("resume_vm", "Resume a suspended virtual machine", s.resumeVM, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Name of the node"},
-		"vmid":      map[string]any{"type": "integer", "description": "VM ID"},
-	})
-	addTool("create_vm", "Create a new virtual machine", s.createVM, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Name of the node"},
-		"vmid":      map[string]any{"type": "integer", "description": "VM ID (must be unique)"},
-		"name":      map[string]any{"type": "string", "description": "VM name"},
-		"memory":    map[string]any{"type": "integer", "description": "Memory in MB (default: 512)"},
-		"cores":     map[string]any{"type": "integer", "description": "CPU cores (default: 1)"},
-		"sockets":   map[string]any{"type": "integer", "description": "CPU sockets (default: 1)"},
-	})
-	addTool("create_vm_advanced", "Create a VM with advanced configuration options", s.createVMAdvanced, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Name of the node"},
-		"vmid":      map[string]any{"type": "integer", "description": "VM ID (must be unique)"},
-		"name":      map[string]any{"type": "string", "description": "VM name (optional)"},
-		"memory":    map[string]any{"type": "integer", "description": "Memory in MB (optional)"},
-		"cores":     map[string]any{"type": "integer", "description": "CPU cores (optional)"},
-		"sockets":   map[string]any{"type": "integer", "description": "CPU sockets (optional)"},
-		"ide2":      map[string]any{"type": "string", "description": "CD/DVD drive (e.g., /mnt/pve/iso/ubuntu.iso, optional)"},
-		"sata0":     map[string]any{"type": "string", "description": "Primary disk storage (e.g., local-lvm:10, optional)"},
-		"net0":      map[string]any{"type": "string", "description": "Network configuration (e.g., virtio,bridge=vmbr0, optional)"},
-	})
-	addTool("clone_vm", "Clone an existing virtual machine", s.cloneVM, map[string]any{
-		"node_name":   map[string]any{"type": "string", "description": "Name of the node"},
-		"source_vmid": map[string]any{"type": "integer", "description": "Source VM ID to clone from"},
-		"new_vmid":    map[string]any{"type": "integer", "description": "New VM ID (must be unique)"},
-		"new_name":    map[string]any{"type": "string", "description": "New VM name"},
-		"full":        map[string]any{"type": "boolean", "description": "Full clone (default: true) vs linked clone"},
-	})
-	addTool("update_vm_config", "Update virtual machine configuration (e.g., mark as template)", s.updateVMConfig, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Name of the node"},
-		"vmid":      map[string]any{"type": "integer", "description": "VM ID"},
-		"config":    map[string]any{"type": "object", "description": "Configuration to update (e.g., {\"template\": 1} to mark as template)"},
-	})
-	addTool("get_vm_console", "Get console access information for a VM", s.getVMConsole, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Name of the node"},
-		"vmid":      map[string]any{"type": "integer", "description": "VM ID"},
-	})
-	addTool("create_vm_snapshot", "Create a snapshot of a virtual machine", s.createVMSnapshot, map[string]any{
-		"node_name":   map[string]any{"type": "string", "description": "Name of the node"},
-		"vmid":        map[string]any{"type": "integer", "description": "VM ID"},
-		"snap_name":   map[string]any{"type": "string", "description": "Snapshot name"},
-		"description": map[string]any{"type": "string", "description": "Snapshot description (optional)"},
-	})
-	addTool("list_vm_snapshots", "List all snapshots for a virtual machine", s.listVMSnapshots, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Name of the node"},
-		"vmid":      map[string]any{"type": "integer", "description": "VM ID"},
-	})
-	addTool("delete_vm_snapshot", "Delete a snapshot from a virtual machine", s.deleteVMSnapshot, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Name of the node"},
-		"vmid":      map[string]any{"type": "integer", "description": "VM ID"},
-		"snap_name": map[string]any{"type": "string", "description": "Snapshot name"},
-		"force":     map[string]any{"type": "boolean", "description": "Force delete (optional)"},
-	})
-	addTool("restore_vm_snapshot", "Restore a virtual machine from a snapshot", s.restoreVMSnapshot, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Name of the node"},
-		"vmid":      map[string]any{"type": "integer", "description": "VM ID"},
-		"snap_name": map[string]any{"type": "string", "description": "Snapshot name"},
-	})
-	addTool("get_vm_firewall_rules", "Get firewall rules for a virtual machine", s.getVMFirewallRules, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Name of the node"},
-		"vmid":      map[string]any{"type": "integer", "description": "VM ID"},
-	})
-	addTool("migrate_vm", "Migrate a virtual machine to another node", s.migrateVM, map[string]any{
-		"node_name":   map[string]any{"type": "string", "description": "Source node name"},
-		"vmid":        map[string]any{"type": "integer", "description": "VM ID"},
-		"target_node": map[string]any{"type": "string", "description": "Target node name"},
-		"online":      map[string]any{"type": "boolean", "description": "Perform live migration (optional)"},
-	})
+	addTool("start_vm", "Start a virtual machine", typed(s.startVM))
+	addTool("stop_vm", "Stop a virtual machine (immediate)", typed(s.stopVM))
+	addTool("shutdown_vm", "Gracefully shutdown a virtual machine", typed(s.shutdownVM))
+	addTool("reboot_vm", "Reboot a virtual machine", typed(s.rebootVM))
+	addTool("get_vm_config", "Get full configuration of a virtual machine", typed(s.getVMConfig))
+	addTool("delete_vm", "Delete a virtual machine", typed(s.deleteVM))
+	addTool("suspend_vm", "Suspend (pause) a virtual machine", typed(s.suspendVM))
+	addTool("resume_vm", "Resume a suspended virtual machine", typed(s.resumeVM))
+	addTool("create_vm", "Create a new virtual machine", typed(s.createVM))
+	addTool("create_vm_advanced", "Create a VM with advanced configuration options", typed(s.createVMAdvanced))
+	addTool("clone_vm", "Clone an existing virtual machine", typed(s.cloneVM))
+	addTool("update_vm_config", "Update virtual machine configuration (e.g., mark as template)", typed(s.updateVMConfig))
+	addTool("get_vm_console", "Get console access information for a VM", typed(s.getVMConsole))
+	addTool("create_vm_snapshot", "Create a snapshot of a virtual machine", typed(s.createVMSnapshot))
+	addTool("list_vm_snapshots", "List all snapshots for a virtual machine", typed(s.listVMSnapshots))
+	addTool("delete_vm_snapshot", "Delete a snapshot from a virtual machine", typed(s.deleteVMSnapshot))
+	addTool("restore_vm_snapshot", "Restore a virtual machine from a snapshot", typed(s.restoreVMSnapshot))
+	addTool("get_vm_firewall_rules", "Get firewall rules for a virtual machine", typed(s.getVMFirewallRules))
+	addTool("migrate_vm", "Migrate a virtual machine to another node", typed(s.migrateVM))
+
+	// Virtual Machine Management - Cloud-init
+	addTool("get_vm_cloudinit", "Get the cloud-init settings of a VM and the user, network and meta data generated from them", typed(s.getVMCloudInit))
+	addTool("set_vm_cloudinit", "Set cloud-init settings of a VM: user, password, SSH keys, IP configuration, DNS and custom snippets", typed(s.setVMCloudInit))
+	addTool("regenerate_vm_cloudinit", "Regenerate the cloud-init drive of a VM from its current settings", typed(s.regenerateVMCloudInit))
+
+	// Virtual Machine Management - Disks
+	addTool("list_vm_disks", "List the disks of a VM with their storage, volume, size, cache, iothread and discard settings", typed(s.listVMDisks, mcp.WithOutputSchema[vmDisksResult]()))
+	addTool("resize_vm_disk", "Grow a disk of a virtual machine", typed(s.resizeVMDisk))
+	addTool("set_vm_disk_options", "Change the cache, discard, iothread, ssd or backup option of a VM disk, keeping its other settings", typed(s.setVMDiskOptions))
+	addTool("move_vm_disk", "Move a disk of a virtual machine to another storage", typed(s.moveVMDisk))
+	addTool("detach_vm_disk", "Detach disks from a virtual machine, keeping them as unused disks or deleting them", typed(s.detachVMDisk))
+	addTool("import_vm_disk", "Import an existing volume or disk image into a virtual machine as a new disk", typed(s.importVMDisk))
+
+	// Virtual Machine Management - Templates
+	addTool("create_template_from_image", "Build a cloud-init VM template from a cloud image in one call: download, import disk, cloud-init drive, serial console, boot order and template conversion, removing the VM again if a step fails", typed(s.createTemplateFromImage))
 
 	// Container Management - Query (Advanced)
-	addToolAdvanced("get_containers", "Get all containers on a specific node", s.getContainers, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Name of the node"},
-	})
-	addToolAdvanced("get_container_status", "Get detailed status of a specific container", s.getContainerStatus, map[string]any{
-		"node_name":    map[string]any{"type": "string", "description": "Name of the node"},
-		"container_id": map[string]any{"type": "integer", "description": "Container ID"},
-	})
+	addToolAdvanced("get_containers", "Get all containers on a specific node", typed(s.getContainers, mcp.WithOutputSchema[containersResult]()))
+	addToolAdvanced("get_container_status", "Get detailed status of a specific container", typed(s.getContainerStatus, mcp.WithOutputSchema[proxmox.Container]()))
 
 	// Container Management - Control (Advanced)
-	addToolAdvanced("start_container", "Start an LXC container", s.startContainer, map[string]any{
-		"node_name":    map[string]any{"type": "string", "description": "Name of the node"},
-		"container_id": map[string]any{"type": "integer", "description": "Container ID"},
-	})
-	addToolAdvanced("stop_container", "Stop an LXC container (immediate)", s.stopContainer, map[string]any{
-		"node_name":    map[string]any{"type": "string", "description": "Name of the node"},
-		"container_id": map[string]any{"type": "integer", "description": "Container ID"},
-	})
-	addToolAdvanced("shutdown_container", "Gracefully shutdown an LXC container", s.shutdownContainer, map[string]any{
-		"node_name":    map[string]any{"type": "string", "description": "Name of the node"},
-		"container_id": map[string]any{"type": "integer", "description": "Container ID"},
-	})
-	addToolAdvanced("reboot_container", "Reboot an LXC container", s.rebootContainer, map[string]any{
-		"node_name":    map[string]any{"type": "string", "description": "Name of the node"},
-		"container_id": map[string]any{"type": "integer", "description": "Container ID"},
-	})
-	addToolAdvanced("get_container_config", "Get full configuration of a container", s.getContainerConfig, map[string]any{
-		"node_name":    map[string]any{"type": "string", "description": "Name of the node"},
-		"container_id": map[string]any{"type": "integer", "description": "Container ID"},
-	})
-	addToolAdvanced("delete_container", "Delete an LXC container", s.deleteContainer, map[string]any{
-		"node_name":    map[string]any{"type": "string", "description": "Name of the node"},
-		"container_id": map[string]any{"type": "integer", "description": "Container ID"},
-		"force":        map[string]any{"type": "boolean", "description": "Force delete even if running (optional)"},
-	})
-	addToolAdvanced("create_container", "Create a new LXC container", s.createContainer, map[string]any{
-		"node_name":    map[string]any{"type": "string", "description": "Name of the node"},
-		"container_id": map[string]any{"type": "integer", "description": "Container ID (must be unique)"},
-		"hostname":     map[string]any{"type": "string", "description": "Container hostname"},
-		"storage":      map[string]any{"type": "string", "description": "Storage device ID"},
-		"memory":       map[string]any{"type": "integer", "description": "Memory in MB (default: 512)"},
-		"cores":        map[string]any{"type": "integer", "description": "CPU cores (default: 1)"},
-		"ostype":       map[string]any{"type": "string", "description": "OS type (default: debian)"},
-	})
-	addToolAdvanced("create_container_advanced", "Create a container with advanced configuration options", s.createContainerAdvanced, map[string]any{
-		"node_name":    map[string]any{"type": "string", "description": "Name of the node"},
-		"container_id": map[string]any{"type": "integer", "description": "Container ID (must be unique)"},
-		"hostname":     map[string]any{"type": "string", "description": "Container hostname (optional)"},
-		"storage":      map[string]any{"type": "string", "description": "Storage device ID (optional)"},
-		"memory":       map[string]any{"type": "integer", "description": "Memory in MB (optional)"},
-		"cores":        map[string]any{"type": "integer", "description": "CPU cores (optional)"},
-		"ostype":       map[string]any{"type": "string", "description": "OS type (optional)"},
-		"net0":         map[string]any{"type": "string", "description": "Network configuration (e.g., name=eth0,bridge=vmbr0, optional)"},
-		"rootfs":       map[string]any{"type": "string", "description": "Root filesystem (e.g., local-lvm:10, optional)"},
-	})
-	addToolAdvanced("clone_container", "Clone an existing LXC container", s.cloneContainer, map[string]any{
-		"node_name":           map[string]any{"type": "string", "description": "Name of the node"},
-		"source_container_id": map[string]any{"type": "integer", "description": "Source container ID to clone from"},
-		"new_container_id":    map[string]any{"type": "integer", "description": "New container ID (must be unique)"},
-		"new_hostname":        map[string]any{"type": "string", "description": "New container hostname"},
-		"full":                map[string]any{"type": "boolean", "description": "Full clone (default: true) vs linked clone"},
-	})
-	addToolAdvanced("update_container_config", "Update LXC container configuration", s.updateContainerConfig, map[string]any{
-		"node_name":    map[string]any{"type": "string", "description": "Name of the node"},
-		"container_id": map[string]any{"type": "integer", "description": "Container ID"},
-		"config":       map[string]any{"type": "object", "description": "Configuration to update"},
-	})
-	addToolAdvanced("create_container_snapshot", "Create a snapshot of an LXC container", s.createContainerSnapshot, map[string]any{
-		"node_name":    map[string]any{"type": "string", "description": "Name of the node"},
-		"container_id": map[string]any{"type": "integer", "description": "Container ID"},
-		"snap_name":    map[string]any{"type": "string", "description": "Snapshot name"},
-		"description":  map[string]any{"type": "string", "description": "Snapshot description (optional)"},
-	})
-	addToolAdvanced("list_container_snapshots", "List all snapshots for an LXC container", s.listContainerSnapshots, map[string]any{
-		"node_name":    map[string]any{"type": "string", "description": "Name of the node"},
-		"container_id": map[string]any{"type": "integer", "description": "Container ID"},
-	})
-	addToolAdvanced("delete_container_snapshot", "Delete a snapshot from an LXC container", s.deleteContainerSnapshot, map[string]any{
-		"node_name":    map[string]any{"type": "string", "description": "Name of the node"},
-		"container_id": map[string]any{"type": "integer", "description": "Container ID"},
-		"snap_name":    map[string]any{"type": "string", "description": "Snapshot name"},
-		"force":        map[string]any{"type": "boolean", "description": "Force delete (optional)"},
-	})
-	addToolAdvanced("restore_container_snapshot", "Restore an LXC container from a snapshot", s.restoreContainerSnapshot, map[string]any{
-		"node_name":    map[string]any{"type": "string", "description": "Name of the node"},
-		"container_id": map[string]any{"type": "integer", "description": "Container ID"},
-		"snap_name":    map[string]any{"type": "string", "description": "Snapshot name"},
-	})
+	addToolAdvanced("start_container", "Start an LXC container", typed(s.startContainer))
+	addToolAdvanced("stop_container", "Stop an LXC container (immediate)", typed(s.stopContainer))
+	addToolAdvanced("shutdown_container", "Gracefully shutdown an LXC container", typed(s.shutdownContainer))
+	addToolAdvanced("reboot_container", "Reboot an LXC container", typed(s.rebootContainer))
+	addToolAdvanced("get_container_config", "Get full configuration of a container", typed(s.getContainerConfig))
+	addToolAdvanced("delete_container", "Delete an LXC container", typed(s.deleteContainer))
+	addToolAdvanced("create_container", "Create a new LXC container", typed(s.createContainer))
+	addToolAdvanced("create_container_advanced", "Create a container with advanced configuration options", typed(s.createContainerAdvanced))
+	addToolAdvanced("clone_container", "Clone an existing LXC container", typed(s.cloneContainer))
+	addToolAdvanced("update_container_config", "Update LXC container configuration", typed(s.updateContainerConfig))
+	addToolAdvanced("create_container_snapshot", "Create a snapshot of an LXC container", typed(s.createContainerSnapshot))
+	addToolAdvanced("list_container_snapshots", "List all snapshots for an LXC container", typed(s.listContainerSnapshots))
+	addToolAdvanced("delete_container_snapshot", "Delete a snapshot from an LXC container", typed(s.deleteContainerSnapshot))
+	addToolAdvanced("restore_container_snapshot", "Restore an LXC container from a snapshot", typed(s.restoreContainerSnapshot))
 
 	// User Management - Query (Advanced)
-	addToolAdvanced("list_users", "List all users in the system", s.listUsers, map[string]any{})
-	addToolAdvanced("get_user", "Get details for a specific user", s.getUser, map[string]any{
-		"userid": map[string]any{"type": "string", "description": "User ID (e.g., user@pve)"},
-	})
-	addToolAdvanced("list_groups", "List all groups", s.listGroups, map[string]any{})
-	addToolAdvanced("list_roles", "List all available roles and their privileges", s.listRoles, map[string]any{})
-	addToolAdvanced("list_acl", "List all access control list entries", s.listACLs, map[string]any{})
+	addToolAdvanced("list_users", "List all users in the system", typed(s.listUsers))
+	addToolAdvanced("get_user", "Get details for a specific user", typed(s.getUser))
+	addToolAdvanced("list_groups", "List all groups", typed(s.listGroups))
+	addToolAdvanced("list_roles", "List all available roles and their privileges", typed(s.listRoles))
+	addToolAdvanced("list_acl", "List all access control list entries", typed(s.listACLs))
 
 	// User Management - Control (Advanced)
-	addToolAdvanced("create_user", "Create a new user", s.createUser, map[string]any{
-		"userid":   map[string]any{"type": "string", "description": "User ID (e.g., user@pve)"},
-		"password": map[string]any{"type": "string", "description": "Initial password (optional - will attempt to set after creation, may fail with API token for PAM realm, works with session ticket)"},
-		"email":    map[string]any{"type": "string", "description": "Email address (optional)"},
-		"comment":  map[string]any{"type": "string", "description": "Comment (optional)"},
-	})
-	addToolAdvanced("update_user", "Update user properties", s.updateUser, map[string]any{
-		"userid":    map[string]any{"type": "string", "description": "User ID"},
-		"email":     map[string]any{"type": "string", "description": "Email address (optional)"},
-		"comment":   map[string]any{"type": "string", "description": "Comment (optional)"},
-		"firstname": map[string]any{"type": "string", "description": "First name (optional)"},
-		"lastname":  map[string]any{"type": "string", "description": "Last name (optional)"},
-		"enable":    map[string]any{"type": "boolean", "description": "Enable/disable user (optional)"},
-		"expire":    map[string]any{"type": "integer", "description": "Expiration Unix timestamp (optional)"},
-	})
-	addToolAdvanced("delete_user", "Delete a user", s.deleteUser, map[string]any{
-		"userid": map[string]any{"type": "string", "description": "User ID"},
-	})
-	addToolAdvanced("change_password", "Change user password", s.changePassword, map[string]any{
-		"userid":   map[string]any{"type": "string", "description": "User ID"},
-		"password": map[string]any{"type": "string", "description": "New password"},
-	})
-	addToolAdvanced("create_group", "Create a new user group", s.createGroup, map[string]any{
-		"groupid": map[string]any{"type": "string", "description": "Group ID"},
-		"comment": map[string]any{"type": "string", "description": "Comment (optional)"},
-	})
-	addToolAdvanced("delete_group", "Delete a user group", s.deleteGroup, map[string]any{
-		"groupid": map[string]any{"type": "string", "description": "Group ID"},
-	})
-	addToolAdvanced("create_role", "Create a new role with specific privileges", s.createRole, map[string]any{
-		"roleid": map[string]any{"type": "string", "description": "Role ID"},
-		"privs":  map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "List of privileges"},
-	})
-	addToolAdvanced("delete_role", "Delete a role", s.deleteRole, map[string]any{
-		"roleid": map[string]any{"type": "string", "description": "Role ID"},
-	})
-	addToolAdvanced("set_acl", "Create or update an access control list entry", s.setACL, map[string]any{
-		"path":      map[string]any{"type": "string", "description": "ACL path (e.g., /vms, /nodes)"},
-		"role":      map[string]any{"type": "string", "description": "Role ID"},
-		"userid":    map[string]any{"type": "string", "description": "User ID (optional)"},
-		"groupid":   map[string]any{"type": "string", "description": "Group ID (optional)"},
-		"tokenid":   map[string]any{"type": "string", "description": "Token ID (optional)"},
-		"propagate": map[string]any{"type": "boolean", "description": "Propagate permissions down tree (optional)"},
-	})
-	addToolAdvanced("create_api_token", "Create a new API token for a user", s.createAPIToken, map[string]any{
-		"userid":  map[string]any{"type": "string", "description": "User ID"},
-		"tokenid": map[string]any{"type": "string", "description": "Token ID"},
-		"expire":  map[string]any{"type": "integer", "description": "Expiration Unix timestamp (optional)"},
-		"privsep": map[string]any{"type": "boolean", "description": "Separate privileges (optional)"},
-	})
-	addToolAdvanced("delete_api_token", "Delete an API token", s.deleteAPIToken, map[string]any{
-		"userid":  map[string]any{"type": "string", "description": "User ID"},
-		"tokenid": map[string]any{"type": "string", "description": "Token ID"},
-	})
+	addToolAdvanced("create_user", "Create a new user", typed(s.createUser))
+	addToolAdvanced("update_user", "Update user properties", typed(s.updateUser))
+	addToolAdvanced("delete_user", "Delete a user", typed(s.deleteUser))
+	addToolAdvanced("change_password", "Change user password", typed(s.changePassword))
+	addToolAdvanced("create_group", "Create a new user group", typed(s.createGroup))
+	addToolAdvanced("delete_group", "Delete a user group", typed(s.deleteGroup))
+	addToolAdvanced("create_role", "Create a new role with specific privileges", typed(s.createRole))
+	addToolAdvanced("delete_role", "Delete a role", typed(s.deleteRole))
+	addToolAdvanced("set_acl", "Create or update an access control list entry", typed(s.setACL))
+	addToolAdvanced("create_api_token", "Create a new API token for a user", typed(s.createAPIToken))
+	addToolAdvanced("delete_api_token", "Delete an API token", typed(s.deleteAPIToken))
 
 	// Backup & Restore - Query
-	addTool("list_backups", "List available backups in storage", s.listBackups, map[string]any{
-		"storage": map[string]any{"type": "string", "description": "Storage device ID"},
-	})
+	addTool("list_backups", "List available backups in storage", typed(s.listBackups, mcp.WithOutputSchema[backupsResult]()))
 
 	// Backup & Restore - Control
-	addTool("create_vm_backup", "Create a backup of a virtual machine", s.createVMBackup, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Node name"},
-		"vmid":      map[string]any{"type": "integer", "description": "VM ID"},
-		"storage":   map[string]any{"type": "string", "description": "Storage device ID"},
-		"backup_id": map[string]any{"type": "string", "description": "Backup ID (optional)"},
-		"notes":     map[string]any{"type": "string", "description": "Backup notes (optional)"},
-	})
-	addToolAdvanced("create_container_backup", "Create a backup of a container", s.createContainerBackup, map[string]any{
-		"node_name":    map[string]any{"type": "string", "description": "Node name"},
-		"container_id": map[string]any{"type": "integer", "description": "Container ID"},
-		"storage":      map[string]any{"type": "string", "description": "Storage device ID"},
-		"backup_id":    map[string]any{"type": "string", "description": "Backup ID (optional)"},
-		"notes":        map[string]any{"type": "string", "description": "Backup notes (optional)"},
-	})
-	addTool("delete_backup", "Delete a backup file", s.deleteBackup, map[string]any{
-		"storage":   map[string]any{"type": "string", "description": "Storage device ID"},
-		"backup_id": map[string]any{"type": "string", "description": "Backup ID/filename"},
-	})
-	addTool("restore_vm_backup", "Restore a virtual machine from a backup", s.restoreVMBackup, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Node name"},
-		"backup_id": map[string]any{"type": "string", "description": "Backup ID/filename"},
-		"storage":   map[string]any{"type": "string", "description": "Storage device ID"},
-	})
-	addToolAdvanced("restore_container_backup", "Restore a container from a backup", s.restoreContainerBackup, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Node name"},
-		"backup_id": map[string]any{"type": "string", "description": "Backup ID/filename"},
-		"storage":   map[string]any{"type": "string", "description": "Storage device ID"},
-	})
+	addTool("create_vm_backup", "Create a backup of a virtual machine", typed(s.createVMBackup))
+	addToolAdvanced("create_container_backup", "Create a backup of a container", typed(s.createContainerBackup))
+	addTool("delete_backup", "Delete a backup file", typed(s.deleteBackup))
+	addTool("restore_vm_backup", "Restore a virtual machine from a backup", typed(s.restoreVMBackup))
+	addToolAdvanced("restore_container_backup", "Restore a container from a backup", typed(s.restoreContainerBackup))
 
 	// Resource Pools - Query
-	addTool("list_pools", "List all resource pools in the cluster", s.listPools, map[string]any{})
-	addTool("get_pool", "Get details for a specific resource pool", s.getPool, map[string]any{
-		"poolid": map[string]any{"type": "string", "description": "Pool ID"},
-	})
+	addTool("list_pools", "List all resource pools in the cluster", typed(s.listPools))
+	addTool("get_pool", "Get details for a specific resource pool", typed(s.getPool))
 
 	// Node Management
-	addTool("get_node_tasks", "Get tasks for a specific node", s.getNodeTasks, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Node name"},
-	})
-	addTool("get_cluster_tasks", "Get all tasks in the cluster", s.getClusterTasks, map[string]any{})
+	addTool("get_node_tasks", "Get tasks for a specific node", typed(s.getNodeTasks, mcp.WithOutputSchema[tasksResult]()))
+	addTool("get_cluster_tasks", "Get all tasks in the cluster", typed(s.getClusterTasks, mcp.WithOutputSchema[tasksResult]()))
 
 	// Statistics
-	addTool("get_node_stats", "Get performance statistics for a specific node", s.getNodeStats, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Node name"},
-	})
-	addTool("get_vm_stats", "Get performance statistics for a specific VM", s.getVMStats, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Node name"},
-		"vmid":      map[string]any{"type": "integer", "description": "VM ID"},
-	})
-	addToolAdvanced("get_container_stats", "Get performance statistics for a specific container", s.getContainerStats, map[string]any{
-		"node_name":    map[string]any{"type": "string", "description": "Node name"},
-		"container_id": map[string]any{"type": "integer", "description": "Container ID"},
-	})
+	addTool("get_node_stats", "Get performance statistics for a specific node", typed(s.getNodeStats))
+	addTool("get_vm_stats", "Get performance statistics for a specific VM", typed(s.getVMStats))
+	addToolAdvanced("get_container_stats", "Get performance statistics for a specific container", typed(s.getContainerStats))
 
 	// ============ PHASE 4: Storage Management (CRITICAL) ============
-	addTool("get_storage_info", "Get detailed storage device information", s.getStorageInfo, map[string]any{
-		"storage": map[string]any{"type": "string", "description": "Storage device ID"},
-	})
-	addTool("create_storage", "Create a new storage mount", s.createStorage, map[string]any{
-		"storage":      map[string]any{"type": "string", "description": "Storage device ID"},
-		"storage_type": map[string]any{"type": "string", "description": "Storage type (dir, nfs, lvm, iscsi, etc.)"},
-		"content":      map[string]any{"type": "string", "description": "Content types (images, rootdir, backups, etc.)"},
-		"config":       map[string]any{"type": "object", "description": "Type-specific configuration (optional)"},
-	})
-	addTool("delete_storage", "Remove a storage configuration", s.deleteStorage, map[string]any{
-		"storage": map[string]any{"type": "string", "description": "Storage device ID"},
-	})
-	addTool("update_storage", "Modify storage configuration", s.updateStorage, map[string]any{
-		"storage": map[string]any{"type": "string", "description": "Storage device ID"},
-		"config":  map[string]any{"type": "object", "description": "Configuration to update"},
-	})
-	addTool("get_storage_content", "List storage contents (ISOs, backups, templates, etc.)", s.getStorageContent, map[string]any{
-		"storage": map[string]any{"type": "string", "description": "Storage device ID"},
-	})
+	addTool("get_storage_info", "Get detailed storage device information", typed(s.getStorageInfo))
+	addTool("create_storage", "Create a new storage mount", typed(s.createStorage))
+	addTool("delete_storage", "Remove a storage configuration", typed(s.deleteStorage))
+	addTool("update_storage", "Modify storage configuration", typed(s.updateStorage))
+	addTool("get_storage_content", "List storage contents (ISOs, backups, templates, etc.)", typed(s.getStorageContent))
 
 	// ============ PHASE 4: Task Management (HIGH PRIORITY) ============
-	addTool("get_task_status", "Get detailed status and progress of a task", s.getTaskStatus, map[string]any{
-		"task_id": map[string]any{"type": "string", "description": "Task ID (UPID format)"},
-	})
-	addTool("get_task_log", "Get task execution log and output", s.getTaskLog, map[string]any{
-		"task_id": map[string]any{"type": "string", "description": "Task ID (UPID format)"},
-		"start":   map[string]any{"type": "integer", "description": "Start line number (optional)"},
-		"limit":   map[string]any{"type": "integer", "description": "Number of lines to return (optional)"},
-	})
-	addTool("cancel_task", "Cancel a running task", s.cancelTask, map[string]any{
-		"task_id": map[string]any{"type": "string", "description": "Task ID (UPID format)"},
-	})
+	addTool("get_task_status", "Get detailed status and progress of a task", typed(s.getTaskStatus, mcp.WithOutputSchema[taskStatusResult]()))
+	addTool("get_task_log", "Get task execution log and output", typed(s.getTaskLog))
+	addTool("cancel_task", "Cancel a running task", typed(s.cancelTask))
 
 	// ============ PHASE 4: Node Management (HIGH PRIORITY) ============
-	addTool("get_node_config", "Get node network and system configuration", s.getNodeConfig, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Node name"},
-	})
-	addTool("update_node_config", "Modify node settings", s.updateNodeConfig, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Node name"},
-		"config":    map[string]any{"type": "object", "description": "Configuration to update"},
-	})
-	addToolAdvanced("reboot_node", "Reboot a node", s.rebootNode, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Node name"},
-	})
-	addToolAdvanced("shutdown_node", "Gracefully shutdown a node", s.shutdownNode, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Node name"},
-	})
-	addTool("get_node_disks", "List physical disks in a node", s.getNodeDisks, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Node name"},
-	})
-	addTool("get_node_cert", "Get SSL certificate information for a node", s.getNodeCert, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Node name"},
-	})
+	addTool("get_node_config", "Get node network and system configuration", typed(s.getNodeConfig))
+	addTool("update_node_config", "Modify node settings", typed(s.updateNodeConfig))
+	addToolAdvanced("reboot_node", "Reboot a node", typed(s.rebootNode))
+	addToolAdvanced("shutdown_node", "Gracefully shutdown a node", typed(s.shutdownNode))
+	addTool("get_node_disks", "List physical disks in a node", typed(s.getNodeDisks))
+	addTool("get_node_cert", "Get SSL certificate information for a node", typed(s.getNodeCert))
 
 	// ============ POOL MANAGEMENT ============
-	addTool("create_pool", "Create a new resource pool", s.createPool, map[string]any{
-		"poolid":  map[string]any{"type": "string", "description": "Pool ID"},
-		"comment": map[string]any{"type": "string", "description": "Pool comment (optional)"},
-		"members": map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "Pool members array (optional)"},
-	})
-	addTool("update_pool", "Modify an existing resource pool", s.updatePool, map[string]any{
-		"poolid":  map[string]any{"type": "string", "description": "Pool ID"},
-		"comment": map[string]any{"type": "string", "description": "Pool comment (optional)"},
-		"members": map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "Pool members array (optional)"},
-		"delete":  map[string]any{"type": "boolean", "description": "Delete pool members (optional)"},
-	})
-	addTool("delete_pool", "Remove a resource pool", s.deletePool, map[string]any{
-		"poolid": map[string]any{"type": "string", "description": "Pool ID"},
-	})
-	addTool("get_pool_members", "Get all resources in a resource pool", s.getPoolMembers, map[string]any{
-		"poolid": map[string]any{"type": "string", "description": "Pool ID"},
-	})
+	addTool("create_pool", "Create a new resource pool", typed(s.createPool))
+	addTool("update_pool", "Modify an existing resource pool", typed(s.updatePool))
+	addTool("delete_pool", "Remove a resource pool", typed(s.deletePool))
+	addTool("get_pool_members", "Get all resources in a resource pool", typed(s.getPoolMembers))
 
 	// ============ ADDITIONAL TOOLS (Phase 5 - Optional Extensions) ============
-	addTool("get_storage_quota", "Get storage quota and usage information", s.getStorageQuota, map[string]any{
-		"storage": map[string]any{"type": "string", "description": "Storage device ID"},
-	})
-	addTool("upload_backup", "Upload backup file to storage (experimental)", s.uploadBackup, map[string]any{
-		"storage":   map[string]any{"type": "string", "description": "Storage device ID"},
-		"backup_id": map[string]any{"type": "string", "description": "Backup ID/filename"},
-		"file_path": map[string]any{"type": "string", "description": "Local file path to upload"},
-	})
-	addTool("get_node_logs", "Get node system logs", s.getNodeLogs, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Node name"},
-		"lines":     map[string]any{"type": "integer", "description": "Number of log lines to retrieve (optional, default: 50)"},
-	})
-	addTool("get_node_apt_updates", "Get available package updates for a node", s.getNodeAPTUpdates, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Node name"},
-	})
-	addTool("apply_node_updates", "Install available system updates on a node", s.applyNodeUpdates, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Node name"},
-	})
-	addTool("get_node_network", "Get detailed network configuration for a node", s.getNodeNetwork, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Node name"},
-	})
-	addTool("get_node_dns", "Get DNS configuration for a node", s.getNodeDNS, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Node name"},
-	})
+	addTool("get_storage_quota", "Get storage quota and usage information", typed(s.getStorageQuota))
+	addTool("upload_backup", "Upload backup file to storage (experimental)", typed(s.uploadBackup))
+	addTool("get_node_logs", "Get node system logs", typed(s.getNodeLogs))
+	addTool("get_node_apt_updates", "Get available package updates for a node", typed(s.getNodeAPTUpdates))
+	addTool("apply_node_updates", "Install available system updates on a node", typed(s.applyNodeUpdates))
+	addTool("get_node_network", "Get detailed network configuration for a node", typed(s.getNodeNetwork))
+	addTool("get_node_dns", "Get DNS configuration for a node", typed(s.getNodeDNS))
 
 	// ============ HA (HIGH AVAILABILITY) CLUSTER MANAGEMENT ============
-	addTool("get_ha_status", "Get cluster High Availability status", s.getHAStatus, map[string]any{})
-	addTool("enable_ha_resource", "Enable High Availability for a resource", s.enableHAResource, map[string]any{
-		"sid":     map[string]any{"type": "string", "description": "Resource ID (sid format: type:id, e.g., vm:100)"},
-		"comment": map[string]any{"type": "string", "description": "HA resource comment (optional)"},
-		"state":   map[string]any{"type": "string", "description": "Initial state: started or stopped (optional)"},
-	})
-	addTool("disable_ha_resource", "Disable High Availability for a resource", s.disableHAResource, map[string]any{
-		"sid": map[string]any{"type": "string", "description": "Resource ID to disable HA"},
-	})
+	addTool("get_ha_status", "Get cluster High Availability status", typed(s.getHAStatus))
+	addTool("enable_ha_resource", "Enable High Availability for a resource", typed(s.enableHAResource))
+	addTool("disable_ha_resource", "Disable High Availability for a resource", typed(s.disableHAResource))
 
 	// ============ CLUSTER OPERATIONS (Advanced) ============
-	addToolAdvanced("get_cluster_config", "Get cluster configuration", s.getClusterConfig, map[string]any{})
-	addToolAdvanced("get_cluster_nodes_status", "Get status of all nodes in the cluster", s.getClusterNodesStatus, map[string]any{})
-	addToolAdvanced("add_node_to_cluster", "Add a node to the cluster", s.addNodeToCluster, map[string]any{
-		"node_name":       map[string]any{"type": "string", "description": "Node name to add"},
-		"cluster_name":    map[string]any{"type": "string", "description": "Cluster name (optional)"},
-		"cluster_network": map[string]any{"type": "string", "description": "Cluster network address (optional)"},
-	})
-	addToolAdvanced("remove_node_from_cluster", "Remove a node from the cluster", s.removeNodeFromCluster, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Node name to remove"},
-	})
+	addToolAdvanced("get_cluster_config", "Get cluster configuration", typed(s.getClusterConfig))
+	addToolAdvanced("get_cluster_nodes_status", "Get status of all nodes in the cluster", typed(s.getClusterNodesStatus))
+	addToolAdvanced("add_node_to_cluster", "Add a node to the cluster", typed(s.addNodeToCluster))
+	addToolAdvanced("remove_node_from_cluster", "Remove a node from the cluster", typed(s.removeNodeFromCluster))
 
 	// ============ FIREWALL & NETWORK MANAGEMENT ============
-	addTool("get_firewall_rules", "List cluster-wide firewall rules", s.getFirewallRules, map[string]any{})
-	addTool("create_firewall_rule", "Create a new firewall rule", s.createFirewallRule, map[string]any{
-		"direction": map[string]any{"type": "string", "description": "Rule direction: in, out, or group"},
-		"action":    map[string]any{"type": "string", "description": "Action: ACCEPT, DROP, or REJECT"},
-		"source":    map[string]any{"type": "string", "description": "Source address/network (optional)"},
-		"dest":      map[string]any{"type": "string", "description": "Destination address/network (optional)"},
-		"proto":     map[string]any{"type": "string", "description": "Protocol: tcp, udp, esp, gre, etc (optional)"},
-		"sport":     map[string]any{"type": "string", "description": "Source port or port range (optional)"},
-		"dport":     map[string]any{"type": "string", "description": "Destination port or port range (optional)"},
-		"comment":   map[string]any{"type": "string", "description": "Rule comment (optional)"},
-		"enable":    map[string]any{"type": "integer", "description": "Enable rule: 0 or 1 (default: 1)"},
-	})
-	addTool("delete_firewall_rule", "Delete a firewall rule by position", s.deleteFirewallRule, map[string]any{
-		"position": map[string]any{"type": "string", "description": "Rule position/ID to delete"},
-	})
-	addTool("get_security_groups", "List all security groups (firewall groups)", s.getSecurityGroups, map[string]any{})
-	addTool("create_security_group", "Create a new security group", s.createSecurityGroup, map[string]any{
-		"name":    map[string]any{"type": "string", "description": "Security group name"},
-		"comment": map[string]any{"type": "string", "description": "Group comment (optional)"},
-	})
-	addTool("get_network_interfaces", "List network interfaces on a node", s.getNetworkInterfaces, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Node name"},
-	})
-	addTool("get_vlan_config", "Get VLAN configuration for a node", s.getVLANConfig, map[string]any{
-		"node_name": map[string]any{"type": "string", "description": "Node name"},
-	})
+	addTool("get_firewall_rules", "List cluster-wide firewall rules", typed(s.getFirewallRules))
+	addTool("create_firewall_rule", "Create a new firewall rule", typed(s.createFirewallRule))
+	addTool("delete_firewall_rule", "Delete a firewall rule by position", typed(s.deleteFirewallRule))
+	addTool("get_security_groups", "List all security groups (firewall groups)", typed(s.getSecurityGroups))
+	addTool("create_security_group", "Create a new security group", typed(s.createSecurityGroup))
+	addTool("get_network_interfaces", "List network interfaces on a node", typed(s.getNetworkInterfaces))
+	addTool("get_vlan_config", "Get VLAN configuration for a node", typed(s.getVLANConfig))
+
+	return toolDefs
+}
+
+func (s *Server) registerTools() {
+	enableAdvanced := s.options.EnableAdvancedTools
+
+	tools := []server.ServerTool{}
+	toolDefs := s.toolDefinitions()
+
+	// Helper to register a tool (only if it should be enabled)
+	registerTool := func(def ToolDefinition) {
+		if def.Category == CategoryDefault || enableAdvanced {
+			s.schemas[def.Name] = def.Tool.schema
+			tools = append(tools, server.ServerTool{
+				Tool:    s.toolSchema(def),
+				Handler: s.wrapTool(def.Name, def.Tool.handler),
+			})
+		}
+	}
 
 	// Register tools based on category and environment settings
 	defaultCount := 0
 	advancedCount := 0
+	filteredCount := 0
 	for _, def := range toolDefs {
 		if def.Category == CategoryDefault || enableAdvanced {
+			if !s.options.Tools.permits(def.Name) {
+				filteredCount++
+				continue
+			}
 			registerTool(def)
 			if def.Category == CategoryDefault {
 				defaultCount++
@@ -633,192 +429,115 @@ func (s *Server) registerTools() {
 		s.server.AddTool(tool.Tool, tool.Handler)
 	}
 
+	// list_clusters is cluster-independent and does not take the cluster argument
+	if s.options.Tools.permits("list_clusters") {
+		s.server.AddTool(mcp.Tool{
+			Name:        "list_clusters",
+			Description: "List the Proxmox clusters this server can manage; pass a cluster name as the cluster argument of other tools",
+			InputSchema: mcp.ToolInputSchema{Type: "object", Properties: map[string]any{}},
+			Annotations: toolAnnotations("list_clusters"),
+		}, s.wrapTool("list_clusters", s.listClusters))
+	}
+
 	totalRegistered := len(tools)
 	if enableAdvanced {
 		s.logger.Infof("Registered %d tools (%d default + %d advanced)", totalRegistered, defaultCount, advancedCount)
 	} else {
-		s.logger.Infof("Registered %d default tools (advanced tools disabled - set MCP_ENABLE_ADVANCED_TOOLS=true to enable)", totalRegistered)
-		s.logger.Debugf("Skipped %d advanced tools", len(toolDefs)-totalRegistered)
+		s.logger.Infof("Registered %d default tools (advanced tools disabled - set MCP_TOOLS_MODE=all or mcp.tools_mode in the config file to enable)", totalRegistered)
+		s.logger.Debugf("Skipped %d advanced tools", len(toolDefs)-totalRegistered-filteredCount)
+	}
+	if filteredCount > 0 {
+		s.logger.Infof("Excluded %d tools by read-only mode or allow/deny lists", filteredCount)
 	}
 }
 
-// ServeStdio starts the MCP server with stdio transport
+// ServeStdio starts the MCP server with stdio transport and runs it until
+// stdin is closed or ctx is cancelled
 func (s *Server) ServeStdio(ctx context.Context) error {
 	s.logger.Info("Starting Proxmox VE MCP Server")
-	return server.ServeStdio(s.server)
-}
+	go s.watchResources(ctx)
 
-// ServeHTTP starts the MCP server with HTTP transport
-func (s *Server) ServeHTTP(addr string, ctx context.Context) error {
-	s.logger.Infof("Starting Proxmox VE MCP Server on HTTP at %s", addr)
-
-	http.HandleFunc("/mcp", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodPost {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			return
-		}
-
-		// Read the request body
-		body, err := io.ReadAll(r.Body)
-		if err != nil {
-			http.Error(w, "Failed to read request body", http.StatusBadRequest)
-			return
-		}
-		defer r.Body.Close()
-
-		// Parse the MCP request
-		var requestData map[string]interface{}
-		if err := json.Unmarshal(body, &requestData); err != nil {
-			http.Error(w, "Invalid JSON", http.StatusBadRequest)
-			return
-		}
-
-		// Log the request
-		s.logger.Debugf("HTTP MCP request received: %v", requestData)
-
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		response := map[string]string{
-			"status": "MCP HTTP transport is available",
-			"info":   "This is an HTTP endpoint. Use stdio transport for full MCP protocol support.",
-		}
-		json.NewEncoder(w).Encode(response)
-	})
-
-	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(map[string]string{"status": "healthy"})
-	})
-
-	return http.ListenAndServe(addr, nil)
+	stdout := &syncWriter{w: os.Stdout}
+	stdin := &subscriptionReader{s: s, ctx: ctx, in: bufio.NewReader(os.Stdin), out: stdout}
+	return server.NewStdioServer(s.server).Listen(ctx, stdin, stdout)
 }
 
 // getNodes handles the get_nodes tool
-func (s *Server) getNodes(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_nodes")
-
-	nodes, err := s.proxmoxClient.GetNodes(ctx)
+func (s *Server) getNodes(ctx context.Context, request mcp.CallToolRequest, _ noArgs) (*mcp.CallToolResult, error) {
+	nodes, err := s.client(ctx).GetNodes(ctx)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get nodes: %v", err)), nil
+		return toolError("Failed to get nodes", err), nil
 	}
 
-	return mcp.NewToolResultJSON(map[string]interface{}{
-		"nodes": nodes,
-		"count": len(nodes),
+	return mcp.NewToolResultJSON(nodesResult{
+		Nodes: nodes,
+		Count: len(nodes),
 	})
 }
 
 // getNodeStatus handles the get_node_status tool
-func (s *Server) getNodeStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_node_status")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	node, err := s.proxmoxClient.GetNode(ctx, nodeName)
+func (s *Server) getNodeStatus(ctx context.Context, request mcp.CallToolRequest, args nodeArgs) (*mcp.CallToolResult, error) {
+	node, err := s.client(ctx).GetNode(ctx, args.NodeName)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get node status: %v", err)), nil
+		return toolError("Failed to get node status", err), nil
 	}
 
 	return mcp.NewToolResultJSON(node)
 }
 
 // getVMs handles the get_vms tool
-func (s *Server) getVMs(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_vms")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	vms, err := s.proxmoxClient.GetVMs(ctx, nodeName)
+func (s *Server) getVMs(ctx context.Context, request mcp.CallToolRequest, args nodeArgs) (*mcp.CallToolResult, error) {
+	vms, err := s.client(ctx).GetVMs(ctx, args.NodeName)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get VMs: %v", err)), nil
+		return toolError("Failed to get VMs", err), nil
 	}
 
-	return mcp.NewToolResultJSON(map[string]interface{}{
-		"vms":   vms,
-		"count": len(vms),
-		"node":  nodeName,
+	return mcp.NewToolResultJSON(vmsResult{
+		VMs:   vms,
+		Count: len(vms),
+		Node:  args.NodeName,
 	})
 }
 
 // getVMStatus handles the get_vm_status tool
-func (s *Server) getVMStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_vm_status")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	vmID := request.GetInt("vmid", 0)
-	if vmID <= 0 {
-		return mcp.NewToolResultError("vmid parameter is required and must be a positive integer"), nil
-	}
-
-	vm, err := s.proxmoxClient.GetVM(ctx, nodeName, vmID)
+func (s *Server) getVMStatus(ctx context.Context, request mcp.CallToolRequest, args vmArgs) (*mcp.CallToolResult, error) {
+	vm, err := s.client(ctx).GetVM(ctx, args.NodeName, args.VMID)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get VM status: %v", err)), nil
+		return toolError("Failed to get VM status", err), nil
 	}
 
 	return mcp.NewToolResultJSON(vm)
 }
 
 // getContainers handles the get_containers tool
-func (s *Server) getContainers(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_containers")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	containers, err := s.proxmoxClient.GetContainers(ctx, nodeName)
+func (s *Server) getContainers(ctx context.Context, request mcp.CallToolRequest, args nodeArgs) (*mcp.CallToolResult, error) {
+	containers, err := s.client(ctx).GetContainers(ctx, args.NodeName)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get containers: %v", err)), nil
+		return toolError("Failed to get containers", err), nil
 	}
 
-	return mcp.NewToolResultJSON(map[string]interface{}{
-		"containers": containers,
-		"count":      len(containers),
-		"node":       nodeName,
+	return mcp.NewToolResultJSON(containersResult{
+		Containers: containers,
+		Count:      len(containers),
+		Node:       args.NodeName,
 	})
 }
 
 // getContainerStatus handles the get_container_status tool
-func (s *Server) getContainerStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_container_status")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	containerID := request.GetInt("container_id", 0)
-	if containerID <= 0 {
-		return mcp.NewToolResultError("container_id parameter is required and must be a positive integer"), nil
-	}
-
-	container, err := s.proxmoxClient.GetContainer(ctx, nodeName, containerID)
+func (s *Server) getContainerStatus(ctx context.Context, request mcp.CallToolRequest, args containerArgs) (*mcp.CallToolResult, error) {
+	container, err := s.client(ctx).GetContainer(ctx, args.NodeName, args.ContainerID)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get container status: %v", err)), nil
+		return toolError("Failed to get container status", err), nil
 	}
 
 	return mcp.NewToolResultJSON(container)
 }
 
 // getClusterResources handles the get_cluster_resources tool
-func (s *Server) getClusterResources(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_cluster_resources")
-
-	resources, err := s.proxmoxClient.GetClusterResources(ctx)
+func (s *Server) getClusterResources(ctx context.Context, request mcp.CallToolRequest, _ noArgs) (*mcp.CallToolResult, error) {
+	resources, err := s.client(ctx).GetClusterResources(ctx)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get cluster resources: %v", err)), nil
+		return toolError("Failed to get cluster resources", err), nil
 	}
 
 	// Wrap array response in object for MCP compatibility
@@ -829,12 +548,10 @@ func (s *Server) getClusterResources(ctx context.Context, request mcp.CallToolRe
 }
 
 // getClusterStatus handles the get_cluster_status tool
-func (s *Server) getClusterStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_cluster_status")
-
-	status, err := s.proxmoxClient.GetClusterStatus(ctx)
+func (s *Server) getClusterStatus(ctx context.Context, request mcp.CallToolRequest, _ noArgs) (*mcp.CallToolResult, error) {
+	status, err := s.client(ctx).GetClusterStatus(ctx)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get cluster status: %v", err)), nil
+		return toolError("Failed to get cluster status", err), nil
 	}
 
 	// Wrap array response in object for MCP compatibility
@@ -845,1147 +562,716 @@ func (s *Server) getClusterStatus(ctx context.Context, request mcp.CallToolReque
 }
 
 // getStorage handles the get_storage tool
-func (s *Server) getStorage(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_storage")
-
-	storage, err := s.proxmoxClient.GetStorage(ctx)
+func (s *Server) getStorage(ctx context.Context, request mcp.CallToolRequest, _ noArgs) (*mcp.CallToolResult, error) {
+	storage, err := s.client(ctx).GetStorage(ctx)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get storage: %v", err)), nil
+		return toolError("Failed to get storage", err), nil
 	}
 
-	return mcp.NewToolResultJSON(map[string]interface{}{
-		"storage": storage,
-		"count":   len(storage),
+	return mcp.NewToolResultJSON(storageResult{
+		Storage: storage,
+		Count:   len(storage),
 	})
 }
 
 // getNodeStorage handles the get_node_storage tool
-func (s *Server) getNodeStorage(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_node_storage")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	storage, err := s.proxmoxClient.GetNodeStorage(ctx, nodeName)
+func (s *Server) getNodeStorage(ctx context.Context, request mcp.CallToolRequest, args nodeArgs) (*mcp.CallToolResult, error) {
+	storage, err := s.client(ctx).GetNodeStorage(ctx, args.NodeName)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get node storage: %v", err)), nil
+		return toolError("Failed to get node storage", err), nil
 	}
 
-	return mcp.NewToolResultJSON(map[string]interface{}{
-		"storage": storage,
-		"node":    nodeName,
-		"count":   len(storage),
+	return mcp.NewToolResultJSON(storageResult{
+		Storage: storage,
+		Node:    args.NodeName,
+		Count:   len(storage),
 	})
 }
 
 // startVM handles the start_vm tool
-func (s *Server) startVM(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: start_vm")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	vmID := request.GetInt("vmid", 0)
-	if vmID <= 0 {
-		return mcp.NewToolResultError("vmid parameter is required and must be a positive integer"), nil
+func (s *Server) startVM(ctx context.Context, request mcp.CallToolRequest, args vmTaskArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).StartVM(ctx, args.NodeName, args.VMID)
+	if err != nil {
+		return toolError("Failed to start VM", err), nil
 	}
-
-	result, err := s.proxmoxClient.StartVM(ctx, nodeName, vmID)
+	result, err = s.awaitTask(ctx, args.taskWait, result)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to start VM: %v", err)), nil
+		return toolError("Failed to start VM", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action": "start",
-		"vmid":   vmID,
-		"node":   nodeName,
+		"vmid":   args.VMID,
+		"node":   args.NodeName,
 		"result": result,
 	})
 }
 
 // stopVM handles the stop_vm tool
-func (s *Server) stopVM(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: stop_vm")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	vmID := request.GetInt("vmid", 0)
-	if vmID <= 0 {
-		return mcp.NewToolResultError("vmid parameter is required and must be a positive integer"), nil
+func (s *Server) stopVM(ctx context.Context, request mcp.CallToolRequest, args vmTaskArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).StopVM(ctx, args.NodeName, args.VMID)
+	if err != nil {
+		return toolError("Failed to stop VM", err), nil
 	}
-
-	result, err := s.proxmoxClient.StopVM(ctx, nodeName, vmID)
+	result, err = s.awaitTask(ctx, args.taskWait, result)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to stop VM: %v", err)), nil
+		return toolError("Failed to stop VM", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action": "stop",
-		"vmid":   vmID,
-		"node":   nodeName,
+		"vmid":   args.VMID,
+		"node":   args.NodeName,
 		"result": result,
 	})
 }
 
 // shutdownVM handles the shutdown_vm tool
-func (s *Server) shutdownVM(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: shutdown_vm")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	vmID := request.GetInt("vmid", 0)
-	if vmID <= 0 {
-		return mcp.NewToolResultError("vmid parameter is required and must be a positive integer"), nil
+func (s *Server) shutdownVM(ctx context.Context, request mcp.CallToolRequest, args vmTaskArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).ShutdownVM(ctx, args.NodeName, args.VMID)
+	if err != nil {
+		return toolError("Failed to shutdown VM", err), nil
 	}
-
-	result, err := s.proxmoxClient.ShutdownVM(ctx, nodeName, vmID)
+	result, err = s.awaitTask(ctx, args.taskWait, result)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to shutdown VM: %v", err)), nil
+		return toolError("Failed to shutdown VM", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action": "shutdown",
-		"vmid":   vmID,
-		"node":   nodeName,
+		"vmid":   args.VMID,
+		"node":   args.NodeName,
 		"result": result,
 	})
 }
 
 // rebootVM handles the reboot_vm tool
-func (s *Server) rebootVM(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: reboot_vm")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	vmID := request.GetInt("vmid", 0)
-	if vmID <= 0 {
-		return mcp.NewToolResultError("vmid parameter is required and must be a positive integer"), nil
+func (s *Server) rebootVM(ctx context.Context, request mcp.CallToolRequest, args vmTaskArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).RebootVM(ctx, args.NodeName, args.VMID)
+	if err != nil {
+		return toolError("Failed to reboot VM", err), nil
 	}
-
-	result, err := s.proxmoxClient.RebootVM(ctx, nodeName, vmID)
+	result, err = s.awaitTask(ctx, args.taskWait, result)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to reboot VM: %v", err)), nil
+		return toolError("Failed to reboot VM", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action": "reboot",
-		"vmid":   vmID,
-		"node":   nodeName,
+		"vmid":   args.VMID,
+		"node":   args.NodeName,
 		"result": result,
 	})
 }
 
 // getVMConfig handles the get_vm_config tool
-func (s *Server) getVMConfig(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_vm_config")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	vmID := request.GetInt("vmid", 0)
-	if vmID <= 0 {
-		return mcp.NewToolResultError("vmid parameter is required and must be a positive integer"), nil
+func (s *Server) getVMConfig(ctx context.Context, request mcp.CallToolRequest, args getVMConfigArgs) (*mcp.CallToolResult, error) {
+	var config interface{}
+	var err error
+	if args.Parsed {
+		config, err = s.client(ctx).GetParsedVMConfig(ctx, args.NodeName, args.VMID)
+	} else {
+		config, err = s.client(ctx).GetVMConfig(ctx, args.NodeName, args.VMID)
 	}
-
-	config, err := s.proxmoxClient.GetVMConfig(ctx, nodeName, vmID)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get VM config: %v", err)), nil
+		return toolError("Failed to get VM config", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
-		"vmid":   vmID,
-		"node":   nodeName,
+		"vmid":   args.VMID,
+		"node":   args.NodeName,
 		"config": config,
 	})
 }
 
 // deleteVM handles the delete_vm tool
-func (s *Server) deleteVM(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: delete_vm")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	vmID := request.GetInt("vmid", 0)
-	if vmID <= 0 {
-		return mcp.NewToolResultError("vmid parameter is required and must be a positive integer"), nil
+func (s *Server) deleteVM(ctx context.Context, request mcp.CallToolRequest, args deleteVMArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).DeleteVM(ctx, args.NodeName, args.VMID, args.Force)
+	if err != nil {
+		return toolError("Failed to delete VM", err), nil
 	}
-
-	force := request.GetBool("force", false)
-
-	result, err := s.proxmoxClient.DeleteVM(ctx, nodeName, vmID, force)
+	result, err = s.awaitTask(ctx, args.taskWait, result)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to delete VM: %v", err)), nil
+		return toolError("Failed to delete VM", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action": "delete",
-		"vmid":   vmID,
-		"node":   nodeName,
-		"force":  force,
+		"vmid":   args.VMID,
+		"node":   args.NodeName,
+		"force":  args.Force,
 		"result": result,
 	})
 }
 
 // suspendVM handles the suspend_vm tool
-func (s *Server) suspendVM(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: suspend_vm")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	vmID := request.GetInt("vmid", 0)
-	if vmID <= 0 {
-		return mcp.NewToolResultError("vmid parameter is required and must be a positive integer"), nil
+func (s *Server) suspendVM(ctx context.Context, request mcp.CallToolRequest, args vmTaskArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).SuspendVM(ctx, args.NodeName, args.VMID)
+	if err != nil {
+		return toolError("Failed to suspend VM", err), nil
 	}
-
-	result, err := s.proxmoxClient.SuspendVM(ctx, nodeName, vmID)
+	result, err = s.awaitTask(ctx, args.taskWait, result)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to suspend VM: %v", err)), nil
+		return toolError("Failed to suspend VM", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action": "suspend",
-		"vmid":   vmID,
-		"node":   nodeName,
+		"vmid":   args.VMID,
+		"node":   args.NodeName,
 		"result": result,
 	})
 }
 
 // resumeVM handles the resume_vm tool
-func (s *Server) resumeVM(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: resume_vm")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	vmID := request.GetInt("vmid", 0)
-	if vmID <= 0 {
-		return mcp.NewToolResultError("vmid parameter is required and must be a positive integer"), nil
+func (s *Server) resumeVM(ctx context.Context, request mcp.CallToolRequest, args vmTaskArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).ResumeVM(ctx, args.NodeName, args.VMID)
+	if err != nil {
+		return toolError("Failed to resume VM", err), nil
 	}
-
-	result, err := s.proxmoxClient.ResumeVM(ctx, nodeName, vmID)
+	result, err = s.awaitTask(ctx, args.taskWait, result)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to resume VM: %v", err)), nil
+		return toolError("Failed to resume VM", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action": "resume",
-		"vmid":   vmID,
-		"node":   nodeName,
+		"vmid":   args.VMID,
+		"node":   args.NodeName,
 		"result": result,
 	})
 }
 
 // createVM handles the create_vm tool
-func (s *Server) createVM(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: create_vm")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	vmID := request.GetInt("vmid", 0)
-	if vmID <= 0 {
-		return mcp.NewToolResultError("vmid parameter is required and must be a positive integer"), nil
-	}
-
-	name := request.GetString("name", "")
-	if name == "" {
-		return mcp.NewToolResultError("name parameter is required"), nil
+func (s *Server) createVM(ctx context.Context, request mcp.CallToolRequest, args createVMArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).CreateVMFull(ctx, args.NodeName, args.VMID, args.Name, args.Memory, args.Cores, args.Sockets)
+	if err != nil {
+		return toolError("Failed to create VM", err), nil
 	}
-
-	memory := request.GetInt("memory", 512)
-	cores := request.GetInt("cores", 1)
-	sockets := request.GetInt("sockets", 1)
-
-	result, err := s.proxmoxClient.CreateVMFull(ctx, nodeName, vmID, name, memory, cores, sockets)
+	result, err = s.awaitTask(ctx, args.taskWait, result)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to create VM: %v", err)), nil
+		return toolError("Failed to create VM", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action": "create",
-		"vmid":   vmID,
-		"name":   name,
-		"node":   nodeName,
+		"vmid":   args.VMID,
+		"name":   args.Name,
+		"node":   args.NodeName,
 		"config": map[string]interface{}{
-			"memory":  memory,
-			"cores":   cores,
-			"sockets": sockets,
+			"memory":  args.Memory,
+			"cores":   args.Cores,
+			"sockets": args.Sockets,
 		},
 		"result": result,
 	})
 }
 
 // createVMAdvanced handles the create_vm_advanced tool with full configuration
-func (s *Server) createVMAdvanced(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: create_vm_advanced")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	vmID := request.GetInt("vmid", 0)
-	if vmID <= 0 {
-		return mcp.NewToolResultError("vmid parameter is required and must be a positive integer"), nil
-	}
-
+func (s *Server) createVMAdvanced(ctx context.Context, request mcp.CallToolRequest, args createVMAdvancedArgs) (*mcp.CallToolResult, error) {
 	// Build configuration from optional parameters
 	config := map[string]interface{}{
-		"vmid": vmID,
+		"vmid": args.VMID,
 	}
 
 	// Add optional parameters if provided
-	if name := request.GetString("name", ""); name != "" {
-		config["name"] = name
+	if args.Name != "" {
+		config["name"] = args.Name
 	}
-	if memory := request.GetInt("memory", 0); memory > 0 {
-		config["memory"] = memory
+	if args.Memory > 0 {
+		config["memory"] = args.Memory
 	}
-	if cores := request.GetInt("cores", 0); cores > 0 {
-		config["cores"] = cores
+	if args.Cores > 0 {
+		config["cores"] = args.Cores
 	}
-	if sockets := request.GetInt("sockets", 0); sockets > 0 {
-		config["sockets"] = sockets
+	if args.Sockets > 0 {
+		config["sockets"] = args.Sockets
 	}
-	if ide2 := request.GetString("ide2", ""); ide2 != "" {
-		config["ide2"] = ide2
+	if args.IDE2 != "" {
+		config["ide2"] = args.IDE2
 	}
-	if sata0 := request.GetString("sata0", ""); sata0 != "" {
-		config["sata0"] = sata0
+	if args.SATA0 != "" {
+		config["sata0"] = args.SATA0
 	}
-	if net0 := request.GetString("net0", ""); net0 != "" {
-		config["net0"] = net0
+	if args.Net0 != "" {
+		config["net0"] = args.Net0
 	}
 
-	result, err := s.proxmoxClient.CreateVM(ctx, nodeName, config)
+	result, err := s.client(ctx).CreateVM(ctx, args.NodeName, config)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to create VM: %v", err)), nil
+		return toolError("Failed to create VM", err), nil
+	}
+	result, err = s.awaitTask(ctx, args.taskWait, result)
+	if err != nil {
+		return toolError("Failed to create VM", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action": "create",
-		"vmid":   vmID,
-		"node":   nodeName,
+		"vmid":   args.VMID,
+		"node":   args.NodeName,
 		"config": config,
 		"result": result,
 	})
 }
 
 // cloneVM handles the clone_vm tool
-func (s *Server) cloneVM(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: clone_vm")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	sourceVMID := request.GetInt("source_vmid", 0)
-	if sourceVMID <= 0 {
-		return mcp.NewToolResultError("source_vmid parameter is required and must be a positive integer"), nil
-	}
-
-	newVMID := request.GetInt("new_vmid", 0)
-	if newVMID <= 0 {
-		return mcp.NewToolResultError("new_vmid parameter is required and must be a positive integer"), nil
-	}
-
-	newName := request.GetString("new_name", "")
-	if newName == "" {
-		return mcp.NewToolResultError("new_name parameter is required"), nil
+func (s *Server) cloneVM(ctx context.Context, request mcp.CallToolRequest, args cloneVMArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).CloneVM(ctx, args.NodeName, args.SourceVMID, args.NewVMID, args.NewName, args.Full)
+	if err != nil {
+		return toolError("Failed to clone VM", err), nil
 	}
-
-	full := request.GetBool("full", true)
-
-	result, err := s.proxmoxClient.CloneVM(ctx, nodeName, sourceVMID, newVMID, newName, full)
+	result, err = s.awaitTask(ctx, args.taskWait, result)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to clone VM: %v", err)), nil
+		return toolError("Failed to clone VM", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action":      "clone",
-		"source_vmid": sourceVMID,
-		"new_vmid":    newVMID,
-		"new_name":    newName,
-		"node":        nodeName,
-		"full_clone":  full,
+		"source_vmid": args.SourceVMID,
+		"new_vmid":    args.NewVMID,
+		"new_name":    args.NewName,
+		"node":        args.NodeName,
+		"full_clone":  args.Full,
 		"result":      result,
 	})
 }
 
 // updateVMConfig handles the update_vm_config tool
-func (s *Server) updateVMConfig(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: update_vm_config")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	vmID := request.GetInt("vmid", 0)
-	if vmID <= 0 {
-		return mcp.NewToolResultError("vmid parameter is required and must be a positive integer"), nil
-	}
-
-	// Get all arguments to extract config
-	args := request.GetArguments()
-	configValue := args["config"]
-	if configValue == nil {
-		return mcp.NewToolResultError("config parameter is required"), nil
-	}
-
-	// Convert config to map[string]interface{}
-	var config map[string]interface{}
-	configBytes, err := json.Marshal(configValue)
+func (s *Server) updateVMConfig(ctx context.Context, request mcp.CallToolRequest, args vmConfigArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).UpdateVM(ctx, args.NodeName, args.VMID, args.Config)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Invalid config parameter: %v", err)), nil
-	}
-	err = json.Unmarshal(configBytes, &config)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to parse config: %v", err)), nil
-	}
-
-	result, err := s.proxmoxClient.UpdateVM(ctx, nodeName, vmID, config)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to update VM config: %v", err)), nil
+		return toolError("Failed to update VM config", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action": "update_config",
-		"vmid":   vmID,
-		"node":   nodeName,
-		"config": config,
+		"vmid":   args.VMID,
+		"node":   args.NodeName,
+		"config": args.Config,
 		"result": result,
 	})
 }
 
 // getVMConsole handles the get_vm_console tool
-func (s *Server) getVMConsole(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_vm_console")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	vmID := request.GetInt("vmid", 0)
-	if vmID <= 0 {
-		return mcp.NewToolResultError("vmid parameter is required and must be a positive integer"), nil
-	}
-
-	result, err := s.proxmoxClient.GetVMConsole(ctx, nodeName, vmID)
+func (s *Server) getVMConsole(ctx context.Context, request mcp.CallToolRequest, args vmArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).GetVMConsole(ctx, args.NodeName, args.VMID)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get VM console: %v", err)), nil
+		return toolError("Failed to get VM console", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
-		"vmid":    vmID,
-		"node":    nodeName,
+		"vmid":    args.VMID,
+		"node":    args.NodeName,
 		"console": result,
 	})
 }
 
 // createVMSnapshot handles the create_vm_snapshot tool
-func (s *Server) createVMSnapshot(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: create_vm_snapshot")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	vmID := request.GetInt("vmid", 0)
-	if vmID <= 0 {
-		return mcp.NewToolResultError("vmid parameter is required and must be a positive integer"), nil
-	}
-
-	snapName := request.GetString("snap_name", "")
-	if snapName == "" {
-		return mcp.NewToolResultError("snap_name parameter is required"), nil
+func (s *Server) createVMSnapshot(ctx context.Context, request mcp.CallToolRequest, args createVMSnapshotArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).CreateVMSnapshot(ctx, args.NodeName, args.VMID, args.SnapName, args.Description)
+	if err != nil {
+		return toolError("Failed to create VM snapshot", err), nil
 	}
-
-	description := request.GetString("description", "")
-
-	result, err := s.proxmoxClient.CreateVMSnapshot(ctx, nodeName, vmID, snapName, description)
+	result, err = s.awaitTask(ctx, args.taskWait, result)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to create VM snapshot: %v", err)), nil
+		return toolError("Failed to create VM snapshot", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action":      "create_snapshot",
-		"vmid":        vmID,
-		"node":        nodeName,
-		"snapshot":    snapName,
-		"description": description,
+		"vmid":        args.VMID,
+		"node":        args.NodeName,
+		"snapshot":    args.SnapName,
+		"description": args.Description,
 		"result":      result,
 	})
 }
 
 // listVMSnapshots handles the list_vm_snapshots tool
-func (s *Server) listVMSnapshots(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: list_vm_snapshots")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	vmID := request.GetInt("vmid", 0)
-	if vmID <= 0 {
-		return mcp.NewToolResultError("vmid parameter is required and must be a positive integer"), nil
-	}
-
-	result, err := s.proxmoxClient.ListVMSnapshots(ctx, nodeName, vmID)
+func (s *Server) listVMSnapshots(ctx context.Context, request mcp.CallToolRequest, args vmArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).ListVMSnapshots(ctx, args.NodeName, args.VMID)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to list VM snapshots: %v", err)), nil
+		return toolError("Failed to list VM snapshots", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
-		"vmid":      vmID,
-		"node":      nodeName,
+		"vmid":      args.VMID,
+		"node":      args.NodeName,
 		"snapshots": result,
 	})
 }
 
 // deleteVMSnapshot handles the delete_vm_snapshot tool
-func (s *Server) deleteVMSnapshot(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: delete_vm_snapshot")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	vmID := request.GetInt("vmid", 0)
-	if vmID <= 0 {
-		return mcp.NewToolResultError("vmid parameter is required and must be a positive integer"), nil
-	}
-
-	snapName := request.GetString("snap_name", "")
-	if snapName == "" {
-		return mcp.NewToolResultError("snap_name parameter is required"), nil
+func (s *Server) deleteVMSnapshot(ctx context.Context, request mcp.CallToolRequest, args deleteVMSnapshotArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).DeleteVMSnapshot(ctx, args.NodeName, args.VMID, args.SnapName, args.Force)
+	if err != nil {
+		return toolError("Failed to delete VM snapshot", err), nil
 	}
-
-	force := request.GetBool("force", false)
-
-	result, err := s.proxmoxClient.DeleteVMSnapshot(ctx, nodeName, vmID, snapName, force)
+	result, err = s.awaitTask(ctx, args.taskWait, result)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to delete VM snapshot: %v", err)), nil
+		return toolError("Failed to delete VM snapshot", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action":   "delete_snapshot",
-		"vmid":     vmID,
-		"node":     nodeName,
-		"snapshot": snapName,
-		"force":    force,
+		"vmid":     args.VMID,
+		"node":     args.NodeName,
+		"snapshot": args.SnapName,
+		"force":    args.Force,
 		"result":   result,
 	})
 }
 
 // restoreVMSnapshot handles the restore_vm_snapshot tool
-func (s *Server) restoreVMSnapshot(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: restore_vm_snapshot")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	vmID := request.GetInt("vmid", 0)
-	if vmID <= 0 {
-		return mcp.NewToolResultError("vmid parameter is required and must be a positive integer"), nil
-	}
-
-	snapName := request.GetString("snap_name", "")
-	if snapName == "" {
-		return mcp.NewToolResultError("snap_name parameter is required"), nil
+func (s *Server) restoreVMSnapshot(ctx context.Context, request mcp.CallToolRequest, args vmSnapshotArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).RestoreVMSnapshot(ctx, args.NodeName, args.VMID, args.SnapName)
+	if err != nil {
+		return toolError("Failed to restore VM snapshot", err), nil
 	}
-
-	result, err := s.proxmoxClient.RestoreVMSnapshot(ctx, nodeName, vmID, snapName)
+	result, err = s.awaitTask(ctx, args.taskWait, result)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to restore VM snapshot: %v", err)), nil
+		return toolError("Failed to restore VM snapshot", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action":   "restore_snapshot",
-		"vmid":     vmID,
-		"node":     nodeName,
-		"snapshot": snapName,
+		"vmid":     args.VMID,
+		"node":     args.NodeName,
+		"snapshot": args.SnapName,
 		"result":   result,
 	})
 }
 
 // getVMFirewallRules handles the get_vm_firewall_rules tool
-func (s *Server) getVMFirewallRules(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_vm_firewall_rules")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	vmID := request.GetInt("vmid", 0)
-	if vmID <= 0 {
-		return mcp.NewToolResultError("vmid parameter is required and must be a positive integer"), nil
-	}
-
-	result, err := s.proxmoxClient.GetVMFirewallRules(ctx, nodeName, vmID)
+func (s *Server) getVMFirewallRules(ctx context.Context, request mcp.CallToolRequest, args vmArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).GetVMFirewallRules(ctx, args.NodeName, args.VMID)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get VM firewall rules: %v", err)), nil
+		return toolError("Failed to get VM firewall rules", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
-		"vmid":  vmID,
-		"node":  nodeName,
+		"vmid":  args.VMID,
+		"node":  args.NodeName,
 		"rules": result,
 	})
 }
 
 // migrateVM handles the migrate_vm tool
-func (s *Server) migrateVM(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: migrate_vm")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	vmID := request.GetInt("vmid", 0)
-	if vmID <= 0 {
-		return mcp.NewToolResultError("vmid parameter is required and must be a positive integer"), nil
-	}
-
-	targetNode := request.GetString("target_node", "")
-	if targetNode == "" {
-		return mcp.NewToolResultError("target_node parameter is required"), nil
+func (s *Server) migrateVM(ctx context.Context, request mcp.CallToolRequest, args migrateVMArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).MigrateVM(ctx, args.NodeName, args.VMID, args.TargetNode, args.Online)
+	if err != nil {
+		return toolError("Failed to migrate VM", err), nil
 	}
-
-	online := request.GetBool("online", false)
-
-	result, err := s.proxmoxClient.MigrateVM(ctx, nodeName, vmID, targetNode, online)
+	result, err = s.awaitTask(ctx, args.taskWait, result)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to migrate VM: %v", err)), nil
+		return toolError("Failed to migrate VM", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action":      "migrate",
-		"vmid":        vmID,
-		"source_node": nodeName,
-		"target_node": targetNode,
-		"online":      online,
+		"vmid":        args.VMID,
+		"source_node": args.NodeName,
+		"target_node": args.TargetNode,
+		"online":      args.Online,
 		"result":      result,
 	})
 }
 
 // startContainer handles the start_container tool
-func (s *Server) startContainer(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: start_container")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	containerID := request.GetInt("container_id", 0)
-	if containerID <= 0 {
-		return mcp.NewToolResultError("container_id parameter is required and must be a positive integer"), nil
+func (s *Server) startContainer(ctx context.Context, request mcp.CallToolRequest, args containerTaskArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).StartContainer(ctx, args.NodeName, args.ContainerID)
+	if err != nil {
+		return toolError("Failed to start container", err), nil
 	}
-
-	result, err := s.proxmoxClient.StartContainer(ctx, nodeName, containerID)
+	result, err = s.awaitTask(ctx, args.taskWait, result)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to start container: %v", err)), nil
+		return toolError("Failed to start container", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action":       "start",
-		"container_id": containerID,
-		"node":         nodeName,
+		"container_id": args.ContainerID,
+		"node":         args.NodeName,
 		"result":       result,
 	})
 }
 
 // stopContainer handles the stop_container tool
-func (s *Server) stopContainer(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: stop_container")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	containerID := request.GetInt("container_id", 0)
-	if containerID <= 0 {
-		return mcp.NewToolResultError("container_id parameter is required and must be a positive integer"), nil
+func (s *Server) stopContainer(ctx context.Context, request mcp.CallToolRequest, args containerTaskArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).StopContainer(ctx, args.NodeName, args.ContainerID)
+	if err != nil {
+		return toolError("Failed to stop container", err), nil
 	}
-
-	result, err := s.proxmoxClient.StopContainer(ctx, nodeName, containerID)
+	result, err = s.awaitTask(ctx, args.taskWait, result)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to stop container: %v", err)), nil
+		return toolError("Failed to stop container", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action":       "stop",
-		"container_id": containerID,
-		"node":         nodeName,
+		"container_id": args.ContainerID,
+		"node":         args.NodeName,
 		"result":       result,
 	})
 }
 
 // shutdownContainer handles the shutdown_container tool
-func (s *Server) shutdownContainer(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: shutdown_container")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	containerID := request.GetInt("container_id", 0)
-	if containerID <= 0 {
-		return mcp.NewToolResultError("container_id parameter is required and must be a positive integer"), nil
+func (s *Server) shutdownContainer(ctx context.Context, request mcp.CallToolRequest, args containerTaskArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).ShutdownContainer(ctx, args.NodeName, args.ContainerID)
+	if err != nil {
+		return toolError("Failed to shutdown container", err), nil
 	}
-
-	result, err := s.proxmoxClient.ShutdownContainer(ctx, nodeName, containerID)
+	result, err = s.awaitTask(ctx, args.taskWait, result)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to shutdown container: %v", err)), nil
+		return toolError("Failed to shutdown container", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action":       "shutdown",
-		"container_id": containerID,
-		"node":         nodeName,
+		"container_id": args.ContainerID,
+		"node":         args.NodeName,
 		"result":       result,
 	})
 }
 
 // rebootContainer handles the reboot_container tool
-func (s *Server) rebootContainer(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: reboot_container")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	containerID := request.GetInt("container_id", 0)
-	if containerID <= 0 {
-		return mcp.NewToolResultError("container_id parameter is required and must be a positive integer"), nil
+func (s *Server) rebootContainer(ctx context.Context, request mcp.CallToolRequest, args containerTaskArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).RebootContainer(ctx, args.NodeName, args.ContainerID)
+	if err != nil {
+		return toolError("Failed to reboot container", err), nil
 	}
-
-	result, err := s.proxmoxClient.RebootContainer(ctx, nodeName, containerID)
+	result, err = s.awaitTask(ctx, args.taskWait, result)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to reboot container: %v", err)), nil
+		return toolError("Failed to reboot container", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action":       "reboot",
-		"container_id": containerID,
-		"node":         nodeName,
+		"container_id": args.ContainerID,
+		"node":         args.NodeName,
 		"result":       result,
 	})
 }
 
 // getContainerConfig handles the get_container_config tool
-func (s *Server) getContainerConfig(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_container_config")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	containerID := request.GetInt("container_id", 0)
-	if containerID <= 0 {
-		return mcp.NewToolResultError("container_id parameter is required and must be a positive integer"), nil
-	}
-
-	config, err := s.proxmoxClient.GetContainerConfig(ctx, nodeName, containerID)
+func (s *Server) getContainerConfig(ctx context.Context, request mcp.CallToolRequest, args containerArgs) (*mcp.CallToolResult, error) {
+	config, err := s.client(ctx).GetContainerConfig(ctx, args.NodeName, args.ContainerID)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get container config: %v", err)), nil
+		return toolError("Failed to get container config", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
-		"container_id": containerID,
-		"node":         nodeName,
+		"container_id": args.ContainerID,
+		"node":         args.NodeName,
 		"config":       config,
 	})
 }
 
 // deleteContainer handles the delete_container tool
-func (s *Server) deleteContainer(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: delete_container")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	containerID := request.GetInt("container_id", 0)
-	if containerID <= 0 {
-		return mcp.NewToolResultError("container_id parameter is required and must be a positive integer"), nil
+func (s *Server) deleteContainer(ctx context.Context, request mcp.CallToolRequest, args deleteContainerArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).DeleteContainer(ctx, args.NodeName, args.ContainerID, args.Force)
+	if err != nil {
+		return toolError("Failed to delete container", err), nil
 	}
-
-	force := request.GetBool("force", false)
-
-	result, err := s.proxmoxClient.DeleteContainer(ctx, nodeName, containerID, force)
+	result, err = s.awaitTask(ctx, args.taskWait, result)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to delete container: %v", err)), nil
+		return toolError("Failed to delete container", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action":       "delete",
-		"container_id": containerID,
-		"node":         nodeName,
-		"force":        force,
+		"container_id": args.ContainerID,
+		"node":         args.NodeName,
+		"force":        args.Force,
 		"result":       result,
 	})
 }
 
 // createContainer handles the create_container tool
-func (s *Server) createContainer(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: create_container")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	containerID := request.GetInt("container_id", 0)
-	if containerID <= 0 {
-		return mcp.NewToolResultError("container_id parameter is required and must be a positive integer"), nil
-	}
-
-	hostname := request.GetString("hostname", "")
-	if hostname == "" {
-		return mcp.NewToolResultError("hostname parameter is required"), nil
-	}
-
-	storage := request.GetString("storage", "")
-	if storage == "" {
-		return mcp.NewToolResultError("storage parameter is required"), nil
+func (s *Server) createContainer(ctx context.Context, request mcp.CallToolRequest, args createContainerArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).CreateContainerFull(ctx, args.NodeName, args.ContainerID, args.Hostname, args.Storage, args.Memory, args.Cores, args.OSType)
+	if err != nil {
+		return toolError("Failed to create container", err), nil
 	}
-
-	memory := request.GetInt("memory", 512)
-	cores := request.GetInt("cores", 1)
-	ostype := request.GetString("ostype", "debian")
-
-	result, err := s.proxmoxClient.CreateContainerFull(ctx, nodeName, containerID, hostname, storage, memory, cores, ostype)
+	result, err = s.awaitTask(ctx, args.taskWait, result)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to create container: %v", err)), nil
+		return toolError("Failed to create container", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action":       "create",
-		"container_id": containerID,
-		"hostname":     hostname,
-		"node":         nodeName,
+		"container_id": args.ContainerID,
+		"hostname":     args.Hostname,
+		"node":         args.NodeName,
 		"config": map[string]interface{}{
-			"storage": storage,
-			"memory":  memory,
-			"cores":   cores,
-			"ostype":  ostype,
+			"storage": args.Storage,
+			"memory":  args.Memory,
+			"cores":   args.Cores,
+			"ostype":  args.OSType,
 		},
 		"result": result,
 	})
 }
 
 // createContainerAdvanced handles the create_container_advanced tool with full configuration
-func (s *Server) createContainerAdvanced(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: create_container_advanced")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	containerID := request.GetInt("container_id", 0)
-	if containerID <= 0 {
-		return mcp.NewToolResultError("container_id parameter is required and must be a positive integer"), nil
-	}
-
+func (s *Server) createContainerAdvanced(ctx context.Context, request mcp.CallToolRequest, args createContainerAdvancedArgs) (*mcp.CallToolResult, error) {
 	// Build configuration from optional parameters
 	config := map[string]interface{}{
-		"vmid": containerID,
+		"vmid": args.ContainerID,
 	}
 
 	// Add optional parameters if provided
-	if hostname := request.GetString("hostname", ""); hostname != "" {
-		config["hostname"] = hostname
+	if args.Hostname != "" {
+		config["hostname"] = args.Hostname
 	}
-	if storage := request.GetString("storage", ""); storage != "" {
-		config["storage"] = storage
+	if args.Storage != "" {
+		config["storage"] = args.Storage
 	}
-	if memory := request.GetInt("memory", 0); memory > 0 {
-		config["memory"] = memory
+	if args.Memory > 0 {
+		config["memory"] = args.Memory
 	}
-	if cores := request.GetInt("cores", 0); cores > 0 {
-		config["cores"] = cores
+	if args.Cores > 0 {
+		config["cores"] = args.Cores
 	}
-	if ostype := request.GetString("ostype", ""); ostype != "" {
-		config["ostype"] = ostype
+	if args.OSType != "" {
+		config["ostype"] = args.OSType
 	}
-	if net0 := request.GetString("net0", ""); net0 != "" {
-		config["net0"] = net0
+	if args.Net0 != "" {
+		config["net0"] = args.Net0
 	}
-	if rootfs := request.GetString("rootfs", ""); rootfs != "" {
-		config["rootfs"] = rootfs
+	if args.RootFS != "" {
+		config["rootfs"] = args.RootFS
 	}
 
-	result, err := s.proxmoxClient.CreateContainer(ctx, nodeName, config)
+	result, err := s.client(ctx).CreateContainer(ctx, args.NodeName, config)
+	if err != nil {
+		return toolError("Failed to create container", err), nil
+	}
+	result, err = s.awaitTask(ctx, args.taskWait, result)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to create container: %v", err)), nil
+		return toolError("Failed to create container", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action":       "create",
-		"container_id": containerID,
-		"node":         nodeName,
+		"container_id": args.ContainerID,
+		"node":         args.NodeName,
 		"config":       config,
 		"result":       result,
 	})
 }
 
 // cloneContainer handles the clone_container tool
-func (s *Server) cloneContainer(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: clone_container")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	sourceContainerID := request.GetInt("source_container_id", 0)
-	if sourceContainerID <= 0 {
-		return mcp.NewToolResultError("source_container_id parameter is required and must be a positive integer"), nil
-	}
-
-	newContainerID := request.GetInt("new_container_id", 0)
-	if newContainerID <= 0 {
-		return mcp.NewToolResultError("new_container_id parameter is required and must be a positive integer"), nil
-	}
-
-	newHostname := request.GetString("new_hostname", "")
-	if newHostname == "" {
-		return mcp.NewToolResultError("new_hostname parameter is required"), nil
+func (s *Server) cloneContainer(ctx context.Context, request mcp.CallToolRequest, args cloneContainerArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).CloneContainer(ctx, args.NodeName, args.SourceContainerID, args.NewContainerID, args.NewHostname, args.Full)
+	if err != nil {
+		return toolError("Failed to clone container", err), nil
 	}
-
-	full := request.GetBool("full", true)
-
-	result, err := s.proxmoxClient.CloneContainer(ctx, nodeName, sourceContainerID, newContainerID, newHostname, full)
+	result, err = s.awaitTask(ctx, args.taskWait, result)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to clone container: %v", err)), nil
+		return toolError("Failed to clone container", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action":              "clone",
-		"source_container_id": sourceContainerID,
-		"new_container_id":    newContainerID,
-		"new_hostname":        newHostname,
-		"node":                nodeName,
-		"full_clone":          full,
+		"source_container_id": args.SourceContainerID,
+		"new_container_id":    args.NewContainerID,
+		"new_hostname":        args.NewHostname,
+		"node":                args.NodeName,
+		"full_clone":          args.Full,
 		"result":              result,
 	})
 }
 
 // updateContainerConfig handles the update_container_config tool
-func (s *Server) updateContainerConfig(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: update_container_config")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	containerID := request.GetInt("container_id", 0)
-	if containerID <= 0 {
-		return mcp.NewToolResultError("container_id parameter is required and must be a positive integer"), nil
-	}
-
-	// Get all arguments to extract config
-	args := request.GetArguments()
-	configValue := args["config"]
-	if configValue == nil {
-		return mcp.NewToolResultError("config parameter is required"), nil
-	}
-
-	// Convert config to map[string]interface{}
-	var config map[string]interface{}
-	configBytes, err := json.Marshal(configValue)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Invalid config parameter: %v", err)), nil
-	}
-	err = json.Unmarshal(configBytes, &config)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to parse config: %v", err)), nil
-	}
-
-	result, err := s.proxmoxClient.UpdateContainer(ctx, nodeName, containerID, config)
+func (s *Server) updateContainerConfig(ctx context.Context, request mcp.CallToolRequest, args containerConfigArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).UpdateContainer(ctx, args.NodeName, args.ContainerID, args.Config)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to update container config: %v", err)), nil
+		return toolError("Failed to update container config", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action":       "update_config",
-		"container_id": containerID,
-		"node":         nodeName,
-		"config":       config,
+		"container_id": args.ContainerID,
+		"node":         args.NodeName,
+		"config":       args.Config,
 		"result":       result,
 	})
 }
 
 // createContainerSnapshot handles the create_container_snapshot tool
-func (s *Server) createContainerSnapshot(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: create_container_snapshot")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	containerID := request.GetInt("container_id", 0)
-	if containerID <= 0 {
-		return mcp.NewToolResultError("container_id parameter is required and must be a positive integer"), nil
-	}
-
-	snapName := request.GetString("snap_name", "")
-	if snapName == "" {
-		return mcp.NewToolResultError("snap_name parameter is required"), nil
+func (s *Server) createContainerSnapshot(ctx context.Context, request mcp.CallToolRequest, args createContainerSnapshotArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).CreateContainerSnapshot(ctx, args.NodeName, args.ContainerID, args.SnapName, args.Description)
+	if err != nil {
+		return toolError("Failed to create container snapshot", err), nil
 	}
-
-	description := request.GetString("description", "")
-
-	result, err := s.proxmoxClient.CreateContainerSnapshot(ctx, nodeName, containerID, snapName, description)
+	result, err = s.awaitTask(ctx, args.taskWait, result)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to create container snapshot: %v", err)), nil
+		return toolError("Failed to create container snapshot", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action":       "create_snapshot",
-		"container_id": containerID,
-		"node":         nodeName,
-		"snapshot":     snapName,
-		"description":  description,
+		"container_id": args.ContainerID,
+		"node":         args.NodeName,
+		"snapshot":     args.SnapName,
+		"description":  args.Description,
 		"result":       result,
 	})
 }
 
 // listContainerSnapshots handles the list_container_snapshots tool
-func (s *Server) listContainerSnapshots(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: list_container_snapshots")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	containerID := request.GetInt("container_id", 0)
-	if containerID <= 0 {
-		return mcp.NewToolResultError("container_id parameter is required and must be a positive integer"), nil
-	}
-
-	result, err := s.proxmoxClient.ListContainerSnapshots(ctx, nodeName, containerID)
+func (s *Server) listContainerSnapshots(ctx context.Context, request mcp.CallToolRequest, args containerArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).ListContainerSnapshots(ctx, args.NodeName, args.ContainerID)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to list container snapshots: %v", err)), nil
+		return toolError("Failed to list container snapshots", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
-		"container_id": containerID,
-		"node":         nodeName,
+		"container_id": args.ContainerID,
+		"node":         args.NodeName,
 		"snapshots":    result,
 	})
 }
 
 // deleteContainerSnapshot handles the delete_container_snapshot tool
-func (s *Server) deleteContainerSnapshot(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: delete_container_snapshot")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	containerID := request.GetInt("container_id", 0)
-	if containerID <= 0 {
-		return mcp.NewToolResultError("container_id parameter is required and must be a positive integer"), nil
-	}
-
-	snapName := request.GetString("snap_name", "")
-	if snapName == "" {
-		return mcp.NewToolResultError("snap_name parameter is required"), nil
+func (s *Server) deleteContainerSnapshot(ctx context.Context, request mcp.CallToolRequest, args deleteContainerSnapshotArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).DeleteContainerSnapshot(ctx, args.NodeName, args.ContainerID, args.SnapName, args.Force)
+	if err != nil {
+		return toolError("Failed to delete container snapshot", err), nil
 	}
-
-	force := request.GetBool("force", false)
-
-	result, err := s.proxmoxClient.DeleteContainerSnapshot(ctx, nodeName, containerID, snapName, force)
+	result, err = s.awaitTask(ctx, args.taskWait, result)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to delete container snapshot: %v", err)), nil
+		return toolError("Failed to delete container snapshot", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action":       "delete_snapshot",
-		"container_id": containerID,
-		"node":         nodeName,
-		"snapshot":     snapName,
-		"force":        force,
+		"container_id": args.ContainerID,
+		"node":         args.NodeName,
+		"snapshot":     args.SnapName,
+		"force":        args.Force,
 		"result":       result,
 	})
 }
 
 // restoreContainerSnapshot handles the restore_container_snapshot tool
-func (s *Server) restoreContainerSnapshot(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: restore_container_snapshot")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	containerID := request.GetInt("container_id", 0)
-	if containerID <= 0 {
-		return mcp.NewToolResultError("container_id parameter is required and must be a positive integer"), nil
-	}
-
-	snapName := request.GetString("snap_name", "")
-	if snapName == "" {
-		return mcp.NewToolResultError("snap_name parameter is required"), nil
+func (s *Server) restoreContainerSnapshot(ctx context.Context, request mcp.CallToolRequest, args containerSnapshotArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).RestoreContainerSnapshot(ctx, args.NodeName, args.ContainerID, args.SnapName)
+	if err != nil {
+		return toolError("Failed to restore container snapshot", err), nil
 	}
-
-	result, err := s.proxmoxClient.RestoreContainerSnapshot(ctx, nodeName, containerID, snapName)
+	result, err = s.awaitTask(ctx, args.taskWait, result)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to restore container snapshot: %v", err)), nil
+		return toolError("Failed to restore container snapshot", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action":       "restore_snapshot",
-		"container_id": containerID,
-		"node":         nodeName,
-		"snapshot":     snapName,
+		"container_id": args.ContainerID,
+		"node":         args.NodeName,
+		"snapshot":     args.SnapName,
 		"result":       result,
 	})
 }
@@ -1993,12 +1279,10 @@ func (s *Server) restoreContainerSnapshot(ctx context.Context, request mcp.CallT
 // ============ USER MANAGEMENT ============
 
 // listUsers handles the list_users tool
-func (s *Server) listUsers(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: list_users")
-
-	users, err := s.proxmoxClient.ListUsers(ctx)
+func (s *Server) listUsers(ctx context.Context, request mcp.CallToolRequest, _ noArgs) (*mcp.CallToolResult, error) {
+	users, err := s.client(ctx).ListUsers(ctx)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to list users: %v", err)), nil
+		return toolError("Failed to list users", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
@@ -2008,47 +1292,29 @@ func (s *Server) listUsers(ctx context.Context, request mcp.CallToolRequest) (*m
 }
 
 // getUser handles the get_user tool
-func (s *Server) getUser(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_user")
-
-	userID := request.GetString("userid", "")
-	if userID == "" {
-		return mcp.NewToolResultError("userid parameter is required"), nil
-	}
-
-	user, err := s.proxmoxClient.GetUser(ctx, userID)
+func (s *Server) getUser(ctx context.Context, request mcp.CallToolRequest, args userArgs) (*mcp.CallToolResult, error) {
+	user, err := s.client(ctx).GetUser(ctx, args.UserID)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get user: %v", err)), nil
+		return toolError("Failed to get user", err), nil
 	}
 
 	// Ensure userid is set (Proxmox API doesn't always return it in the response)
 	if user.UserID == "" {
-		user.UserID = userID
+		user.UserID = args.UserID
 	}
 
 	return mcp.NewToolResultJSON(user)
 }
 
 // createUser handles the create_user tool
-func (s *Server) createUser(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: create_user")
-
-	userID := request.GetString("userid", "")
-	if userID == "" {
-		return mcp.NewToolResultError("userid parameter is required"), nil
-	}
-
-	password := request.GetString("password", "")
-	email := request.GetString("email", "")
-	comment := request.GetString("comment", "")
-
-	result, err := s.proxmoxClient.CreateUser(ctx, userID, password, email, comment)
+func (s *Server) createUser(ctx context.Context, request mcp.CallToolRequest, args createUserArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).CreateUser(ctx, args.UserID, args.Password, args.Email, args.Comment)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to create user: %v", err)), nil
+		return toolError("Failed to create user", err), nil
 	}
 
 	message := "User created successfully"
-	if password != "" {
+	if args.Password != "" {
 		// Check if password was actually set by checking if there was an error
 		// The client will attempt to set password, but it may fail with API tokens
 		message += " (Password setting attempted - may fail with API token for PAM realm, works with session ticket)"
@@ -2056,97 +1322,62 @@ func (s *Server) createUser(ctx context.Context, request mcp.CallToolRequest) (*
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action":  "create",
-		"userid":  userID,
+		"userid":  args.UserID,
 		"message": message,
 		"result":  result,
 	})
 }
 
 // updateUser handles the update_user tool
-func (s *Server) updateUser(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: update_user")
-
-	userID := request.GetString("userid", "")
-	if userID == "" {
-		return mcp.NewToolResultError("userid parameter is required"), nil
-	}
-
-	email := request.GetString("email", "")
-	comment := request.GetString("comment", "")
-	firstName := request.GetString("firstname", "")
-	lastName := request.GetString("lastname", "")
-	enable := request.GetBool("enable", true)
-	expire := int64(request.GetInt("expire", 0))
-
-	result, err := s.proxmoxClient.UpdateUser(ctx, userID, email, comment, firstName, lastName, enable, expire)
+func (s *Server) updateUser(ctx context.Context, request mcp.CallToolRequest, args updateUserArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).UpdateUser(ctx, args.UserID, args.Email, args.Comment, args.FirstName, args.LastName, args.Enable, args.Expire)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to update user: %v", err)), nil
+		return toolError("Failed to update user", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action":  "update",
-		"userid":  userID,
+		"userid":  args.UserID,
 		"message": "User updated successfully",
 		"result":  result,
 	})
 }
 
 // deleteUser handles the delete_user tool
-func (s *Server) deleteUser(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: delete_user")
-
-	userID := request.GetString("userid", "")
-	if userID == "" {
-		return mcp.NewToolResultError("userid parameter is required"), nil
-	}
-
-	result, err := s.proxmoxClient.DeleteUser(ctx, userID)
+func (s *Server) deleteUser(ctx context.Context, request mcp.CallToolRequest, args userArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).DeleteUser(ctx, args.UserID)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to delete user: %v", err)), nil
+		return toolError("Failed to delete user", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action":  "delete",
-		"userid":  userID,
+		"userid":  args.UserID,
 		"message": "User deleted successfully",
 		"result":  result,
 	})
-}
-
-// changePassword handles the change_password tool
-func (s *Server) changePassword(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: change_password")
-
-	userID := request.GetString("userid", "")
-	if userID == "" {
-		return mcp.NewToolResultError("userid parameter is required"), nil
-	}
-
-	password := request.GetString("password", "")
-	if password == "" {
-		return mcp.NewToolResultError("password parameter is required"), nil
-	}
+}
 
-	result, err := s.proxmoxClient.ChangePassword(ctx, userID, password)
+// changePassword handles the change_password tool
+func (s *Server) changePassword(ctx context.Context, request mcp.CallToolRequest, args changePasswordArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).ChangePassword(ctx, args.UserID, args.Password)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to change password: %v", err)), nil
+		return toolError("Failed to change password", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action":  "change_password",
-		"userid":  userID,
+		"userid":  args.UserID,
 		"message": "Password changed successfully",
 		"result":  result,
 	})
 }
 
 // listGroups handles the list_groups tool
-func (s *Server) listGroups(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: list_groups")
-
-	groups, err := s.proxmoxClient.ListGroups(ctx)
+func (s *Server) listGroups(ctx context.Context, request mcp.CallToolRequest, _ noArgs) (*mcp.CallToolResult, error) {
+	groups, err := s.client(ctx).ListGroups(ctx)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to list groups: %v", err)), nil
+		return toolError("Failed to list groups", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
@@ -2156,58 +1387,40 @@ func (s *Server) listGroups(ctx context.Context, request mcp.CallToolRequest) (*
 }
 
 // createGroup handles the create_group tool
-func (s *Server) createGroup(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: create_group")
-
-	groupID := request.GetString("groupid", "")
-	if groupID == "" {
-		return mcp.NewToolResultError("groupid parameter is required"), nil
-	}
-
-	comment := request.GetString("comment", "")
-
-	result, err := s.proxmoxClient.CreateGroup(ctx, groupID, comment)
+func (s *Server) createGroup(ctx context.Context, request mcp.CallToolRequest, args createGroupArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).CreateGroup(ctx, args.GroupID, args.Comment)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to create group: %v", err)), nil
+		return toolError("Failed to create group", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action":  "create",
-		"groupid": groupID,
+		"groupid": args.GroupID,
 		"message": "Group created successfully",
 		"result":  result,
 	})
 }
 
 // deleteGroup handles the delete_group tool
-func (s *Server) deleteGroup(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: delete_group")
-
-	groupID := request.GetString("groupid", "")
-	if groupID == "" {
-		return mcp.NewToolResultError("groupid parameter is required"), nil
-	}
-
-	result, err := s.proxmoxClient.DeleteGroup(ctx, groupID)
+func (s *Server) deleteGroup(ctx context.Context, request mcp.CallToolRequest, args groupArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).DeleteGroup(ctx, args.GroupID)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to delete group: %v", err)), nil
+		return toolError("Failed to delete group", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action":  "delete",
-		"groupid": groupID,
+		"groupid": args.GroupID,
 		"message": "Group deleted successfully",
 		"result":  result,
 	})
 }
 
 // listRoles handles the list_roles tool
-func (s *Server) listRoles(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: list_roles")
-
-	roles, err := s.proxmoxClient.ListRoles(ctx)
+func (s *Server) listRoles(ctx context.Context, request mcp.CallToolRequest, _ noArgs) (*mcp.CallToolResult, error) {
+	roles, err := s.client(ctx).ListRoles(ctx)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to list roles: %v", err)), nil
+		return toolError("Failed to list roles", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
@@ -2217,90 +1430,40 @@ func (s *Server) listRoles(ctx context.Context, request mcp.CallToolRequest) (*m
 }
 
 // createRole handles the create_role tool
-func (s *Server) createRole(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: create_role")
-
-	roleID := request.GetString("roleid", "")
-	if roleID == "" {
-		return mcp.NewToolResultError("roleid parameter is required"), nil
-	}
-
-	// Parse privileges - for simplicity, accept a space-separated string or array
-	privs := []string{}
-
-	// Try to get as string first (space-separated)
-	if privStr := request.GetString("privs", ""); privStr != "" {
-		// If it's a string, it might be space-separated
-		privsList := splitPrivileges(privStr)
-		privs = privsList
-	}
-
-	result, err := s.proxmoxClient.CreateRole(ctx, roleID, privs)
+func (s *Server) createRole(ctx context.Context, request mcp.CallToolRequest, args createRoleArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).CreateRole(ctx, args.RoleID, args.Privs)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to create role: %v", err)), nil
+		return toolError("Failed to create role", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action":  "create",
-		"roleid":  roleID,
+		"roleid":  args.RoleID,
 		"message": "Role created successfully",
 		"result":  result,
 	})
 }
 
-// Helper function to split privileges string
-func splitPrivileges(privStr string) []string {
-	if privStr == "" {
-		return []string{}
-	}
-	// For now, assume space-separated or comma-separated
-	parts := make([]string, 0)
-	current := ""
-	for _, ch := range privStr {
-		if ch == ' ' || ch == ',' {
-			if current != "" {
-				parts = append(parts, current)
-				current = ""
-			}
-		} else {
-			current += string(ch)
-		}
-	}
-	if current != "" {
-		parts = append(parts, current)
-	}
-	return parts
-}
-
 // deleteRole handles the delete_role tool
-func (s *Server) deleteRole(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: delete_role")
-
-	roleID := request.GetString("roleid", "")
-	if roleID == "" {
-		return mcp.NewToolResultError("roleid parameter is required"), nil
-	}
-
-	result, err := s.proxmoxClient.DeleteRole(ctx, roleID)
+func (s *Server) deleteRole(ctx context.Context, request mcp.CallToolRequest, args roleArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).DeleteRole(ctx, args.RoleID)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to delete role: %v", err)), nil
+		return toolError("Failed to delete role", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action":  "delete",
-		"roleid":  roleID,
+		"roleid":  args.RoleID,
 		"message": "Role deleted successfully",
 		"result":  result,
 	})
 }
 
 // listACLs handles the list_acl tool
-func (s *Server) listACLs(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: list_acl")
-
-	acls, err := s.proxmoxClient.ListACLs(ctx)
+func (s *Server) listACLs(ctx context.Context, request mcp.CallToolRequest, _ noArgs) (*mcp.CallToolResult, error) {
+	acls, err := s.client(ctx).ListACLs(ctx)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to list ACLs: %v", err)), nil
+		return toolError("Failed to list ACLs", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
@@ -2310,97 +1473,53 @@ func (s *Server) listACLs(ctx context.Context, request mcp.CallToolRequest) (*mc
 }
 
 // setACL handles the set_acl tool
-func (s *Server) setACL(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: set_acl")
-
-	path := request.GetString("path", "")
-	if path == "" {
-		return mcp.NewToolResultError("path parameter is required"), nil
-	}
-
-	role := request.GetString("role", "")
-	if role == "" {
-		return mcp.NewToolResultError("role parameter is required"), nil
-	}
-
-	userID := request.GetString("userid", "")
-	groupID := request.GetString("groupid", "")
-	tokenID := request.GetString("tokenid", "")
-	propagate := request.GetBool("propagate", true)
-
-	if userID == "" && groupID == "" && tokenID == "" {
+func (s *Server) setACL(ctx context.Context, request mcp.CallToolRequest, args setACLArgs) (*mcp.CallToolResult, error) {
+	if args.UserID == "" && args.GroupID == "" && args.TokenID == "" {
 		return mcp.NewToolResultError("At least one of userid, groupid, or tokenid is required"), nil
 	}
 
-	result, err := s.proxmoxClient.SetACL(ctx, path, role, userID, groupID, tokenID, propagate)
+	result, err := s.client(ctx).SetACL(ctx, args.Path, args.Role, args.UserID, args.GroupID, args.TokenID, args.Propagate)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to set ACL: %v", err)), nil
+		return toolError("Failed to set ACL", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action":    "set_acl",
-		"path":      path,
-		"role":      role,
-		"propagate": propagate,
+		"path":      args.Path,
+		"role":      args.Role,
+		"propagate": args.Propagate,
 		"message":   "ACL set successfully",
 		"result":    result,
 	})
 }
 
 // createAPIToken handles the create_api_token tool
-func (s *Server) createAPIToken(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: create_api_token")
-
-	userID := request.GetString("userid", "")
-	if userID == "" {
-		return mcp.NewToolResultError("userid parameter is required"), nil
-	}
-
-	tokenID := request.GetString("tokenid", "")
-	if tokenID == "" {
-		return mcp.NewToolResultError("tokenid parameter is required"), nil
-	}
-
-	expire := int64(request.GetInt("expire", 0))
-	privSep := request.GetBool("privsep", false)
-
-	result, err := s.proxmoxClient.CreateAPIToken(ctx, userID, tokenID, expire, privSep)
+func (s *Server) createAPIToken(ctx context.Context, request mcp.CallToolRequest, args createAPITokenArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).CreateAPIToken(ctx, args.UserID, args.TokenID, args.Expire, args.PrivSep)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to create API token: %v", err)), nil
+		return toolError("Failed to create API token", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action":  "create",
-		"userid":  userID,
-		"tokenid": tokenID,
+		"userid":  args.UserID,
+		"tokenid": args.TokenID,
 		"message": "API token created successfully",
 		"result":  result,
 	})
 }
 
 // deleteAPIToken handles the delete_api_token tool
-func (s *Server) deleteAPIToken(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: delete_api_token")
-
-	userID := request.GetString("userid", "")
-	if userID == "" {
-		return mcp.NewToolResultError("userid parameter is required"), nil
-	}
-
-	tokenID := request.GetString("tokenid", "")
-	if tokenID == "" {
-		return mcp.NewToolResultError("tokenid parameter is required"), nil
-	}
-
-	result, err := s.proxmoxClient.DeleteAPIToken(ctx, userID, tokenID)
+func (s *Server) deleteAPIToken(ctx context.Context, request mcp.CallToolRequest, args apiTokenArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).DeleteAPIToken(ctx, args.UserID, args.TokenID)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to delete API token: %v", err)), nil
+		return toolError("Failed to delete API token", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action":  "delete",
-		"userid":  userID,
-		"tokenid": tokenID,
+		"userid":  args.UserID,
+		"tokenid": args.TokenID,
 		"message": "API token deleted successfully",
 		"result":  result,
 	})
@@ -2409,191 +1528,118 @@ func (s *Server) deleteAPIToken(ctx context.Context, request mcp.CallToolRequest
 // ============ BACKUP & RESTORE ============
 
 // listBackups handles the list_backups tool
-func (s *Server) listBackups(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: list_backups")
-
-	storage := request.GetString("storage", "")
-	if storage == "" {
-		return mcp.NewToolResultError("storage parameter is required"), nil
-	}
-
-	backups, err := s.proxmoxClient.ListBackups(ctx, storage)
+func (s *Server) listBackups(ctx context.Context, request mcp.CallToolRequest, args storageArgs) (*mcp.CallToolResult, error) {
+	backups, err := s.client(ctx).ListBackups(ctx, args.Storage)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to list backups: %v", err)), nil
+		return toolError("Failed to list backups", err), nil
 	}
 
-	return mcp.NewToolResultJSON(map[string]interface{}{
-		"backups": backups,
-		"storage": storage,
-		"count":   len(backups),
+	return mcp.NewToolResultJSON(backupsResult{
+		Backups: backups,
+		Storage: args.Storage,
+		Count:   len(backups),
 	})
 }
 
 // createVMBackup handles the create_vm_backup tool
-func (s *Server) createVMBackup(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: create_vm_backup")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	vmID := request.GetInt("vmid", 0)
-	if vmID <= 0 {
-		return mcp.NewToolResultError("vmid parameter is required and must be a positive integer"), nil
-	}
-
-	storage := request.GetString("storage", "")
-	if storage == "" {
-		return mcp.NewToolResultError("storage parameter is required"), nil
+func (s *Server) createVMBackup(ctx context.Context, request mcp.CallToolRequest, args createVMBackupArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).CreateVMBackup(ctx, args.NodeName, args.VMID, args.Storage, args.BackupID, args.Notes)
+	if err != nil {
+		return toolError("Failed to create VM backup", err), nil
 	}
-
-	backupID := request.GetString("backup_id", "")
-	notes := request.GetString("notes", "")
-
-	result, err := s.proxmoxClient.CreateVMBackup(ctx, nodeName, vmID, storage, backupID, notes)
+	result, err = s.awaitTask(ctx, args.taskWait, result)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to create VM backup: %v", err)), nil
+		return toolError("Failed to create VM backup", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action":  "backup",
-		"vmid":    vmID,
-		"node":    nodeName,
-		"storage": storage,
+		"vmid":    args.VMID,
+		"node":    args.NodeName,
+		"storage": args.Storage,
 		"message": "VM backup started",
 		"result":  result,
 	})
 }
 
 // createContainerBackup handles the create_container_backup tool
-func (s *Server) createContainerBackup(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: create_container_backup")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	containerID := request.GetInt("container_id", 0)
-	if containerID <= 0 {
-		return mcp.NewToolResultError("container_id parameter is required and must be a positive integer"), nil
-	}
-
-	storage := request.GetString("storage", "")
-	if storage == "" {
-		return mcp.NewToolResultError("storage parameter is required"), nil
+func (s *Server) createContainerBackup(ctx context.Context, request mcp.CallToolRequest, args createContainerBackupArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).CreateContainerBackup(ctx, args.NodeName, args.ContainerID, args.Storage, args.BackupID, args.Notes)
+	if err != nil {
+		return toolError("Failed to create container backup", err), nil
 	}
-
-	backupID := request.GetString("backup_id", "")
-	notes := request.GetString("notes", "")
-
-	result, err := s.proxmoxClient.CreateContainerBackup(ctx, nodeName, containerID, storage, backupID, notes)
+	result, err = s.awaitTask(ctx, args.taskWait, result)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to create container backup: %v", err)), nil
+		return toolError("Failed to create container backup", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action":       "backup",
-		"container_id": containerID,
-		"node":         nodeName,
-		"storage":      storage,
+		"container_id": args.ContainerID,
+		"node":         args.NodeName,
+		"storage":      args.Storage,
 		"message":      "Container backup started",
 		"result":       result,
 	})
 }
 
 // deleteBackup handles the delete_backup tool
-func (s *Server) deleteBackup(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: delete_backup")
-
-	storage := request.GetString("storage", "")
-	if storage == "" {
-		return mcp.NewToolResultError("storage parameter is required"), nil
-	}
-
-	backupID := request.GetString("backup_id", "")
-	if backupID == "" {
-		return mcp.NewToolResultError("backup_id parameter is required"), nil
+func (s *Server) deleteBackup(ctx context.Context, request mcp.CallToolRequest, args backupArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).DeleteBackup(ctx, args.Storage, args.BackupID)
+	if err != nil {
+		return toolError("Failed to delete backup", err), nil
 	}
-
-	result, err := s.proxmoxClient.DeleteBackup(ctx, storage, backupID)
+	result, err = s.awaitTask(ctx, args.taskWait, result)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to delete backup: %v", err)), nil
+		return toolError("Failed to delete backup", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action":    "delete",
-		"storage":   storage,
-		"backup_id": backupID,
+		"storage":   args.Storage,
+		"backup_id": args.BackupID,
 		"message":   "Backup deleted successfully",
 		"result":    result,
 	})
 }
 
 // restoreVMBackup handles the restore_vm_backup tool
-func (s *Server) restoreVMBackup(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: restore_vm_backup")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	backupID := request.GetString("backup_id", "")
-	if backupID == "" {
-		return mcp.NewToolResultError("backup_id parameter is required"), nil
-	}
-
-	storage := request.GetString("storage", "")
-	if storage == "" {
-		return mcp.NewToolResultError("storage parameter is required"), nil
+func (s *Server) restoreVMBackup(ctx context.Context, request mcp.CallToolRequest, args restoreBackupArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).RestoreVMBackup(ctx, args.NodeName, args.BackupID, args.Storage)
+	if err != nil {
+		return toolError("Failed to restore VM backup", err), nil
 	}
-
-	result, err := s.proxmoxClient.RestoreVMBackup(ctx, nodeName, backupID, storage)
+	result, err = s.awaitTask(ctx, args.taskWait, result)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to restore VM backup: %v", err)), nil
+		return toolError("Failed to restore VM backup", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action":    "restore",
-		"node":      nodeName,
-		"backup_id": backupID,
-		"storage":   storage,
+		"node":      args.NodeName,
+		"backup_id": args.BackupID,
+		"storage":   args.Storage,
 		"message":   "VM restore started",
 		"result":    result,
 	})
 }
 
 // restoreContainerBackup handles the restore_container_backup tool
-func (s *Server) restoreContainerBackup(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: restore_container_backup")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	backupID := request.GetString("backup_id", "")
-	if backupID == "" {
-		return mcp.NewToolResultError("backup_id parameter is required"), nil
-	}
-
-	storage := request.GetString("storage", "")
-	if storage == "" {
-		return mcp.NewToolResultError("storage parameter is required"), nil
+func (s *Server) restoreContainerBackup(ctx context.Context, request mcp.CallToolRequest, args restoreBackupArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).RestoreContainerBackup(ctx, args.NodeName, args.BackupID, args.Storage)
+	if err != nil {
+		return toolError("Failed to restore container backup", err), nil
 	}
-
-	result, err := s.proxmoxClient.RestoreContainerBackup(ctx, nodeName, backupID, storage)
+	result, err = s.awaitTask(ctx, args.taskWait, result)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to restore container backup: %v", err)), nil
+		return toolError("Failed to restore container backup", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"action":    "restore",
-		"node":      nodeName,
-		"backup_id": backupID,
-		"storage":   storage,
+		"node":      args.NodeName,
+		"backup_id": args.BackupID,
+		"storage":   args.Storage,
 		"message":   "Container restore started",
 		"result":    result,
 	})
@@ -2601,12 +1647,10 @@ func (s *Server) restoreContainerBackup(ctx context.Context, request mcp.CallToo
 
 // ============ RESOURCE POOLS ============
 
-func (s *Server) listPools(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: list_pools")
-
-	pools, err := s.proxmoxClient.ListPools(ctx)
+func (s *Server) listPools(ctx context.Context, request mcp.CallToolRequest, _ noArgs) (*mcp.CallToolResult, error) {
+	pools, err := s.client(ctx).ListPools(ctx)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to list pools: %v", err)), nil
+		return toolError("Failed to list pools", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
@@ -2615,17 +1659,10 @@ func (s *Server) listPools(ctx context.Context, request mcp.CallToolRequest) (*m
 	})
 }
 
-func (s *Server) getPool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_pool")
-
-	poolID := request.GetString("poolid", "")
-	if poolID == "" {
-		return mcp.NewToolResultError("poolid parameter is required"), nil
-	}
-
-	pool, err := s.proxmoxClient.GetPool(ctx, poolID)
+func (s *Server) getPool(ctx context.Context, request mcp.CallToolRequest, args poolArgs) (*mcp.CallToolResult, error) {
+	pool, err := s.client(ctx).GetPool(ctx, args.PoolID)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get pool: %v", err)), nil
+		return toolError("Failed to get pool", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
@@ -2636,732 +1673,435 @@ func (s *Server) getPool(ctx context.Context, request mcp.CallToolRequest) (*mcp
 
 // ============ NODE MANAGEMENT - TASKS ============
 
-func (s *Server) getNodeTasks(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_node_tasks")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
+func (s *Server) getNodeTasks(ctx context.Context, request mcp.CallToolRequest, args nodeTasksArgs) (*mcp.CallToolResult, error) {
+	filter := proxmox.TaskFilter{
+		TypeFilter: args.TypeFilter,
+		VMID:       args.VMID,
+		Errors:     args.Errors,
+		Source:     args.Source,
+		Limit:      args.Limit,
+	}
+	if args.Since > 0 {
+		filter.Since = time.Unix(args.Since, 0)
+	}
+	if args.Until > 0 {
+		filter.Until = time.Unix(args.Until, 0)
 	}
 
-	tasks, err := s.proxmoxClient.GetNodeTasks(ctx, nodeName)
+	tasks, err := s.client(ctx).GetNodeTasks(ctx, args.NodeName, filter)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get node tasks: %v", err)), nil
+		return toolError("Failed to get node tasks", err), nil
 	}
 
-	return mcp.NewToolResultJSON(map[string]interface{}{
-		"message": "Node tasks retrieved successfully",
-		"node":    nodeName,
-		"tasks":   tasks,
+	return mcp.NewToolResultJSON(tasksResult{
+		Message: "Node tasks retrieved successfully",
+		Node:    args.NodeName,
+		Tasks:   tasks,
 	})
 }
 
-func (s *Server) getClusterTasks(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_cluster_tasks")
-
-	tasks, err := s.proxmoxClient.GetClusterTasks(ctx)
+func (s *Server) getClusterTasks(ctx context.Context, request mcp.CallToolRequest, _ noArgs) (*mcp.CallToolResult, error) {
+	tasks, err := s.client(ctx).GetClusterTasks(ctx)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get cluster tasks: %v", err)), nil
+		return toolError("Failed to get cluster tasks", err), nil
 	}
 
-	return mcp.NewToolResultJSON(map[string]interface{}{
-		"message": "Cluster tasks retrieved successfully",
-		"tasks":   tasks,
+	return mcp.NewToolResultJSON(tasksResult{
+		Message: "Cluster tasks retrieved successfully",
+		Tasks:   tasks,
 	})
 }
 
 // ============ STATISTICS ============
 
-func (s *Server) getNodeStats(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_node_stats")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	stats, err := s.proxmoxClient.GetNodeStats(ctx, nodeName, "day")
+func (s *Server) getNodeStats(ctx context.Context, request mcp.CallToolRequest, args nodeArgs) (*mcp.CallToolResult, error) {
+	stats, err := s.client(ctx).GetNodeStats(ctx, args.NodeName, "day")
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get node statistics: %v", err)), nil
+		return toolError("Failed to get node statistics", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"message": "Node statistics retrieved successfully",
-		"node":    nodeName,
+		"node":    args.NodeName,
 		"stats":   stats,
 	})
 }
 
-func (s *Server) getVMStats(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_vm_stats")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	vmID := request.GetInt("vmid", 0)
-	if vmID == 0 {
-		return mcp.NewToolResultError("vmid parameter is required"), nil
-	}
-
-	stats, err := s.proxmoxClient.GetVMStats(ctx, nodeName, vmID)
+func (s *Server) getVMStats(ctx context.Context, request mcp.CallToolRequest, args vmArgs) (*mcp.CallToolResult, error) {
+	stats, err := s.client(ctx).GetVMStats(ctx, args.NodeName, args.VMID)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get VM statistics: %v", err)), nil
+		return toolError("Failed to get VM statistics", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"message": "VM statistics retrieved successfully",
-		"node":    nodeName,
-		"vmid":    vmID,
+		"node":    args.NodeName,
+		"vmid":    args.VMID,
 		"stats":   stats,
 	})
 }
 
-func (s *Server) getContainerStats(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_container_stats")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	containerID := request.GetInt("container_id", 0)
-	if containerID == 0 {
-		return mcp.NewToolResultError("container_id parameter is required"), nil
-	}
-
-	stats, err := s.proxmoxClient.GetContainerStats(ctx, nodeName, containerID)
+func (s *Server) getContainerStats(ctx context.Context, request mcp.CallToolRequest, args containerArgs) (*mcp.CallToolResult, error) {
+	stats, err := s.client(ctx).GetContainerStats(ctx, args.NodeName, args.ContainerID)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get container statistics: %v", err)), nil
+		return toolError("Failed to get container statistics", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"message":      "Container statistics retrieved successfully",
-		"node":         nodeName,
-		"container_id": containerID,
+		"node":         args.NodeName,
+		"container_id": args.ContainerID,
 		"stats":        stats,
 	})
 }
 
 // ============ PHASE 4: STORAGE MANAGEMENT ============
 
-func (s *Server) getStorageInfo(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_storage_info")
-
-	storage := request.GetString("storage", "")
-	if storage == "" {
-		return mcp.NewToolResultError("storage parameter is required"), nil
-	}
-
-	info, err := s.proxmoxClient.GetStorageInfo(ctx, storage)
+func (s *Server) getStorageInfo(ctx context.Context, request mcp.CallToolRequest, args storageArgs) (*mcp.CallToolResult, error) {
+	info, err := s.client(ctx).GetStorageInfo(ctx, args.Storage)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get storage info: %v", err)), nil
+		return toolError("Failed to get storage info", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"message": "Storage information retrieved successfully",
-		"storage": storage,
+		"storage": args.Storage,
 		"info":    info,
 	})
 }
 
-func (s *Server) createStorage(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: create_storage")
-
-	storage := request.GetString("storage", "")
-	if storage == "" {
-		return mcp.NewToolResultError("storage parameter is required"), nil
-	}
-
-	storageType := request.GetString("storage_type", "")
-	if storageType == "" {
-		return mcp.NewToolResultError("storage_type parameter is required"), nil
-	}
-
-	content := request.GetString("content", "")
-	if content == "" {
-		return mcp.NewToolResultError("content parameter is required"), nil
-	}
-
-	// Get config parameter (optional)
-	config := make(map[string]interface{})
-	args := request.GetArguments()
-	if configValue, ok := args["config"]; ok && configValue != nil {
-		configBytes, err := json.Marshal(configValue)
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Invalid config parameter: %v", err)), nil
-		}
-		err = json.Unmarshal(configBytes, &config)
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to parse config: %v", err)), nil
-		}
-	}
-
-	result, err := s.proxmoxClient.CreateStorage(ctx, storage, storageType, content, config)
+func (s *Server) createStorage(ctx context.Context, request mcp.CallToolRequest, args createStorageArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).CreateStorage(ctx, args.Storage, args.StorageType, args.Content, args.Config)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to create storage: %v", err)), nil
+		return toolError("Failed to create storage", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"message": "Storage created successfully",
-		"storage": storage,
+		"storage": args.Storage,
 		"result":  result,
 	})
 }
 
-func (s *Server) deleteStorage(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: delete_storage")
-
-	storage := request.GetString("storage", "")
-	if storage == "" {
-		return mcp.NewToolResultError("storage parameter is required"), nil
-	}
-
-	result, err := s.proxmoxClient.DeleteStorage(ctx, storage)
+func (s *Server) deleteStorage(ctx context.Context, request mcp.CallToolRequest, args storageArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).DeleteStorage(ctx, args.Storage)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to delete storage: %v", err)), nil
+		return toolError("Failed to delete storage", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"message": "Storage deleted successfully",
-		"storage": storage,
+		"storage": args.Storage,
 		"result":  result,
 	})
 }
 
-func (s *Server) updateStorage(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: update_storage")
-
-	storage := request.GetString("storage", "")
-	if storage == "" {
-		return mcp.NewToolResultError("storage parameter is required"), nil
-	}
-
-	args := request.GetArguments()
-	configValue := args["config"]
-	if configValue == nil {
-		return mcp.NewToolResultError("config parameter is required"), nil
-	}
-
-	// Convert config to map[string]interface{}
-	var config map[string]interface{}
-	configBytes, err := json.Marshal(configValue)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Invalid config parameter: %v", err)), nil
-	}
-	err = json.Unmarshal(configBytes, &config)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to parse config: %v", err)), nil
-	}
-
-	result, err := s.proxmoxClient.UpdateStorage(ctx, storage, config)
+func (s *Server) updateStorage(ctx context.Context, request mcp.CallToolRequest, args storageConfigArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).UpdateStorage(ctx, args.Storage, args.Config)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to update storage: %v", err)), nil
+		return toolError("Failed to update storage", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"message": "Storage updated successfully",
-		"storage": storage,
+		"storage": args.Storage,
 		"result":  result,
 	})
 }
 
-func (s *Server) getStorageContent(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_storage_content")
-
-	storage := request.GetString("storage", "")
-	if storage == "" {
-		return mcp.NewToolResultError("storage parameter is required"), nil
-	}
-
-	content, err := s.proxmoxClient.GetStorageContent(ctx, storage)
+func (s *Server) getStorageContent(ctx context.Context, request mcp.CallToolRequest, args storageArgs) (*mcp.CallToolResult, error) {
+	content, err := s.client(ctx).GetStorageContent(ctx, args.Storage)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get storage content: %v", err)), nil
+		return toolError("Failed to get storage content", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"message": "Storage content retrieved successfully",
-		"storage": storage,
+		"storage": args.Storage,
 		"content": content,
 	})
 }
 
 // ============ PHASE 4: TASK MANAGEMENT ============
 
-func (s *Server) getTaskStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_task_status")
-
-	taskID := request.GetString("task_id", "")
-	if taskID == "" {
-		return mcp.NewToolResultError("task_id parameter is required"), nil
-	}
-
-	status, err := s.proxmoxClient.GetTaskStatus(ctx, taskID)
+func (s *Server) getTaskStatus(ctx context.Context, request mcp.CallToolRequest, args taskArgs) (*mcp.CallToolResult, error) {
+	status, err := s.client(ctx).GetTaskStatus(ctx, args.TaskID)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get task status: %v", err)), nil
+		return toolError("Failed to get task status", err), nil
 	}
 
-	return mcp.NewToolResultJSON(map[string]interface{}{
-		"message": "Task status retrieved successfully",
-		"task_id": taskID,
-		"status":  status,
+	return mcp.NewToolResultJSON(taskStatusResult{
+		Message: "Task status retrieved successfully",
+		TaskID:  args.TaskID,
+		Status:  status,
 	})
 }
 
-func (s *Server) getTaskLog(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_task_log")
-
-	taskID := request.GetString("task_id", "")
-	if taskID == "" {
-		return mcp.NewToolResultError("task_id parameter is required"), nil
-	}
-
-	start := request.GetInt("start", 0)
-	limit := request.GetInt("limit", 50)
-
-	log, err := s.proxmoxClient.GetTaskLog(ctx, taskID, start, limit)
+func (s *Server) getTaskLog(ctx context.Context, request mcp.CallToolRequest, args taskLogArgs) (*mcp.CallToolResult, error) {
+	log, err := s.client(ctx).GetTaskLog(ctx, args.TaskID, args.Start, args.Limit)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get task log: %v", err)), nil
+		return toolError("Failed to get task log", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"message": "Task log retrieved successfully",
-		"task_id": taskID,
+		"task_id": args.TaskID,
 		"log":     log,
 	})
 }
 
-func (s *Server) cancelTask(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: cancel_task")
-
-	taskID := request.GetString("task_id", "")
-	if taskID == "" {
-		return mcp.NewToolResultError("task_id parameter is required"), nil
-	}
-
-	result, err := s.proxmoxClient.CancelTask(ctx, taskID)
+func (s *Server) cancelTask(ctx context.Context, request mcp.CallToolRequest, args taskArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).CancelTask(ctx, args.TaskID)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to cancel task: %v", err)), nil
+		return toolError("Failed to cancel task", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"message": "Task cancellation requested",
-		"task_id": taskID,
+		"task_id": args.TaskID,
 		"result":  result,
 	})
 }
 
 // ============ PHASE 4: NODE MANAGEMENT ============
 
-func (s *Server) getNodeConfig(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_node_config")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	config, err := s.proxmoxClient.GetNodeConfig(ctx, nodeName)
+func (s *Server) getNodeConfig(ctx context.Context, request mcp.CallToolRequest, args nodeArgs) (*mcp.CallToolResult, error) {
+	config, err := s.client(ctx).GetNodeConfig(ctx, args.NodeName)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get node config: %v", err)), nil
+		return toolError("Failed to get node config", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"message": "Node configuration retrieved successfully",
-		"node":    nodeName,
+		"node":    args.NodeName,
 		"config":  config,
 	})
 }
 
-func (s *Server) updateNodeConfig(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: update_node_config")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	args := request.GetArguments()
-	configValue := args["config"]
-	if configValue == nil {
-		return mcp.NewToolResultError("config parameter is required"), nil
-	}
-
-	// Convert config to map[string]interface{}
-	var config map[string]interface{}
-	configBytes, err := json.Marshal(configValue)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Invalid config parameter: %v", err)), nil
-	}
-	err = json.Unmarshal(configBytes, &config)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to parse config: %v", err)), nil
-	}
-
-	result, err := s.proxmoxClient.UpdateNodeConfig(ctx, nodeName, config)
+func (s *Server) updateNodeConfig(ctx context.Context, request mcp.CallToolRequest, args nodeConfigArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).UpdateNodeConfig(ctx, args.NodeName, args.Config)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to update node config: %v", err)), nil
+		return toolError("Failed to update node config", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"message": "Node configuration updated successfully",
-		"node":    nodeName,
+		"node":    args.NodeName,
 		"result":  result,
 	})
 }
 
-func (s *Server) rebootNode(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: reboot_node")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	result, err := s.proxmoxClient.RebootNode(ctx, nodeName)
+func (s *Server) rebootNode(ctx context.Context, request mcp.CallToolRequest, args nodeArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).RebootNode(ctx, args.NodeName)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to reboot node: %v", err)), nil
+		return toolError("Failed to reboot node", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"message": "Node reboot initiated",
-		"node":    nodeName,
+		"node":    args.NodeName,
 		"result":  result,
 	})
 }
 
-func (s *Server) shutdownNode(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: shutdown_node")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	result, err := s.proxmoxClient.ShutdownNode(ctx, nodeName)
+func (s *Server) shutdownNode(ctx context.Context, request mcp.CallToolRequest, args nodeArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).ShutdownNode(ctx, args.NodeName)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to shutdown node: %v", err)), nil
+		return toolError("Failed to shutdown node", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"message": "Node shutdown initiated",
-		"node":    nodeName,
+		"node":    args.NodeName,
 		"result":  result,
 	})
 }
 
-func (s *Server) getNodeDisks(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_node_disks")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	disks, err := s.proxmoxClient.GetNodeDisks(ctx, nodeName)
+func (s *Server) getNodeDisks(ctx context.Context, request mcp.CallToolRequest, args nodeArgs) (*mcp.CallToolResult, error) {
+	disks, err := s.client(ctx).GetNodeDisks(ctx, args.NodeName)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get node disks: %v", err)), nil
+		return toolError("Failed to get node disks", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"message": "Node disks retrieved successfully",
-		"node":    nodeName,
+		"node":    args.NodeName,
 		"disks":   disks,
 	})
 }
 
-func (s *Server) getNodeCert(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_node_cert")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	cert, err := s.proxmoxClient.GetNodeCert(ctx, nodeName)
+func (s *Server) getNodeCert(ctx context.Context, request mcp.CallToolRequest, args nodeArgs) (*mcp.CallToolResult, error) {
+	cert, err := s.client(ctx).GetNodeCert(ctx, args.NodeName)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get node certificate: %v", err)), nil
+		return toolError("Failed to get node certificate", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"message": "Node certificate information retrieved successfully",
-		"node":    nodeName,
+		"node":    args.NodeName,
 		"cert":    cert,
 	})
 }
 
 // ============ POOL MANAGEMENT ============
 
-func (s *Server) createPool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: create_pool")
-
-	poolID := request.GetString("poolid", "")
-	if poolID == "" {
-		return mcp.NewToolResultError("poolid parameter is required"), nil
-	}
-
-	comment := request.GetString("comment", "")
-
-	// Get members array
-	args := request.GetArguments()
-	members := []string{}
-	if membersValue, ok := args["members"]; ok && membersValue != nil {
-		membersBytes, err := json.Marshal(membersValue)
-		if err == nil {
-			json.Unmarshal(membersBytes, &members)
-		}
-	}
-
-	result, err := s.proxmoxClient.CreatePool(ctx, poolID, comment, members)
+func (s *Server) createPool(ctx context.Context, request mcp.CallToolRequest, args createPoolArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).CreatePool(ctx, args.PoolID, args.Comment, args.Members)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to create pool: %v", err)), nil
+		return toolError("Failed to create pool", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"message": "Pool created successfully",
-		"poolid":  poolID,
+		"poolid":  args.PoolID,
 		"result":  result,
 	})
 }
 
-func (s *Server) updatePool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: update_pool")
-
-	poolID := request.GetString("poolid", "")
-	if poolID == "" {
-		return mcp.NewToolResultError("poolid parameter is required"), nil
-	}
-
-	comment := request.GetString("comment", "")
-	delete := request.GetBool("delete", false)
-
-	// Get members array
-	args := request.GetArguments()
-	members := []string{}
-	if membersValue, ok := args["members"]; ok && membersValue != nil {
-		membersBytes, err := json.Marshal(membersValue)
-		if err == nil {
-			json.Unmarshal(membersBytes, &members)
-		}
-	}
-
-	result, err := s.proxmoxClient.UpdatePool(ctx, poolID, comment, members, delete)
+func (s *Server) updatePool(ctx context.Context, request mcp.CallToolRequest, args updatePoolArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).UpdatePool(ctx, args.PoolID, args.Comment, args.Members, args.Delete)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to update pool: %v", err)), nil
+		return toolError("Failed to update pool", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"message": "Pool updated successfully",
-		"poolid":  poolID,
+		"poolid":  args.PoolID,
 		"result":  result,
 	})
 }
 
-func (s *Server) deletePool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: delete_pool")
-
-	poolID := request.GetString("poolid", "")
-	if poolID == "" {
-		return mcp.NewToolResultError("poolid parameter is required"), nil
-	}
-
-	result, err := s.proxmoxClient.DeletePool(ctx, poolID)
+func (s *Server) deletePool(ctx context.Context, request mcp.CallToolRequest, args poolArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).DeletePool(ctx, args.PoolID)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to delete pool: %v", err)), nil
+		return toolError("Failed to delete pool", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"message": "Pool deleted successfully",
-		"poolid":  poolID,
+		"poolid":  args.PoolID,
 		"result":  result,
 	})
 }
 
-func (s *Server) getPoolMembers(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_pool_members")
-
-	poolID := request.GetString("poolid", "")
-	if poolID == "" {
-		return mcp.NewToolResultError("poolid parameter is required"), nil
-	}
-
-	members, err := s.proxmoxClient.GetPoolMembers(ctx, poolID)
+func (s *Server) getPoolMembers(ctx context.Context, request mcp.CallToolRequest, args poolArgs) (*mcp.CallToolResult, error) {
+	members, err := s.client(ctx).GetPoolMembers(ctx, args.PoolID)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get pool members: %v", err)), nil
+		return toolError("Failed to get pool members", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"message": "Pool members retrieved successfully",
-		"poolid":  poolID,
+		"poolid":  args.PoolID,
 		"members": members,
 	})
 }
 
 // ============ ADDITIONAL TOOLS (Phase 5) ============
 
-func (s *Server) getStorageQuota(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_storage_quota")
-
-	storage := request.GetString("storage", "")
-	if storage == "" {
-		return mcp.NewToolResultError("storage parameter is required"), nil
-	}
-
-	quota, err := s.proxmoxClient.GetStorageQuota(ctx, storage)
+func (s *Server) getStorageQuota(ctx context.Context, request mcp.CallToolRequest, args storageArgs) (*mcp.CallToolResult, error) {
+	quota, err := s.client(ctx).GetStorageQuota(ctx, args.Storage)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get storage quota: %v", err)), nil
+		return toolError("Failed to get storage quota", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"message": "Storage quota information retrieved successfully",
-		"storage": storage,
+		"storage": args.Storage,
 		"quota":   quota,
 	})
 }
 
-func (s *Server) uploadBackup(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: upload_backup")
-
-	storage := request.GetString("storage", "")
-	if storage == "" {
-		return mcp.NewToolResultError("storage parameter is required"), nil
-	}
-
-	backupID := request.GetString("backup_id", "")
-	if backupID == "" {
-		return mcp.NewToolResultError("backup_id parameter is required"), nil
-	}
-
-	filePath := request.GetString("file_path", "")
-	if filePath == "" {
-		return mcp.NewToolResultError("file_path parameter is required"), nil
-	}
-
-	result, err := s.proxmoxClient.UploadBackup(ctx, storage, backupID, filePath)
+func (s *Server) uploadBackup(ctx context.Context, request mcp.CallToolRequest, args uploadBackupArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).UploadBackup(ctx, args.Storage, args.BackupID, args.FilePath)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to upload backup: %v", err)), nil
+		return toolError("Failed to upload backup", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"message":   "Backup upload initiated",
-		"storage":   storage,
-		"backup_id": backupID,
+		"storage":   args.Storage,
+		"backup_id": args.BackupID,
 		"result":    result,
 	})
 }
 
-func (s *Server) getNodeLogs(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_node_logs")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	lines := request.GetInt("lines", 50)
-
-	logs, err := s.proxmoxClient.GetNodeLogs(ctx, nodeName, lines)
+func (s *Server) getNodeLogs(ctx context.Context, request mcp.CallToolRequest, args nodeLogsArgs) (*mcp.CallToolResult, error) {
+	logs, err := s.client(ctx).GetNodeLogs(ctx, args.NodeName, args.Lines)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get node logs: %v", err)), nil
+		return toolError("Failed to get node logs", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"message": "Node system logs retrieved successfully",
-		"node":    nodeName,
-		"lines":   lines,
+		"node":    args.NodeName,
+		"lines":   args.Lines,
 		"logs":    logs,
 	})
 }
 
-func (s *Server) getNodeAPTUpdates(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_node_apt_updates")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	updates, err := s.proxmoxClient.GetNodeAPTUpdates(ctx, nodeName)
+func (s *Server) getNodeAPTUpdates(ctx context.Context, request mcp.CallToolRequest, args nodeArgs) (*mcp.CallToolResult, error) {
+	updates, err := s.client(ctx).GetNodeAPTUpdates(ctx, args.NodeName)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get APT updates: %v", err)), nil
+		return toolError("Failed to get APT updates", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"message": "Available package updates retrieved successfully",
-		"node":    nodeName,
+		"node":    args.NodeName,
 		"updates": updates,
 	})
 }
 
-func (s *Server) applyNodeUpdates(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: apply_node_updates")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
+func (s *Server) applyNodeUpdates(ctx context.Context, request mcp.CallToolRequest, args nodeTaskArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).ApplyNodeUpdates(ctx, args.NodeName)
+	if err != nil {
+		return toolError("Failed to apply node updates", err), nil
 	}
-
-	result, err := s.proxmoxClient.ApplyNodeUpdates(ctx, nodeName)
+	result, err = s.awaitTask(ctx, args.taskWait, result)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to apply node updates: %v", err)), nil
+		return toolError("Failed to apply node updates", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"message": "System updates installation initiated",
-		"node":    nodeName,
+		"node":    args.NodeName,
 		"result":  result,
 	})
 }
 
-func (s *Server) getNodeNetwork(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_node_network")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	network, err := s.proxmoxClient.GetNodeNetwork(ctx, nodeName)
+func (s *Server) getNodeNetwork(ctx context.Context, request mcp.CallToolRequest, args nodeArgs) (*mcp.CallToolResult, error) {
+	network, err := s.client(ctx).GetNodeNetwork(ctx, args.NodeName)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get node network configuration: %v", err)), nil
+		return toolError("Failed to get node network configuration", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"message": "Node network configuration retrieved successfully",
-		"node":    nodeName,
+		"node":    args.NodeName,
 		"network": network,
 	})
 }
 
-func (s *Server) getNodeDNS(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_node_dns")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	dns, err := s.proxmoxClient.GetNodeDNS(ctx, nodeName)
+func (s *Server) getNodeDNS(ctx context.Context, request mcp.CallToolRequest, args nodeArgs) (*mcp.CallToolResult, error) {
+	dns, err := s.client(ctx).GetNodeDNS(ctx, args.NodeName)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get node DNS configuration: %v", err)), nil
+		return toolError("Failed to get node DNS configuration", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"message": "Node DNS configuration retrieved successfully",
-		"node":    nodeName,
+		"node":    args.NodeName,
 		"dns":     dns,
 	})
 }
 
 // ============ HA (HIGH AVAILABILITY) CLUSTER MANAGEMENT HANDLERS ============
 
-func (s *Server) getHAStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_ha_status")
-
-	status, err := s.proxmoxClient.GetHAStatus(ctx)
+func (s *Server) getHAStatus(ctx context.Context, request mcp.CallToolRequest, _ noArgs) (*mcp.CallToolResult, error) {
+	status, err := s.client(ctx).GetHAStatus(ctx)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get HA status: %v", err)), nil
+		return toolError("Failed to get HA status", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
@@ -3370,57 +2110,38 @@ func (s *Server) getHAStatus(ctx context.Context, request mcp.CallToolRequest) (
 	})
 }
 
-func (s *Server) enableHAResource(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: enable_ha_resource")
-
-	sid := request.GetString("sid", "")
-	if sid == "" {
-		return mcp.NewToolResultError("sid parameter is required"), nil
-	}
-
-	comment := request.GetString("comment", "")
-	state := request.GetString("state", "")
-
-	result, err := s.proxmoxClient.EnableHAResource(ctx, sid, comment, state)
+func (s *Server) enableHAResource(ctx context.Context, request mcp.CallToolRequest, args enableHAResourceArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).EnableHAResource(ctx, args.SID, args.Comment, args.State)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to enable HA resource: %v", err)), nil
+		return toolError("Failed to enable HA resource", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"message": "HA resource enabled successfully",
-		"sid":     sid,
+		"sid":     args.SID,
 		"result":  result,
 	})
 }
 
-func (s *Server) disableHAResource(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: disable_ha_resource")
-
-	sid := request.GetString("sid", "")
-	if sid == "" {
-		return mcp.NewToolResultError("sid parameter is required"), nil
-	}
-
-	result, err := s.proxmoxClient.DisableHAResource(ctx, sid)
+func (s *Server) disableHAResource(ctx context.Context, request mcp.CallToolRequest, args haResourceArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).DisableHAResource(ctx, args.SID)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to disable HA resource: %v", err)), nil
+		return toolError("Failed to disable HA resource", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"message": "HA resource disabled successfully",
-		"sid":     sid,
+		"sid":     args.SID,
 		"result":  result,
 	})
 }
 
 // ============ CLUSTER OPERATIONS HANDLERS ============
 
-func (s *Server) getClusterConfig(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_cluster_config")
-
-	config, err := s.proxmoxClient.GetClusterConfig(ctx)
+func (s *Server) getClusterConfig(ctx context.Context, request mcp.CallToolRequest, _ noArgs) (*mcp.CallToolResult, error) {
+	config, err := s.client(ctx).GetClusterConfig(ctx)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get cluster config: %v", err)), nil
+		return toolError("Failed to get cluster config", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
@@ -3429,12 +2150,10 @@ func (s *Server) getClusterConfig(ctx context.Context, request mcp.CallToolReque
 	})
 }
 
-func (s *Server) getClusterNodesStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_cluster_nodes_status")
-
-	status, err := s.proxmoxClient.GetClusterNodesStatus(ctx)
+func (s *Server) getClusterNodesStatus(ctx context.Context, request mcp.CallToolRequest, _ noArgs) (*mcp.CallToolResult, error) {
+	status, err := s.client(ctx).GetClusterNodesStatus(ctx)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get cluster nodes status: %v", err)), nil
+		return toolError("Failed to get cluster nodes status", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
@@ -3443,57 +2162,38 @@ func (s *Server) getClusterNodesStatus(ctx context.Context, request mcp.CallTool
 	})
 }
 
-func (s *Server) addNodeToCluster(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: add_node_to_cluster")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	clusterName := request.GetString("cluster_name", "")
-	clusterNetwork := request.GetString("cluster_network", "")
-
-	result, err := s.proxmoxClient.AddNodeToCluster(ctx, nodeName, clusterName, clusterNetwork)
+func (s *Server) addNodeToCluster(ctx context.Context, request mcp.CallToolRequest, args addNodeToClusterArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).AddNodeToCluster(ctx, args.NodeName, args.ClusterName, args.ClusterNetwork)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to add node to cluster: %v", err)), nil
+		return toolError("Failed to add node to cluster", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"message": "Node added to cluster successfully",
-		"node":    nodeName,
+		"node":    args.NodeName,
 		"result":  result,
 	})
 }
 
-func (s *Server) removeNodeFromCluster(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: remove_node_from_cluster")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	result, err := s.proxmoxClient.RemoveNodeFromCluster(ctx, nodeName)
+func (s *Server) removeNodeFromCluster(ctx context.Context, request mcp.CallToolRequest, args nodeArgs) (*mcp.CallToolResult, error) {
+	result, err := s.client(ctx).RemoveNodeFromCluster(ctx, args.NodeName)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to remove node from cluster: %v", err)), nil
+		return toolError("Failed to remove node from cluster", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"message": "Node removed from cluster successfully",
-		"node":    nodeName,
+		"node":    args.NodeName,
 		"result":  result,
 	})
 }
 
 // ============ FIREWALL & NETWORK MANAGEMENT HANDLERS ============
 
-func (s *Server) getFirewallRules(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_firewall_rules")
-
-	rules, err := s.proxmoxClient.GetFirewallRules(ctx)
+func (s *Server) getFirewallRules(ctx context.Context, request mcp.CallToolRequest, _ noArgs) (*mcp.CallToolResult, error) {
+	rules, err := s.client(ctx).GetFirewallRules(ctx)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get firewall rules: %v", err)), nil
+		return toolError("Failed to get firewall rules", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
@@ -3503,33 +2203,21 @@ func (s *Server) getFirewallRules(ctx context.Context, request mcp.CallToolReque
 	})
 }
 
-func (s *Server) createFirewallRule(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: create_firewall_rule")
-
-	direction := request.GetString("direction", "")
-	if direction == "" {
-		return mcp.NewToolResultError("direction parameter is required"), nil
-	}
-
-	action := request.GetString("action", "")
-	if action == "" {
-		return mcp.NewToolResultError("action parameter is required"), nil
-	}
-
+func (s *Server) createFirewallRule(ctx context.Context, request mcp.CallToolRequest, args createFirewallRuleArgs) (*mcp.CallToolResult, error) {
 	rule := proxmox.FirewallRule{
-		Direction: direction,
-		Action:    action,
-		Source:    request.GetString("source", ""),
-		Dest:      request.GetString("dest", ""),
-		Proto:     request.GetString("proto", ""),
-		Sport:     request.GetString("sport", ""),
-		Dport:     request.GetString("dport", ""),
-		Comment:   request.GetString("comment", ""),
-		Enable:    request.GetInt("enable", 1),
+		Direction: args.Direction,
+		Action:    args.Action,
+		Source:    args.Source,
+		Dest:      args.Dest,
+		Proto:     args.Proto,
+		Sport:     args.Sport,
+		Dport:     args.Dport,
+		Comment:   args.Comment,
+		Enable:    args.Enable,
 	}
 
-	if err := s.proxmoxClient.CreateFirewallRule(ctx, rule); err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to create firewall rule: %v", err)), nil
+	if err := s.client(ctx).CreateFirewallRule(ctx, rule); err != nil {
+		return toolError("Failed to create firewall rule", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
@@ -3538,30 +2226,21 @@ func (s *Server) createFirewallRule(ctx context.Context, request mcp.CallToolReq
 	})
 }
 
-func (s *Server) deleteFirewallRule(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: delete_firewall_rule")
-
-	position := request.GetString("position", "")
-	if position == "" {
-		return mcp.NewToolResultError("position parameter is required"), nil
-	}
-
-	if err := s.proxmoxClient.DeleteFirewallRule(ctx, position); err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to delete firewall rule: %v", err)), nil
+func (s *Server) deleteFirewallRule(ctx context.Context, request mcp.CallToolRequest, args firewallRuleArgs) (*mcp.CallToolResult, error) {
+	if err := s.client(ctx).DeleteFirewallRule(ctx, args.Position); err != nil {
+		return toolError("Failed to delete firewall rule", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"message":  "Firewall rule deleted successfully",
-		"position": position,
+		"position": args.Position,
 	})
 }
 
-func (s *Server) getSecurityGroups(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_security_groups")
-
-	groups, err := s.proxmoxClient.GetSecurityGroups(ctx)
+func (s *Server) getSecurityGroups(ctx context.Context, request mcp.CallToolRequest, _ noArgs) (*mcp.CallToolResult, error) {
+	groups, err := s.client(ctx).GetSecurityGroups(ctx)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get security groups: %v", err)), nil
+		return toolError("Failed to get security groups", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
@@ -3571,21 +2250,14 @@ func (s *Server) getSecurityGroups(ctx context.Context, request mcp.CallToolRequ
 	})
 }
 
-func (s *Server) createSecurityGroup(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: create_security_group")
-
-	name := request.GetString("name", "")
-	if name == "" {
-		return mcp.NewToolResultError("name parameter is required"), nil
-	}
-
+func (s *Server) createSecurityGroup(ctx context.Context, request mcp.CallToolRequest, args createSecurityGroupArgs) (*mcp.CallToolResult, error) {
 	group := proxmox.SecurityGroup{
-		Name:    name,
-		Comment: request.GetString("comment", ""),
+		Name:    args.Name,
+		Comment: args.Comment,
 	}
 
-	if err := s.proxmoxClient.CreateSecurityGroup(ctx, group); err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to create security group: %v", err)), nil
+	if err := s.client(ctx).CreateSecurityGroup(ctx, group); err != nil {
+		return toolError("Failed to create security group", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
@@ -3594,43 +2266,29 @@ func (s *Server) createSecurityGroup(ctx context.Context, request mcp.CallToolRe
 	})
 }
 
-func (s *Server) getNetworkInterfaces(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_network_interfaces")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	interfaces, err := s.proxmoxClient.GetNetworkInterfaces(ctx, nodeName)
+func (s *Server) getNetworkInterfaces(ctx context.Context, request mcp.CallToolRequest, args nodeArgs) (*mcp.CallToolResult, error) {
+	interfaces, err := s.client(ctx).GetNetworkInterfaces(ctx, args.NodeName)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get network interfaces: %v", err)), nil
+		return toolError("Failed to get network interfaces", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"message":    "Network interfaces retrieved successfully",
-		"node":       nodeName,
+		"node":       args.NodeName,
 		"interfaces": interfaces,
 		"count":      len(interfaces),
 	})
 }
 
-func (s *Server) getVLANConfig(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	s.logger.Debug("Tool called: get_vlan_config")
-
-	nodeName := request.GetString("node_name", "")
-	if nodeName == "" {
-		return mcp.NewToolResultError("node_name parameter is required"), nil
-	}
-
-	vlans, err := s.proxmoxClient.GetVLANConfig(ctx, nodeName)
+func (s *Server) getVLANConfig(ctx context.Context, request mcp.CallToolRequest, args nodeArgs) (*mcp.CallToolResult, error) {
+	vlans, err := s.client(ctx).GetVLANConfig(ctx, args.NodeName)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get VLAN configuration: %v", err)), nil
+		return toolError("Failed to get VLAN configuration", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"message": "VLAN configuration retrieved successfully",
-		"node":    nodeName,
+		"node":    args.NodeName,
 		"vlans":   vlans,
 		"count":   len(vlans),
 	})
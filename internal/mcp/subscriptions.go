@@ -0,0 +1,321 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// mcp-go advertises the resources subscribe capability but does not route
+// resources/subscribe and resources/unsubscribe to the server, so both
+// transports intercept these requests before they reach mcp-go. Subscribed
+// resources are re-read every ResourcePollInterval and their subscribers are
+// sent notifications/resources/updated when the contents changed.
+
+const (
+	methodResourcesSubscribe   = "resources/subscribe"
+	methodResourcesUnsubscribe = "resources/unsubscribe"
+	// stdioSessionID is the session ID mcp-go gives the single stdio client
+	stdioSessionID = "stdio"
+)
+
+// resourceSubscriptions tracks the resources each session subscribed to and
+// the digest of their contents when last read
+type resourceSubscriptions struct {
+	mu       sync.Mutex
+	sessions map[string]map[string]bool
+	digests  map[string]string
+}
+
+func newResourceSubscriptions() *resourceSubscriptions {
+	return &resourceSubscriptions{
+		sessions: make(map[string]map[string]bool),
+		digests:  make(map[string]string),
+	}
+}
+
+// subscribe adds uri to the subscriptions of a session. digest is the
+// current contents of uri; it is kept when uri is already watched so that a
+// pending change is still notified.
+func (rs *resourceSubscriptions) subscribe(sessionID, uri, digest string) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	uris, ok := rs.sessions[sessionID]
+	if !ok {
+		uris = make(map[string]bool)
+		rs.sessions[sessionID] = uris
+	}
+	uris[uri] = true
+	if _, ok := rs.digests[uri]; !ok {
+		rs.digests[uri] = digest
+	}
+}
+
+// unsubscribe removes uri from the subscriptions of a session
+func (rs *resourceSubscriptions) unsubscribe(sessionID, uri string) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	delete(rs.sessions[sessionID], uri)
+	if len(rs.sessions[sessionID]) == 0 {
+		delete(rs.sessions, sessionID)
+	}
+	rs.forgetUnwatched()
+}
+
+// drop removes every subscription of a session that has ended
+func (rs *resourceSubscriptions) drop(sessionID string) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	delete(rs.sessions, sessionID)
+	rs.forgetUnwatched()
+}
+
+// forgetUnwatched drops the digests of resources nobody subscribes to any
+// more. The caller holds rs.mu.
+func (rs *resourceSubscriptions) forgetUnwatched() {
+	for uri := range rs.digests {
+		watched := false
+		for _, uris := range rs.sessions {
+			if uris[uri] {
+				watched = true
+				break
+			}
+		}
+		if !watched {
+			delete(rs.digests, uri)
+		}
+	}
+}
+
+// watched returns the subscribed resources with their subscribers
+func (rs *resourceSubscriptions) watched() map[string][]string {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	watched := make(map[string][]string)
+	for sessionID, uris := range rs.sessions {
+		for uri := range uris {
+			watched[uri] = append(watched[uri], sessionID)
+		}
+	}
+	return watched
+}
+
+// update records the digest of uri and reports whether it changed
+func (rs *resourceSubscriptions) update(uri, digest string) bool {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	previous, ok := rs.digests[uri]
+	if !ok {
+		return false
+	}
+	rs.digests[uri] = digest
+	return previous != digest
+}
+
+// handleSubscription answers message when it is a resources/subscribe or
+// resources/unsubscribe request of the session and reports whether it did
+func (s *Server) handleSubscription(ctx context.Context, sessionID string, message []byte) ([]byte, bool) {
+	if s.subscriptions == nil || !bytes.Contains(message, []byte("subscribe")) {
+		return nil, false
+	}
+
+	var request struct {
+		ID     mcp.RequestId `json:"id"`
+		Method string        `json:"method"`
+		Params struct {
+			URI string `json:"uri"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(message, &request); err != nil {
+		return nil, false
+	}
+	if request.Method != methodResourcesSubscribe && request.Method != methodResourcesUnsubscribe {
+		return nil, false
+	}
+
+	var response interface{} = mcp.NewJSONRPCResultResponse(request.ID, mcp.EmptyResult{})
+	uri := request.Params.URI
+	switch {
+	case uri == "":
+		response = mcp.NewJSONRPCError(request.ID, mcp.INVALID_PARAMS, "uri is required", nil)
+	case request.Method == methodResourcesUnsubscribe:
+		s.subscriptions.unsubscribe(sessionID, uri)
+	default:
+		if err := s.subscribeResource(ctx, sessionID, uri); err != nil {
+			response = mcp.NewJSONRPCError(request.ID, mcp.INVALID_PARAMS, err.Error(), map[string]string{"uri": uri})
+		}
+	}
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// subscribeResource reads uri to check that it exists and the client may read
+// it, then subscribes the session to it
+func (s *Server) subscribeResource(ctx context.Context, sessionID, uri string) error {
+	def, vars, ok := s.resolveResource(uri)
+	if !ok {
+		return fmt.Errorf("unknown resource %s", uri)
+	}
+	if err := s.authorizeResource(ctx, def); err != nil {
+		return err
+	}
+	contents, err := s.resourceContents(ctx, def, vars, uri)
+	if err != nil {
+		return err
+	}
+	s.subscriptions.subscribe(sessionID, uri, resourceDigest(def, contents))
+	s.logger.WithField("resource", uri).Debugf("Session %s subscribed", sessionID)
+	return nil
+}
+
+// watchResources re-reads the subscribed resources every poll interval and
+// notifies the subscribers of those that changed, until ctx is cancelled
+func (s *Server) watchResources(ctx context.Context) {
+	if s.subscriptions == nil {
+		return
+	}
+
+	ticker := time.NewTicker(s.options.ResourcePollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.pollResources(ctx)
+		}
+	}
+}
+
+// pollResources checks every subscribed resource once
+func (s *Server) pollResources(ctx context.Context) {
+	for uri, sessionIDs := range s.subscriptions.watched() {
+		def, vars, ok := s.resolveResource(uri)
+		if !ok {
+			continue
+		}
+		readCtx, cancel := context.WithTimeout(ctx, s.options.ResourcePollInterval)
+		contents, err := s.resourceContents(readCtx, def, vars, uri)
+		cancel()
+		if err != nil {
+			s.logger.WithError(err).WithField("resource", uri).Debug("Polling subscribed resource failed")
+			continue
+		}
+		if !s.subscriptions.update(uri, resourceDigest(def, contents)) {
+			continue
+		}
+
+		for _, sessionID := range sessionIDs {
+			err := s.server.SendNotificationToSpecificClient(sessionID, mcp.MethodNotificationResourceUpdated, map[string]any{"uri": uri})
+			if errors.Is(err, server.ErrSessionNotFound) {
+				s.subscriptions.drop(sessionID)
+			} else if err != nil {
+				s.logger.WithError(err).WithField("resource", uri).Debugf("Notifying session %s failed", sessionID)
+			}
+		}
+	}
+}
+
+// subscriptionReader passes the stdio input through to mcp-go, answering
+// subscription requests itself on the way
+type subscriptionReader struct {
+	s       *Server
+	ctx     context.Context
+	in      *bufio.Reader
+	out     io.Writer
+	pending []byte
+}
+
+func (r *subscriptionReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		line, err := r.in.ReadBytes('\n')
+		if len(line) > 0 {
+			if response, ok := r.s.handleSubscription(r.ctx, stdioSessionID, line); ok {
+				if _, err := r.out.Write(append(response, '\n')); err != nil {
+					return 0, err
+				}
+			} else {
+				r.pending = line
+			}
+		}
+		if err != nil {
+			if len(r.pending) == 0 {
+				return 0, err
+			}
+			break
+		}
+	}
+
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// syncWriter serializes the writes of mcp-go and subscriptionReader to stdout.
+// Both write a whole message per call.
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
+
+// subscriptionHandler answers subscription requests posted to the Streamable
+// HTTP endpoint and drops the subscriptions of sessions that are terminated
+func (s *Server) subscriptionHandler(next http.Handler) http.Handler {
+	if s.subscriptions == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sessionID := r.Header.Get(server.HeaderKeySessionID)
+		if sessionID == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodDelete:
+			s.subscriptions.drop(sessionID)
+		case http.MethodPost:
+			body, err := io.ReadAll(r.Body)
+			r.Body.Close()
+			if err != nil {
+				http.Error(w, "Failed to read request body", http.StatusBadRequest)
+				return
+			}
+			if response, ok := s.handleSubscription(r.Context(), sessionID, body); ok {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				w.Write(append(response, '\n'))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
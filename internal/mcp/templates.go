@@ -0,0 +1,59 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/surrealwolf/proxmox-ve-mcp/internal/proxmox"
+)
+
+// createTemplateFromImage handles the create_template_from_image tool
+func (s *Server) createTemplateFromImage(ctx context.Context, request mcp.CallToolRequest, args createTemplateFromImageArgs) (*mcp.CallToolResult, error) {
+	opts := proxmox.TemplateFromImageOptions{
+		Node:              args.NodeName,
+		VMID:              args.VMID,
+		Name:              args.Name,
+		ImageURL:          args.ImageURL,
+		ImageStorage:      args.ImageStorage,
+		ImageFile:         args.ImageFile,
+		Checksum:          args.Checksum,
+		ChecksumAlgorithm: args.ChecksumAlgorithm,
+		DiskStorage:       args.DiskStorage,
+		DiskSize:          args.DiskSize,
+		Memory:            args.Memory,
+		Cores:             args.Cores,
+		Bridge:            args.Bridge,
+		TaskTimeout:       time.Duration(args.TaskTimeoutSeconds) * time.Second,
+	}
+	ipConfig, err := parseIPConfig(args.IPConfig)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if args.User != "" || len(args.SSHKeys) > 0 || len(ipConfig) > 0 {
+		opts.CloudInit = &proxmox.CloudInitConfig{User: args.User, SSHKeys: args.SSHKeys, IPConfig: ipConfig}
+	}
+
+	template, err := s.client(ctx).CreateTemplateFromImage(ctx, opts)
+	if err != nil {
+		if template == nil || len(template.Steps) == 0 {
+			return toolError("Failed to create template", err), nil
+		}
+		result := mcp.NewToolResultStructured(map[string]interface{}{
+			"error":       "Failed to create template",
+			"message":     err.Error(),
+			"template":    template,
+			"rolled_back": template.RolledBack,
+		}, fmt.Sprintf("Failed to create template: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]interface{}{
+		"action":   "create_template_from_image",
+		"vmid":     template.VMID,
+		"node":     template.Node,
+		"template": template,
+	})
+}
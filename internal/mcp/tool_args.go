@@ -0,0 +1,481 @@
+package mcp
+
+// Argument structs of the tools. Tools with the same arguments share a type;
+// see args.go for the tags.
+
+// taskWait is embedded by the arguments of tools that start a Proxmox task
+type taskWait struct {
+	Wait           bool `json:"wait" description:"Wait for the task to finish and return its outcome"`
+	TimeoutSeconds int  `json:"timeout_seconds" description:"Maximum seconds to wait when wait is true" default:"300" minimum:"1"`
+}
+
+// ============ Nodes ============
+
+type nodeArgs struct {
+	NodeName string `json:"node_name" description:"Name of the node" required:"true"`
+}
+
+type nodeTaskArgs struct {
+	nodeArgs
+	taskWait
+}
+
+type nodeConfigArgs struct {
+	nodeArgs
+	Config map[string]interface{} `json:"config" description:"Configuration to update" required:"true"`
+}
+
+type nodeLogsArgs struct {
+	nodeArgs
+	Lines int `json:"lines" description:"Number of log lines to retrieve" default:"50" minimum:"1"`
+}
+
+type nodeTasksArgs struct {
+	nodeArgs
+	TypeFilter string `json:"typefilter" description:"Only tasks of this type, e.g. qmstart, vzdump"`
+	VMID       int    `json:"vmid" description:"Only tasks of this VM or container" minimum:"100"`
+	Errors     bool   `json:"errors" description:"Only failed tasks"`
+	Since      int64  `json:"since" description:"Only tasks started at or after this Unix timestamp" minimum:"0"`
+	Until      int64  `json:"until" description:"Only tasks started at or before this Unix timestamp" minimum:"0"`
+	Source     string `json:"source" description:"Finished (archive), running (active) or all tasks" enum:"archive,active,all" default:"archive"`
+	Limit      int    `json:"limit" description:"Maximum number of tasks to return" default:"50" minimum:"1"`
+}
+
+// ============ Virtual machines ============
+
+// vmArgs identify an existing VM by vmid, name or tag; see guests.go
+type vmArgs struct {
+	NodeName string `json:"node_name" description:"Name of the node (looked up from the VM if omitted)" guest:"node"`
+	VMID     int    `json:"vmid" description:"VM ID" minimum:"100" guest:"qemu"`
+	Name     string `json:"name" description:"VM name, instead of vmid" guest:"name"`
+	Tag      string `json:"tag" description:"Tag carried by exactly one VM, instead of vmid" guest:"tag"`
+}
+
+type vmTaskArgs struct {
+	vmArgs
+	taskWait
+}
+
+type deleteVMArgs struct {
+	vmArgs
+	Force bool `json:"force" description:"Force delete even if running"`
+	taskWait
+}
+
+type createVMArgs struct {
+	NodeName string `json:"node_name" description:"Name of the node" required:"true"`
+	VMID     int    `json:"vmid" description:"VM ID (must be unique)" required:"true" minimum:"100"`
+	Name     string `json:"name" description:"VM name" required:"true"`
+	Memory   int    `json:"memory" description:"Memory in MB" default:"512" minimum:"16"`
+	Cores    int    `json:"cores" description:"CPU cores" default:"1" minimum:"1"`
+	Sockets  int    `json:"sockets" description:"CPU sockets" default:"1" minimum:"1"`
+	taskWait
+}
+
+type createVMAdvancedArgs struct {
+	NodeName string `json:"node_name" description:"Name of the node" required:"true"`
+	VMID     int    `json:"vmid" description:"VM ID (must be unique)" required:"true" minimum:"100"`
+	Name     string `json:"name" description:"VM name"`
+	Memory   int    `json:"memory" description:"Memory in MB" minimum:"16"`
+	Cores    int    `json:"cores" description:"CPU cores" minimum:"1"`
+	Sockets  int    `json:"sockets" description:"CPU sockets" minimum:"1"`
+	IDE2     string `json:"ide2" description:"CD/DVD drive (e.g., /mnt/pve/iso/ubuntu.iso)"`
+	SATA0    string `json:"sata0" description:"Primary disk storage (e.g., local-lvm:10)"`
+	Net0     string `json:"net0" description:"Network configuration (e.g., virtio,bridge=vmbr0)"`
+	taskWait
+}
+
+type cloneVMArgs struct {
+	NodeName   string `json:"node_name" description:"Name of the node (looked up from the VM if omitted)" guest:"node"`
+	SourceVMID int    `json:"source_vmid" description:"Source VM ID to clone from" required:"true" minimum:"100" guest:"qemu"`
+	NewVMID    int    `json:"new_vmid" description:"New VM ID (must be unique)" required:"true" minimum:"100"`
+	NewName    string `json:"new_name" description:"New VM name" required:"true"`
+	Full       bool   `json:"full" description:"Full clone instead of a linked clone" default:"true"`
+	taskWait
+}
+
+type getVMConfigArgs struct {
+	vmArgs
+	Parsed bool `json:"parsed" description:"Return disks, network devices, CPU, boot order, EFI disk, TPM state and PCI and USB passthrough as parsed fields instead of property strings"`
+}
+
+type vmConfigArgs struct {
+	vmArgs
+	Config map[string]interface{} `json:"config" description:"Configuration to update (e.g., {\"template\": 1} to mark as template)" required:"true"`
+}
+
+type createVMSnapshotArgs struct {
+	vmArgs
+	SnapName    string `json:"snap_name" description:"Snapshot name" required:"true"`
+	Description string `json:"description" description:"Snapshot description"`
+	taskWait
+}
+
+type vmSnapshotArgs struct {
+	vmArgs
+	SnapName string `json:"snap_name" description:"Snapshot name" required:"true"`
+	taskWait
+}
+
+type deleteVMSnapshotArgs struct {
+	vmArgs
+	SnapName string `json:"snap_name" description:"Snapshot name" required:"true"`
+	Force    bool   `json:"force" description:"Force delete"`
+	taskWait
+}
+
+type migrateVMArgs struct {
+	vmArgs
+	TargetNode string `json:"target_node" description:"Target node name" required:"true"`
+	Online     bool   `json:"online" description:"Perform live migration"`
+	taskWait
+}
+
+type setVMCloudInitArgs struct {
+	vmArgs
+	User         string                 `json:"user" description:"Default user (ciuser)"`
+	Password     string                 `json:"password" description:"Password of the default user (cipassword)"`
+	SSHKeys      []string               `json:"ssh_keys" description:"SSH public keys of the default user, sent URL-encoded as Proxmox expects"`
+	IPConfig     map[string]interface{} `json:"ip_config" description:"IP settings by interface number, e.g. {\"0\": \"ip=dhcp\", \"1\": \"ip=10.0.0.5/24,gw=10.0.0.1\"}"`
+	Nameserver   string                 `json:"nameserver" description:"DNS servers, space-separated"`
+	SearchDomain string                 `json:"searchdomain" description:"DNS search domains, space-separated"`
+	Custom       string                 `json:"cicustom" description:"Custom snippets, e.g. user=local:snippets/user.yaml,network=local:snippets/net.yaml"`
+	Type         string                 `json:"citype" description:"Cloud-init data format" enum:"nocloud,configdrive2,opennebula"`
+	Delete       []string               `json:"delete" description:"Settings to remove, e.g. [\"sshkeys\", \"ipconfig1\"]"`
+	Regenerate   bool                   `json:"regenerate" description:"Regenerate the cloud-init drive so the change applies without waiting for the next start"`
+}
+
+type vmCloudInitArgs struct {
+	vmArgs
+	Section string `json:"section" description:"Generated data to return; all sections when omitted" enum:"user,network,meta"`
+}
+
+type createTemplateFromImageArgs struct {
+	NodeName           string                 `json:"node_name" description:"Node to build the template on" required:"true"`
+	VMID               int                    `json:"vmid" description:"ID of the template (next free ID if omitted)" minimum:"100"`
+	Name               string                 `json:"name" description:"Template name (default template-<vmid>)"`
+	ImageURL           string                 `json:"image_url" description:"URL of the cloud image, e.g. https://cloud-images.ubuntu.com/noble/current/noble-server-cloudimg-amd64.img" required:"true"`
+	ImageStorage       string                 `json:"image_storage" description:"Storage with import content to download the image to; an image already there is reused" required:"true"`
+	ImageFile          string                 `json:"image_file" description:"File name of the image on image_storage (default from the URL, .img renamed to .qcow2)"`
+	Checksum           string                 `json:"checksum" description:"Expected checksum of the image"`
+	ChecksumAlgorithm  string                 `json:"checksum_algorithm" description:"Algorithm of checksum" enum:"md5,sha1,sha224,sha256,sha384,sha512"`
+	DiskStorage        string                 `json:"disk_storage" description:"Storage for the template disk and cloud-init drive, e.g. local-lvm" required:"true"`
+	DiskSize           string                 `json:"disk_size" description:"Size to grow the imported disk to, e.g. 20G"`
+	Memory             int                    `json:"memory" description:"Memory in MB" default:"2048" minimum:"16"`
+	Cores              int                    `json:"cores" description:"CPU cores" default:"2" minimum:"1"`
+	Bridge             string                 `json:"bridge" description:"Network bridge of net0" default:"vmbr0"`
+	User               string                 `json:"user" description:"Cloud-init default user"`
+	SSHKeys            []string               `json:"ssh_keys" description:"Cloud-init SSH public keys"`
+	IPConfig           map[string]interface{} `json:"ip_config" description:"Cloud-init IP settings by interface number, e.g. {\"0\": \"ip=dhcp\"}"`
+	TaskTimeoutSeconds int                    `json:"task_timeout_seconds" description:"Maximum seconds to wait for each task, such as the download" default:"600" minimum:"1"`
+}
+
+type resizeVMDiskArgs struct {
+	vmArgs
+	Disk string `json:"disk" description:"Disk to resize, e.g. scsi0" required:"true"`
+	Size string `json:"size" description:"New size, e.g. 50G, or an increment such as +10G; disks cannot shrink" required:"true"`
+	taskWait
+}
+
+type setVMDiskOptionsArgs struct {
+	vmArgs
+	Disk     string `json:"disk" description:"Disk to change, e.g. scsi0" required:"true"`
+	Cache    string `json:"cache" description:"Cache mode" enum:"none,writethrough,writeback,unsafe,directsync"`
+	Discard  string `json:"discard" description:"Pass discard (TRIM) requests to the storage" enum:"on,ignore"`
+	IOThread bool   `json:"iothread" description:"Give the disk its own I/O thread"`
+	SSD      bool   `json:"ssd" description:"Present the disk to the guest as an SSD"`
+	Backup   bool   `json:"backup" description:"Include the disk in backups"`
+}
+
+type moveVMDiskArgs struct {
+	vmArgs
+	Disk         string `json:"disk" description:"Disk to move, e.g. scsi0" required:"true"`
+	Storage      string `json:"storage" description:"Target storage" required:"true"`
+	Format       string `json:"format" description:"Format of the moved disk (default: keep the current format where the storage supports it)" enum:"raw,qcow2,vmdk"`
+	DeleteSource bool   `json:"delete_source" description:"Delete the source volume after the move instead of keeping it as an unused disk"`
+	taskWait
+}
+
+type detachVMDiskArgs struct {
+	vmArgs
+	Disks         []string `json:"disks" description:"Disks to detach, e.g. [\"scsi1\"] or [\"unused0\"]" required:"true"`
+	DeleteVolumes bool     `json:"delete_volumes" description:"Destroy the volumes instead of keeping them as unused disks"`
+}
+
+type importVMDiskArgs struct {
+	vmArgs
+	Disk    string `json:"disk" description:"Free slot to attach the imported disk to, e.g. scsi1" required:"true"`
+	Source  string `json:"source" description:"Volume to import, e.g. local:import/debian-12.qcow2 or local-lvm:vm-101-disk-0" required:"true"`
+	Storage string `json:"storage" description:"Storage for the imported disk" required:"true"`
+	Format  string `json:"format" description:"Format of the imported disk" enum:"raw,qcow2,vmdk"`
+	taskWait
+}
+
+// ============ Containers ============
+
+// containerArgs identify an existing container by container_id, name or
+// tag; see guests.go
+type containerArgs struct {
+	NodeName    string `json:"node_name" description:"Name of the node (looked up from the container if omitted)" guest:"node"`
+	ContainerID int    `json:"container_id" description:"Container ID" minimum:"100" guest:"lxc"`
+	Name        string `json:"name" description:"Container hostname, instead of container_id" guest:"name"`
+	Tag         string `json:"tag" description:"Tag carried by exactly one container, instead of container_id" guest:"tag"`
+}
+
+type containerTaskArgs struct {
+	containerArgs
+	taskWait
+}
+
+type deleteContainerArgs struct {
+	containerArgs
+	Force bool `json:"force" description:"Force delete even if running"`
+	taskWait
+}
+
+type createContainerArgs struct {
+	NodeName    string `json:"node_name" description:"Name of the node" required:"true"`
+	ContainerID int    `json:"container_id" description:"Container ID (must be unique)" required:"true" minimum:"100"`
+	Hostname    string `json:"hostname" description:"Container hostname" required:"true"`
+	Storage     string `json:"storage" description:"Storage device ID" required:"true"`
+	Memory      int    `json:"memory" description:"Memory in MB" default:"512" minimum:"16"`
+	Cores       int    `json:"cores" description:"CPU cores" default:"1" minimum:"1"`
+	OSType      string `json:"ostype" description:"OS type" default:"debian"`
+	taskWait
+}
+
+type createContainerAdvancedArgs struct {
+	NodeName    string `json:"node_name" description:"Name of the node" required:"true"`
+	ContainerID int    `json:"container_id" description:"Container ID (must be unique)" required:"true" minimum:"100"`
+	Hostname    string `json:"hostname" description:"Container hostname"`
+	Storage     string `json:"storage" description:"Storage device ID"`
+	Memory      int    `json:"memory" description:"Memory in MB" minimum:"16"`
+	Cores       int    `json:"cores" description:"CPU cores" minimum:"1"`
+	OSType      string `json:"ostype" description:"OS type"`
+	Net0        string `json:"net0" description:"Network configuration (e.g., name=eth0,bridge=vmbr0)"`
+	RootFS      string `json:"rootfs" description:"Root filesystem (e.g., local-lvm:10)"`
+	taskWait
+}
+
+type cloneContainerArgs struct {
+	NodeName          string `json:"node_name" description:"Name of the node (looked up from the container if omitted)" guest:"node"`
+	SourceContainerID int    `json:"source_container_id" description:"Source container ID to clone from" required:"true" minimum:"100" guest:"lxc"`
+	NewContainerID    int    `json:"new_container_id" description:"New container ID (must be unique)" required:"true" minimum:"100"`
+	NewHostname       string `json:"new_hostname" description:"New container hostname" required:"true"`
+	Full              bool   `json:"full" description:"Full clone instead of a linked clone" default:"true"`
+	taskWait
+}
+
+type containerConfigArgs struct {
+	containerArgs
+	Config map[string]interface{} `json:"config" description:"Configuration to update" required:"true"`
+}
+
+type createContainerSnapshotArgs struct {
+	containerArgs
+	SnapName    string `json:"snap_name" description:"Snapshot name" required:"true"`
+	Description string `json:"description" description:"Snapshot description"`
+	taskWait
+}
+
+type containerSnapshotArgs struct {
+	containerArgs
+	SnapName string `json:"snap_name" description:"Snapshot name" required:"true"`
+	taskWait
+}
+
+type deleteContainerSnapshotArgs struct {
+	containerArgs
+	SnapName string `json:"snap_name" description:"Snapshot name" required:"true"`
+	Force    bool   `json:"force" description:"Force delete"`
+	taskWait
+}
+
+// ============ Users and access ============
+
+type userArgs struct {
+	UserID string `json:"userid" description:"User ID (e.g., user@pve)" required:"true"`
+}
+
+type createUserArgs struct {
+	userArgs
+	Password string `json:"password" description:"Initial password (set after creation; may fail with an API token for the PAM realm, works with a session ticket)"`
+	Email    string `json:"email" description:"Email address"`
+	Comment  string `json:"comment" description:"Comment"`
+}
+
+type updateUserArgs struct {
+	userArgs
+	Email     string `json:"email" description:"Email address"`
+	Comment   string `json:"comment" description:"Comment"`
+	FirstName string `json:"firstname" description:"First name"`
+	LastName  string `json:"lastname" description:"Last name"`
+	Enable    bool   `json:"enable" description:"Enable or disable the user" default:"true"`
+	Expire    int64  `json:"expire" description:"Expiration Unix timestamp (0 never expires)" minimum:"0"`
+}
+
+type changePasswordArgs struct {
+	userArgs
+	Password string `json:"password" description:"New password" required:"true"`
+}
+
+type groupArgs struct {
+	GroupID string `json:"groupid" description:"Group ID" required:"true"`
+}
+
+type createGroupArgs struct {
+	groupArgs
+	Comment string `json:"comment" description:"Comment"`
+}
+
+type roleArgs struct {
+	RoleID string `json:"roleid" description:"Role ID" required:"true"`
+}
+
+type createRoleArgs struct {
+	roleArgs
+	Privs []string `json:"privs" description:"List of privileges, e.g. VM.PowerMgmt"`
+}
+
+type setACLArgs struct {
+	Path      string `json:"path" description:"ACL path (e.g., /vms, /nodes)" required:"true"`
+	Role      string `json:"role" description:"Role ID" required:"true"`
+	UserID    string `json:"userid" description:"User ID (one of userid, groupid or tokenid is required)"`
+	GroupID   string `json:"groupid" description:"Group ID"`
+	TokenID   string `json:"tokenid" description:"Token ID"`
+	Propagate bool   `json:"propagate" description:"Propagate permissions down the tree" default:"true"`
+}
+
+type apiTokenArgs struct {
+	userArgs
+	TokenID string `json:"tokenid" description:"Token ID" required:"true"`
+}
+
+type createAPITokenArgs struct {
+	apiTokenArgs
+	Expire  int64 `json:"expire" description:"Expiration Unix timestamp (0 never expires)" minimum:"0"`
+	PrivSep bool  `json:"privsep" description:"Separate privileges"`
+}
+
+// ============ Storage and backups ============
+
+type storageArgs struct {
+	Storage string `json:"storage" description:"Storage device ID" required:"true"`
+}
+
+type createStorageArgs struct {
+	storageArgs
+	StorageType string                 `json:"storage_type" description:"Storage type (dir, nfs, lvm, iscsi, etc.)" required:"true"`
+	Content     string                 `json:"content" description:"Content types (images, rootdir, backup, etc.)" required:"true"`
+	Config      map[string]interface{} `json:"config" description:"Type-specific configuration"`
+}
+
+type storageConfigArgs struct {
+	storageArgs
+	Config map[string]interface{} `json:"config" description:"Configuration to update" required:"true"`
+}
+
+type createVMBackupArgs struct {
+	vmArgs
+	Storage  string `json:"storage" description:"Storage device ID" required:"true"`
+	BackupID string `json:"backup_id" description:"Backup ID"`
+	Notes    string `json:"notes" description:"Backup notes"`
+	taskWait
+}
+
+type createContainerBackupArgs struct {
+	containerArgs
+	Storage  string `json:"storage" description:"Storage device ID" required:"true"`
+	BackupID string `json:"backup_id" description:"Backup ID"`
+	Notes    string `json:"notes" description:"Backup notes"`
+	taskWait
+}
+
+type backupArgs struct {
+	storageArgs
+	BackupID string `json:"backup_id" description:"Backup ID/filename" required:"true"`
+	taskWait
+}
+
+type restoreBackupArgs struct {
+	NodeName string `json:"node_name" description:"Node name" required:"true"`
+	BackupID string `json:"backup_id" description:"Backup ID/filename" required:"true"`
+	Storage  string `json:"storage" description:"Storage device ID" required:"true"`
+	taskWait
+}
+
+type uploadBackupArgs struct {
+	storageArgs
+	BackupID string `json:"backup_id" description:"Backup ID/filename" required:"true"`
+	FilePath string `json:"file_path" description:"Local file path to upload" required:"true"`
+}
+
+// ============ Pools ============
+
+type poolArgs struct {
+	PoolID string `json:"poolid" description:"Pool ID" required:"true"`
+}
+
+type createPoolArgs struct {
+	poolArgs
+	Comment string   `json:"comment" description:"Pool comment"`
+	Members []string `json:"members" description:"Pool members: VM/container IDs or storage IDs"`
+}
+
+type updatePoolArgs struct {
+	createPoolArgs
+	Delete bool `json:"delete" description:"Remove the listed members instead of adding them"`
+}
+
+// ============ Tasks ============
+
+type taskArgs struct {
+	TaskID string `json:"task_id" description:"Task ID (UPID format)" required:"true"`
+}
+
+type taskLogArgs struct {
+	taskArgs
+	Start int `json:"start" description:"Start line number" minimum:"0"`
+	Limit int `json:"limit" description:"Number of lines to return" default:"50" minimum:"1"`
+}
+
+// ============ HA and cluster ============
+
+type haResourceArgs struct {
+	SID string `json:"sid" description:"Resource ID (sid format: type:id, e.g., vm:100)" required:"true"`
+}
+
+type enableHAResourceArgs struct {
+	haResourceArgs
+	Comment string `json:"comment" description:"HA resource comment"`
+	State   string `json:"state" description:"Initial state" enum:"started,stopped,disabled,ignored"`
+}
+
+type addNodeToClusterArgs struct {
+	NodeName       string `json:"node_name" description:"Node name to add" required:"true"`
+	ClusterName    string `json:"cluster_name" description:"Cluster name"`
+	ClusterNetwork string `json:"cluster_network" description:"Cluster network address"`
+}
+
+// ============ Firewall ============
+
+type createFirewallRuleArgs struct {
+	Direction string `json:"direction" description:"Rule direction" required:"true" enum:"in,out,group"`
+	Action    string `json:"action" description:"Action: ACCEPT, DROP, REJECT, or a security group name for group rules" required:"true"`
+	Source    string `json:"source" description:"Source address/network"`
+	Dest      string `json:"dest" description:"Destination address/network"`
+	Proto     string `json:"proto" description:"Protocol: tcp, udp, esp, gre, etc"`
+	Sport     string `json:"sport" description:"Source port or port range"`
+	Dport     string `json:"dport" description:"Destination port or port range"`
+	Comment   string `json:"comment" description:"Rule comment"`
+	Enable    int    `json:"enable" description:"Enable rule" enum:"0,1" default:"1"`
+}
+
+type firewallRuleArgs struct {
+	Position string `json:"position" description:"Rule position/ID to delete" required:"true"`
+}
+
+type createSecurityGroupArgs struct {
+	Name    string `json:"name" description:"Security group name" required:"true"`
+	Comment string `json:"comment" description:"Group comment"`
+}
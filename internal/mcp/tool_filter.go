@@ -0,0 +1,46 @@
+package mcp
+
+import (
+	"path"
+	"strings"
+)
+
+// ToolFilter narrows the set of registered tools
+type ToolFilter struct {
+	// ReadOnly registers only tools that do not change anything
+	ReadOnly bool
+	// Allow lists tool names or globs (e.g. "get_*"); when non-empty, only
+	// matching tools are registered
+	Allow []string
+	// Deny lists tool names or globs (e.g. "delete_*") that are never
+	// registered; it takes precedence over Allow
+	Deny []string
+}
+
+// isReadOnlyTool reports whether a tool only reads state. Tool names follow
+// the verb_object convention, and only get_ and list_ tools are free of side
+// effects.
+func isReadOnlyTool(name string) bool {
+	return strings.HasPrefix(name, "get_") || strings.HasPrefix(name, "list_")
+}
+
+// permits reports whether the tool may be registered
+func (f ToolFilter) permits(name string) bool {
+	if f.ReadOnly && !isReadOnlyTool(name) {
+		return false
+	}
+	if matchesAny(name, f.Deny) {
+		return false
+	}
+	return len(f.Allow) == 0 || matchesAny(name, f.Allow)
+}
+
+// matchesAny reports whether name matches one of the glob patterns
+func matchesAny(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
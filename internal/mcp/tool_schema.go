@@ -0,0 +1,139 @@
+package mcp
+
+import (
+	"encoding/json"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// idempotentTools lists the mutating tools that have no further effect when
+// called again with the same arguments
+var idempotentTools = map[string]bool{
+	"start_vm":                true,
+	"stop_vm":                 true,
+	"shutdown_vm":             true,
+	"suspend_vm":              true,
+	"resume_vm":               true,
+	"update_vm_config":        true,
+	"set_vm_disk_options":     true,
+	"set_vm_cloudinit":        true,
+	"regenerate_vm_cloudinit": true,
+	"start_container":         true,
+	"stop_container":          true,
+	"shutdown_container":      true,
+	"update_container_config": true,
+	"update_user":             true,
+	"change_password":         true,
+	"set_acl":                 true,
+	"update_storage":          true,
+	"update_node_config":      true,
+	"update_pool":             true,
+	"cancel_task":             true,
+	"disable_ha_resource":     true,
+}
+
+// openWorldTools lists the tools that reach beyond the Proxmox cluster
+var openWorldTools = map[string]bool{
+	// Refreshes the package index from the configured repositories
+	"apply_node_updates": true,
+	// Downloads the image from the given URL
+	"create_template_from_image": true,
+}
+
+// toolAnnotations returns the behaviour hints of a tool. Read-only and
+// destructive tools are recognised as in isReadOnlyTool and
+// destructiveTools.
+func toolAnnotations(name string) mcp.ToolAnnotation {
+	readOnly := isReadOnlyTool(name)
+	return mcp.ToolAnnotation{
+		ReadOnlyHint:    mcp.ToBoolPtr(readOnly),
+		DestructiveHint: mcp.ToBoolPtr(isDestructiveTool(name)),
+		IdempotentHint:  mcp.ToBoolPtr(readOnly || idempotentTools[name]),
+		OpenWorldHint:   mcp.ToBoolPtr(openWorldTools[name]),
+	}
+}
+
+// toolSchema builds the MCP tool of a definition. Besides its own arguments
+// every tool accepts an optional cluster argument selecting the Proxmox client,
+// destructive tools a confirmation token when enabled and mutating tools
+// dry_run.
+func (s *Server) toolSchema(def ToolDefinition) mcp.Tool {
+	properties := def.Tool.schema.Properties()
+	if s.options.ConfirmDestructive && isDestructiveTool(def.Name) {
+		properties = withConfirmToken(properties)
+	}
+	if !isReadOnlyTool(def.Name) {
+		properties = withDryRunArg(properties)
+	}
+	tool := mcp.Tool{
+		Name:        def.Name,
+		Description: def.Description,
+		InputSchema: mcp.ToolInputSchema{
+			Type:       "object",
+			Properties: withClusterArg(properties),
+			Required:   def.Tool.schema.Required(),
+		},
+		Annotations: toolAnnotations(def.Name),
+	}
+	for _, option := range def.Tool.options {
+		option(&tool)
+	}
+	return tool
+}
+
+// toolDocument is an entry of docs/tools-schema.json
+type toolDocument struct {
+	Name         string                `json:"name"`
+	Description  string                `json:"description"`
+	Category     ToolCategory          `json:"category"`
+	ReadOnly     bool                  `json:"read_only"`
+	Destructive  bool                  `json:"destructive"`
+	Annotations  mcp.ToolAnnotation    `json:"annotations"`
+	InputSchema  mcp.ToolInputSchema   `json:"inputSchema"`
+	OutputSchema *mcp.ToolOutputSchema `json:"outputSchema,omitempty"`
+}
+
+// ToolsSchema returns the JSON document describing every tool with its
+// annotations and input and output schemas, as published in docs/tools-schema.json. The schemas are those of a
+// server with confirmation of destructive tools enabled.
+func ToolsSchema() ([]byte, error) {
+	s := &Server{options: Options{ConfirmDestructive: true}}
+
+	var tools []toolDocument
+	summary := map[string]int{}
+	for _, def := range s.toolDefinitions() {
+		tool := s.toolSchema(def)
+		doc := toolDocument{
+			Name:        def.Name,
+			Description: def.Description,
+			Category:    def.Category,
+			ReadOnly:    isReadOnlyTool(def.Name),
+			Destructive: isDestructiveTool(def.Name),
+			Annotations: tool.Annotations,
+			InputSchema: tool.InputSchema,
+		}
+		if tool.OutputSchema.Type != "" {
+			doc.OutputSchema = &tool.OutputSchema
+		}
+		tools = append(tools, doc)
+		summary["total_tools"]++
+		summary[string(def.Category)+"_tools"]++
+		if isReadOnlyTool(def.Name) {
+			summary["read_only_tools"]++
+		}
+		if isDestructiveTool(def.Name) {
+			summary["destructive_tools"]++
+		}
+	}
+
+	data, err := json.MarshalIndent(map[string]interface{}{
+		"version":     "1.0",
+		"description": "Proxmox VE MCP Server - Tool Definitions (generated by proxmox-ve-mcp -tools-schema)",
+		"tools":       tools,
+		"summary":     summary,
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,245 @@
+package proxmox
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// ticketLifetime is how long Proxmox accepts a ticket
+	ticketLifetime = 2 * time.Hour
+	// ticketRenewAfter is the ticket age at which it is renewed, leaving
+	// headroom before the 2-hour expiry
+	ticketRenewAfter = 90 * time.Minute
+)
+
+// Authenticator adds credentials to Proxmox API requests
+type Authenticator interface {
+	// Authenticate sets the credentials of req
+	Authenticate(ctx context.Context, req *http.Request) error
+}
+
+// invalidator is implemented by authenticators whose credentials can be
+// refreshed after the API rejected them
+type invalidator interface {
+	Invalidate()
+}
+
+// TokenAuth authenticates with an API token (user@realm!tokenid=secret)
+type TokenAuth struct {
+	token string
+}
+
+// NewTokenAuth creates an authenticator for an API token
+func NewTokenAuth(token string) *TokenAuth {
+	return &TokenAuth{token: token}
+}
+
+// Authenticate implements Authenticator
+func (a *TokenAuth) Authenticate(_ context.Context, req *http.Request) error {
+	req.Header.Set("Authorization", fmt.Sprintf("PVEAPIToken=%s", a.token))
+	return nil
+}
+
+// TicketAuth authenticates with a username and password (plus an optional
+// TOTP second factor) through access/ticket. Tickets are renewed before they
+// expire and write requests carry the CSRFPreventionToken.
+type TicketAuth struct {
+	endpoints  *endpointPool
+	username   string
+	password   string
+	totpSecret string
+	httpClient *http.Client
+
+	mu       sync.Mutex
+	ticket   string
+	csrf     string
+	issuedAt time.Time
+}
+
+// NewTicketAuth creates a ticket authenticator. totpSecret is the base32
+// secret of a TOTP second factor and may be empty.
+func NewTicketAuth(baseURL, username, password, totpSecret string, skipSSLVerify bool) *TicketAuth {
+	return &TicketAuth{
+		endpoints:  newEndpointPool([]string{baseURL}),
+		username:   username,
+		password:   password,
+		totpSecret: totpSecret,
+		httpClient: newHTTPClient(skipSSLVerify),
+	}
+}
+
+// Authenticate implements Authenticator
+func (a *TicketAuth) Authenticate(ctx context.Context, req *http.Request) error {
+	ticket, csrf, err := a.Ticket(ctx)
+	if err != nil {
+		return err
+	}
+	req.AddCookie(&http.Cookie{Name: "PVEAuthCookie", Value: ticket})
+	if req.Method != http.MethodGet {
+		req.Header.Set("CSRFPreventionToken", csrf)
+	}
+	return nil
+}
+
+// Ticket returns a valid ticket and CSRF prevention token, logging in or
+// renewing the ticket as needed. Callers such as console websockets can use
+// the ticket directly.
+func (a *TicketAuth) Ticket(ctx context.Context) (string, string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	age := time.Since(a.issuedAt)
+	if a.ticket != "" && age < ticketRenewAfter {
+		return a.ticket, a.csrf, nil
+	}
+
+	// A still-valid ticket can be exchanged for a new one without the password
+	// or second factor
+	if a.ticket != "" && age < ticketLifetime-time.Minute {
+		if err := a.login(ctx, url.Values{"username": {a.username}, "password": {a.ticket}}); err == nil {
+			return a.ticket, a.csrf, nil
+		}
+	}
+
+	if err := a.login(ctx, url.Values{"username": {a.username}, "password": {a.password}, "new-format": {"1"}}); err != nil {
+		a.ticket = ""
+		return "", "", err
+	}
+	return a.ticket, a.csrf, nil
+}
+
+// useEndpoints implements endpointUser so logins fail over with the client
+func (a *TicketAuth) useEndpoints(pool *endpointPool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.endpoints = pool
+}
+
+// Invalidate discards the current ticket so the next request logs in again
+func (a *TicketAuth) Invalidate() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.ticket = ""
+}
+
+// ticketResponse is the data returned by access/ticket
+type ticketResponse struct {
+	Ticket              string `json:"ticket"`
+	CSRFPreventionToken string `json:"CSRFPreventionToken"`
+	NeedTFA             int    `json:"NeedTFA"`
+}
+
+// login requests a ticket and completes a TOTP challenge if one is issued
+func (a *TicketAuth) login(ctx context.Context, form url.Values) error {
+	resp, err := a.requestTicket(ctx, form)
+	if err != nil {
+		return err
+	}
+
+	if resp.NeedTFA != 0 {
+		if a.totpSecret == "" {
+			return fmt.Errorf("login for %s requires a second factor but no TOTP secret is configured", a.username)
+		}
+		code, err := totpCode(a.totpSecret, time.Now())
+		if err != nil {
+			return err
+		}
+		resp, err = a.requestTicket(ctx, url.Values{
+			"username":      {a.username},
+			"password":      {"totp:" + code},
+			"tfa-challenge": {resp.Ticket},
+			"new-format":    {"1"},
+		})
+		if err != nil {
+			return fmt.Errorf("TOTP verification failed: %w", err)
+		}
+		if resp.NeedTFA != 0 {
+			return fmt.Errorf("TOTP verification failed for %s", a.username)
+		}
+	}
+
+	a.ticket = resp.Ticket
+	a.csrf = resp.CSRFPreventionToken
+	a.issuedAt = time.Now()
+	return nil
+}
+
+// requestTicket posts to access/ticket, trying every cluster member that can
+// be reached
+func (a *TicketAuth) requestTicket(ctx context.Context, form url.Values) (*ticketResponse, error) {
+	var resp *ticketResponse
+	err := a.endpoints.do(func(baseURL string) error {
+		var err error
+		resp, err = a.requestTicketFrom(ctx, baseURL, form)
+		return err
+	}, func(error) bool {
+		return ctx.Err() == nil
+	})
+	return resp, err
+}
+
+func (a *TicketAuth) requestTicketFrom(ctx context.Context, baseURL string, form url.Values) (*ticketResponse, error) {
+	endpoint := "access/ticket"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/api2/json/%s", baseURL, endpoint), strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create login request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("login request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read login response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, newAPIError(http.MethodPost, endpoint, resp, body)
+	}
+
+	var payload struct {
+		Data *ticketResponse `json:"data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("failed to parse login response: %w", err)
+	}
+	if payload.Data == nil || payload.Data.Ticket == "" {
+		return nil, fmt.Errorf("login for %s failed: no ticket returned", a.username)
+	}
+	return payload.Data, nil
+}
+
+// totpCode computes the RFC 6238 code (SHA-1, 6 digits, 30 s step) for a
+// base32 secret
+func totpCode(secret string, now time.Time) (string, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(secret), " ", ""))
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.TrimRight(secret, "="))
+	if err != nil {
+		return "", fmt.Errorf("invalid TOTP secret: %w", err)
+	}
+
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(now.Unix()/30))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", value%1000000), nil
+}
@@ -8,6 +8,7 @@ import (
 // Backup represents a backup file
 type Backup struct {
 	BackupID  string `json:"id,omitempty"`
+	VolID     string `json:"volid,omitempty"`
 	Name      string `json:"name,omitempty"`
 	VMID      int    `json:"vmid,omitempty"`
 	Size      int64  `json:"size,omitempty"`
@@ -21,32 +22,22 @@ type Backup struct {
 
 // CreateVMBackup creates a backup of a virtual machine
 func (c *Client) CreateVMBackup(ctx context.Context, nodeName string, vmID int, storage, backupID, notes string) (interface{}, error) {
-	body := map[string]interface{}{
-		"storage": storage,
-		"vmid":    vmID,
-	}
-	if backupID != "" {
-		body["id"] = backupID
-	}
-	if notes != "" {
-		body["notes"] = notes
-	}
+	body := params{}.
+		set("storage", storage).
+		set("vmid", vmID).
+		opt("id", backupID).
+		opt("notes", notes)
 
 	return c.doRequest(ctx, "POST", fmt.Sprintf("nodes/%s/qemu/%d/backup", nodeName, vmID), body)
 }
 
 // CreateContainerBackup creates a backup of a container
 func (c *Client) CreateContainerBackup(ctx context.Context, nodeName string, containerID int, storage, backupID, notes string) (interface{}, error) {
-	body := map[string]interface{}{
-		"storage": storage,
-		"vmid":    containerID,
-	}
-	if backupID != "" {
-		body["id"] = backupID
-	}
-	if notes != "" {
-		body["notes"] = notes
-	}
+	body := params{}.
+		set("storage", storage).
+		set("vmid", containerID).
+		opt("id", backupID).
+		opt("notes", notes)
 
 	return c.doRequest(ctx, "POST", fmt.Sprintf("nodes/%s/lxc/%d/backup", nodeName, containerID), body)
 }
@@ -59,7 +50,7 @@ func (c *Client) ListBackups(ctx context.Context, storage string) ([]Backup, err
 		return nil, fmt.Errorf("failed to get nodes: %v", err)
 	}
 
-	var allBackups []Backup
+	allBackups := []Backup{}
 
 	// Try to get backups from each node's storage
 	for _, node := range nodes {
@@ -82,6 +73,34 @@ func (c *Client) ListBackups(ctx context.Context, storage string) ([]Backup, err
 	return allBackups, nil
 }
 
+// FindGuestBackups returns the backups of a guest on the backup storages
+// visible from a node
+func (c *Client) FindGuestBackups(ctx context.Context, nodeName string, vmID int) ([]Backup, error) {
+	storages, err := c.doRequest(ctx, "GET", fmt.Sprintf("nodes/%s/storage", nodeName), params{}.set("content", "backup"))
+	if err != nil {
+		return nil, err
+	}
+	var list []Storage
+	if err := c.unmarshalData(storages, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse storage list: %w", err)
+	}
+
+	var backups []Backup
+	for _, storage := range list {
+		data, err := c.doRequest(ctx, "GET", fmt.Sprintf("nodes/%s/storage/%s/content", nodeName, storage.Storage),
+			params{}.set("content", "backup").set("vmid", vmID))
+		if err != nil {
+			return nil, err
+		}
+		found := []Backup{}
+		if err := c.unmarshalData(data, &found); err != nil {
+			return nil, fmt.Errorf("failed to parse backups of storage %s: %w", storage.Storage, err)
+		}
+		backups = append(backups, found...)
+	}
+	return backups, nil
+}
+
 // DeleteBackup removes a backup file from a specific node's storage
 func (c *Client) DeleteBackup(ctx context.Context, storage, backupID string) (interface{}, error) {
 	// Get all nodes to find which one has the backup
@@ -98,6 +117,9 @@ func (c *Client) DeleteBackup(ctx context.Context, storage, backupID string) (in
 		if err == nil {
 			return result, nil
 		}
+		if IsPermissionDenied(err) {
+			return nil, err
+		}
 		lastErr = err
 	}
 
@@ -109,20 +131,18 @@ func (c *Client) DeleteBackup(ctx context.Context, storage, backupID string) (in
 
 // RestoreVMBackup restores a VM from a backup
 func (c *Client) RestoreVMBackup(ctx context.Context, nodeName string, backupID, storage string) (interface{}, error) {
-	body := map[string]interface{}{
-		"archive": backupID,
-		"storage": storage,
-	}
+	body := params{}.
+		set("archive", backupID).
+		opt("storage", storage)
 
 	return c.doRequest(ctx, "POST", fmt.Sprintf("nodes/%s/qemu", nodeName), body)
 }
 
 // RestoreContainerBackup restores a container from a backup
 func (c *Client) RestoreContainerBackup(ctx context.Context, nodeName string, backupID, storage string) (interface{}, error) {
-	body := map[string]interface{}{
-		"archive": backupID,
-		"storage": storage,
-	}
+	body := params{}.
+		set("archive", backupID).
+		opt("storage", storage)
 
 	return c.doRequest(ctx, "POST", fmt.Sprintf("nodes/%s/lxc", nodeName), body)
 }
@@ -1,7 +1,6 @@
 package proxmox
 
 import (
-	"bytes"
 	"context"
 	"crypto/tls"
 	"encoding/json"
@@ -9,21 +8,49 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	// defaultBreakerThreshold is the number of consecutive failures that opens a node's circuit
+	defaultBreakerThreshold = 5
+	// defaultBreakerCooldown is how long an open circuit rejects requests
+	defaultBreakerCooldown = 30 * time.Second
+)
+
 // Client handles communication with Proxmox VE API
 type Client struct {
-	baseURL    string
-	apiToken   string
+	endpoints  *endpointPool
+	auth       Authenticator
 	httpClient *http.Client
 	logger     *logrus.Entry
+	retry      RetryPolicy
+	breakers   *circuitBreakers
 }
 
-// NewClient creates a new Proxmox VE API client
+// NewClient creates a new Proxmox VE API client authenticating with an API token
 func NewClient(baseURL, apiToken string, skipSSLVerify bool) *Client {
+	return NewClientWithAuth(baseURL, NewTokenAuth(apiToken), skipSSLVerify)
+}
+
+// NewClientWithAuth creates a new Proxmox VE API client using auth for credentials
+func NewClientWithAuth(baseURL string, auth Authenticator, skipSSLVerify bool) *Client {
+	c := &Client{
+		auth:       auth,
+		httpClient: newHTTPClient(skipSSLVerify),
+		logger:     logrus.WithField("component", "ProxmoxClient"),
+		retry:      DefaultRetryPolicy(),
+		breakers:   newCircuitBreakers(defaultBreakerThreshold, defaultBreakerCooldown),
+	}
+	c.SetEndpoints([]string{baseURL})
+	return c
+}
+
+// newHTTPClient creates the HTTP client used to talk to the Proxmox API
+func newHTTPClient(skipSSLVerify bool) *http.Client {
 	var tlsConfig *tls.Config
 	if skipSSLVerify {
 		// Disable SSL verification for self-signed certificates
@@ -32,49 +59,113 @@ func NewClient(baseURL, apiToken string, skipSSLVerify bool) *Client {
 		}
 	}
 
-	httpClient := &http.Client{
+	return &http.Client{
 		Timeout: 30 * time.Second,
 		Transport: &http.Transport{
 			TLSClientConfig: tlsConfig,
 		},
 	}
+}
 
-	return &Client{
-		baseURL:    baseURL,
-		apiToken:   apiToken,
-		httpClient: httpClient,
-		logger:     logrus.WithField("component", "ProxmoxClient"),
+// SetRetryPolicy replaces the retry policy of the client
+func (c *Client) SetRetryPolicy(policy RetryPolicy) {
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
 	}
+	c.retry = policy
+}
+
+// SetCircuitBreaker configures the per-node circuit breaker: after threshold
+// consecutive failures to reach a node, requests to it fail fast for cooldown.
+// A threshold of 0 disables the breaker.
+func (c *Client) SetCircuitBreaker(threshold int, cooldown time.Duration) {
+	c.breakers = newCircuitBreakers(threshold, cooldown)
 }
 
-// doRequest performs an HTTP request to the Proxmox API
+// doRequest performs an HTTP request to the Proxmox API, retrying failures
+// according to the client's retry policy, and returns the response data
 func (c *Client) doRequest(ctx context.Context, method, endpoint string, body interface{}) (interface{}, error) {
-	urlStr := fmt.Sprintf("%s/api2/json/%s", c.baseURL, endpoint)
+	resp, err := c.doRequestResponse(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
 
-	var reqBody io.Reader
-	var contentType string
+// doRequestResponse is doRequest returning the whole response, for paged
+// lists that report their total
+func (c *Client) doRequestResponse(ctx context.Context, method, endpoint string, body interface{}) (*APIResponse, error) {
+	values, err := encodeForm(body)
+	if err != nil {
+		return nil, err
+	}
+	if dryRun := dryRunFromContext(ctx); dryRun != nil && method != "GET" {
+		dryRun.record(method, endpoint, values)
+		return &APIResponse{}, nil
+	}
+
+	node := endpointNode(endpoint)
+	for attempt := 1; ; attempt++ {
+		if err := c.breakers.allow(node); err != nil {
+			return nil, err
+		}
+
+		resp, err := c.sendFailover(ctx, method, endpoint, values)
+		if inv, ok := c.auth.(invalidator); ok && isUnauthorized(err) {
+			// The session may have been revoked; log in again once
+			inv.Invalidate()
+			resp, err = c.sendFailover(ctx, method, endpoint, values)
+		}
+		c.breakers.record(node, err)
+		if err == nil || attempt >= c.retry.MaxAttempts || !c.retry.shouldRetry(method, err) {
+			return resp, err
+		}
 
-	// Handle query parameters for GET requests
-	if method == "GET" && body != nil {
-		// For GET requests, convert body to query parameters
-		if params, ok := body.(map[string]interface{}); ok {
-			q := url.Values{}
-			for key, value := range params {
-				q.Set(key, fmt.Sprintf("%v", value))
-			}
-			urlStr = urlStr + "?" + q.Encode()
-			body = nil // No body for GET requests with query params
+		delay := c.retry.backoff(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return nil, err
+		}
+		c.logger.WithError(err).Debugf("Retrying %s %s in %s (attempt %d of %d)", method, endpoint, delay, attempt+1, c.retry.MaxAttempts)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
 		}
 	}
+}
+
+// sendFailover sends a request to the preferred API endpoint, failing over to
+// the other cluster members when it cannot be reached
+func (c *Client) sendFailover(ctx context.Context, method, endpoint string, values url.Values) (*APIResponse, error) {
+	var resp *APIResponse
+	err := c.endpoints.do(func(baseURL string) error {
+		var err error
+		resp, err = c.send(ctx, baseURL, method, endpoint, values)
+		return err
+	}, func(err error) bool {
+		return ctx.Err() == nil && canFailover(method, err)
+	})
+	return resp, err
+}
 
-	// Handle JSON body for POST/PUT requests
-	if body != nil {
-		jsonBody, err := json.Marshal(body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+// send performs a single HTTP request to the Proxmox API at baseURL
+func (c *Client) send(ctx context.Context, baseURL, method, endpoint string, values url.Values) (*APIResponse, error) {
+	urlStr := fmt.Sprintf("%s/api2/json/%s", baseURL, endpoint)
+
+	// GET and DELETE carry their parameters in the query string, POST and PUT
+	// as a form-encoded body
+	var reqBody io.Reader
+	var contentType string
+	if len(values) > 0 {
+		if method == "GET" || method == "DELETE" {
+			urlStr = urlStr + "?" + values.Encode()
+		} else {
+			reqBody = strings.NewReader(values.Encode())
+			contentType = "application/x-www-form-urlencoded"
 		}
-		reqBody = bytes.NewReader(jsonBody)
-		contentType = "application/json"
 	}
 
 	req, err := http.NewRequestWithContext(ctx, method, urlStr, reqBody)
@@ -82,8 +173,9 @@ func (c *Client) doRequest(ctx context.Context, method, endpoint string, body in
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	// Set authentication header
-	req.Header.Set("Authorization", fmt.Sprintf("PVEAPIToken=%s", c.apiToken))
+	if err := c.auth.Authenticate(ctx, req); err != nil {
+		return nil, fmt.Errorf("authentication failed: %w", err)
+	}
 	if contentType != "" {
 		req.Header.Set("Content-Type", contentType)
 	}
@@ -100,7 +192,7 @@ func (c *Client) doRequest(ctx context.Context, method, endpoint string, body in
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(respBody))
+		return nil, newAPIError(method, endpoint, resp, respBody)
 	}
 
 	var apiResp APIResponse
@@ -108,5 +200,5 @@ func (c *Client) doRequest(ctx context.Context, method, endpoint string, body in
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
-	return apiResp.Data, nil
+	return &apiResp, nil
 }
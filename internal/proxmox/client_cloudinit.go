@@ -0,0 +1,171 @@
+package proxmox
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CloudInitConfig holds the cloud-init settings of a VM. SetVMCloudInit
+// leaves empty fields unchanged; list settings in Delete to remove them.
+type CloudInitConfig struct {
+	User         string         `json:"ciuser,omitempty"`
+	Password     string         `json:"cipassword,omitempty"`
+	SSHKeys      []string       `json:"sshkeys,omitempty"`      // public keys, one per entry
+	IPConfig     map[int]string `json:"ipconfig,omitempty"`     // ipconfigN by N, e.g. {0: "ip=dhcp"}
+	Nameserver   string         `json:"nameserver,omitempty"`   // space-separated addresses
+	SearchDomain string         `json:"searchdomain,omitempty"` // space-separated domains
+	Custom       string         `json:"cicustom,omitempty"`     // snippets, e.g. "user=local:snippets/user.yaml"
+	Type         string         `json:"citype,omitempty"`       // nocloud, configdrive2 or opennebula
+	Delete       []string       `json:"-"`                      // settings to remove, e.g. "sshkeys"
+}
+
+// cloudInitSections are the sections of the generated cloud-init data
+var cloudInitSections = []string{"user", "network", "meta"}
+
+// params returns the VM config parameters setting c
+func (c CloudInitConfig) params() params {
+	p := params{}.
+		opt("ciuser", c.User).
+		opt("cipassword", c.Password).
+		opt("nameserver", c.Nameserver).
+		opt("searchdomain", c.SearchDomain).
+		opt("cicustom", c.Custom).
+		opt("citype", c.Type).
+		opt("delete", c.Delete)
+	if len(c.SSHKeys) > 0 {
+		p.set("sshkeys", encodeSSHKeys(c.SSHKeys))
+	}
+	for n, ipconfig := range c.IPConfig {
+		p.opt(fmt.Sprintf("ipconfig%d", n), ipconfig)
+	}
+	return p
+}
+
+// encodeSSHKeys encodes public keys for the sshkeys setting. Proxmox expects
+// the newline-separated keys percent-encoded on top of the form encoding,
+// with spaces as %20 since it does not decode "+".
+func encodeSSHKeys(keys []string) string {
+	var lines []string
+	for _, key := range keys {
+		for _, line := range strings.Split(key, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				lines = append(lines, line)
+			}
+		}
+	}
+	return strings.ReplaceAll(url.QueryEscape(strings.Join(lines, "\n")), "+", "%20")
+}
+
+// decodeSSHKeys reverses encodeSSHKeys
+func decodeSSHKeys(encoded string) []string {
+	decoded, err := url.PathUnescape(encoded)
+	if err != nil {
+		decoded = encoded
+	}
+	var keys []string
+	for _, line := range strings.Split(decoded, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			keys = append(keys, line)
+		}
+	}
+	return keys
+}
+
+// GetVMCloudInit retrieves the cloud-init settings of a VM from its
+// configuration, with the SSH keys decoded. Proxmox masks the password.
+func (c *Client) GetVMCloudInit(ctx context.Context, nodeName string, vmID int) (*CloudInitConfig, error) {
+	config, err := c.GetVMConfig(ctx, nodeName, vmID)
+	if err != nil {
+		return nil, err
+	}
+
+	str := func(key string) string {
+		value, _ := config[key].(string)
+		return value
+	}
+	ci := &CloudInitConfig{
+		User:         str("ciuser"),
+		Password:     str("cipassword"),
+		SSHKeys:      decodeSSHKeys(str("sshkeys")),
+		Nameserver:   str("nameserver"),
+		SearchDomain: str("searchdomain"),
+		Custom:       str("cicustom"),
+		Type:         str("citype"),
+	}
+	for key, value := range config {
+		n, err := strconv.Atoi(strings.TrimPrefix(key, "ipconfig"))
+		if !strings.HasPrefix(key, "ipconfig") || err != nil {
+			continue
+		}
+		if ci.IPConfig == nil {
+			ci.IPConfig = make(map[int]string)
+		}
+		ci.IPConfig[n] = fmt.Sprint(value)
+	}
+	return ci, nil
+}
+
+// SetVMCloudInit updates the cloud-init settings of a VM. The cloud-init
+// drive picks up the change when the VM starts next, or when it is
+// regenerated with RegenerateVMCloudInit.
+func (c *Client) SetVMCloudInit(ctx context.Context, nodeName string, vmID int, config CloudInitConfig) (interface{}, error) {
+	body := config.params()
+	if len(body) == 0 {
+		return nil, fmt.Errorf("no cloud-init settings to change")
+	}
+	return c.doRequest(ctx, "PUT", fmt.Sprintf("nodes/%s/qemu/%d/config", nodeName, vmID), body)
+}
+
+// DumpVMCloudInit returns the cloud-init data generated for a VM. section
+// is user, network or meta.
+func (c *Client) DumpVMCloudInit(ctx context.Context, nodeName string, vmID int, section string) (string, error) {
+	data, err := c.doRequest(ctx, "GET", fmt.Sprintf("nodes/%s/qemu/%d/cloudinit/dump", nodeName, vmID), params{}.set("type", section))
+	if err != nil {
+		return "", err
+	}
+	text, ok := data.(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected cloud-init %s data format", section)
+	}
+	return text, nil
+}
+
+// DumpVMCloudInitAll returns the generated user, network and meta data of a
+// VM by section
+func (c *Client) DumpVMCloudInitAll(ctx context.Context, nodeName string, vmID int) (map[string]string, error) {
+	dump := make(map[string]string, len(cloudInitSections))
+	for _, section := range cloudInitSections {
+		text, err := c.DumpVMCloudInit(ctx, nodeName, vmID, section)
+		if err != nil {
+			return nil, err
+		}
+		dump[section] = text
+	}
+	return dump, nil
+}
+
+// GetVMCloudInitPending lists the cloud-init settings of a VM with the
+// values pending until the drive is regenerated
+func (c *Client) GetVMCloudInitPending(ctx context.Context, nodeName string, vmID int) ([]map[string]interface{}, error) {
+	data, err := c.doRequest(ctx, "GET", fmt.Sprintf("nodes/%s/qemu/%d/cloudinit", nodeName, vmID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := []map[string]interface{}{}
+	if err := c.unmarshalData(data, &pending); err != nil {
+		return nil, fmt.Errorf("failed to parse cloud-init settings: %w", err)
+	}
+	sort.Slice(pending, func(i, j int) bool { return fmt.Sprint(pending[i]["key"]) < fmt.Sprint(pending[j]["key"]) })
+	return pending, nil
+}
+
+// RegenerateVMCloudInit rebuilds the cloud-init drive of a VM from its
+// current settings
+func (c *Client) RegenerateVMCloudInit(ctx context.Context, nodeName string, vmID int) (interface{}, error) {
+	return c.doRequest(ctx, "PUT", fmt.Sprintf("nodes/%s/qemu/%d/cloudinit", nodeName, vmID), nil)
+}
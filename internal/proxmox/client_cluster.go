@@ -101,3 +101,25 @@ func (c *Client) GetClusterResources(ctx context.Context) (interface{}, error) {
 
 	return data, nil
 }
+
+// GetClusterResourcesOfType lists the cluster/resources entries of one type,
+// e.g. node or storage. Guests are listed by GetGuests.
+func (c *Client) GetClusterResourcesOfType(ctx context.Context, resourceType string) ([]map[string]interface{}, error) {
+	data, err := c.doRequest(ctx, "GET", "cluster/resources", params{}.set("type", resourceType))
+	if err != nil {
+		return nil, err
+	}
+
+	var all []map[string]interface{}
+	if err := c.unmarshalData(data, &all); err != nil {
+		return nil, fmt.Errorf("failed to parse cluster resources: %w", err)
+	}
+
+	resources := []map[string]interface{}{}
+	for _, resource := range all {
+		if resource["type"] == resourceType {
+			resources = append(resources, resource)
+		}
+	}
+	return resources, nil
+}
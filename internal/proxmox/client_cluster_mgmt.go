@@ -39,15 +39,10 @@ func (c *Client) GetHAStatus(ctx context.Context) (interface{}, error) {
 
 // EnableHAResource enables High Availability for a resource
 func (c *Client) EnableHAResource(ctx context.Context, sid, comment string, state string) (interface{}, error) {
-	body := map[string]interface{}{
-		"sid": sid,
-	}
-	if comment != "" {
-		body["comment"] = comment
-	}
-	if state != "" {
-		body["state"] = state
-	}
+	body := params{}.
+		set("sid", sid).
+		opt("comment", comment).
+		opt("state", state)
 
 	return c.doRequest(ctx, "POST", "cluster/ha/resources", body)
 }
@@ -59,15 +54,10 @@ func (c *Client) DisableHAResource(ctx context.Context, sid string) (interface{}
 
 // AddNodeToCluster adds a node to the cluster
 func (c *Client) AddNodeToCluster(ctx context.Context, nodeName, clusterName, clusterNetwork string) (interface{}, error) {
-	body := map[string]interface{}{
-		"nodeid": nodeName,
-	}
-	if clusterName != "" {
-		body["clustername"] = clusterName
-	}
-	if clusterNetwork != "" {
-		body["clusternetwork"] = clusterNetwork
-	}
+	body := params{}.
+		set("nodeid", nodeName).
+		opt("clustername", clusterName).
+		opt("clusternetwork", clusterNetwork)
 
 	return c.doRequest(ctx, "POST", "cluster/nodes", body)
 }
@@ -78,33 +78,29 @@ func (c *Client) CreateContainer(ctx context.Context, nodeName string, config ma
 
 // CreateContainerFull creates a new LXC container with full configuration
 func (c *Client) CreateContainerFull(ctx context.Context, nodeName string, containerID int, hostname string, storage string, memory int, cores int, ostype string) (interface{}, error) {
-	config := map[string]interface{}{
-		"vmid":     containerID,
-		"hostname": hostname,
-		"storage":  storage,
-		"memory":   memory,
-		"cores":    cores,
-		"ostype":   ostype,
-	}
+	config := params{}.
+		set("vmid", containerID).
+		set("hostname", hostname).
+		set("storage", storage).
+		opt("memory", memory).
+		opt("cores", cores).
+		opt("ostype", ostype)
 	return c.CreateContainer(ctx, nodeName, config)
 }
 
 // DeleteContainer deletes an LXC container
 func (c *Client) DeleteContainer(ctx context.Context, nodeName string, containerID int, force bool) (interface{}, error) {
-	data := map[string]interface{}{
-		"force": force,
-	}
-	return c.doRequest(ctx, "DELETE", fmt.Sprintf("nodes/%s/lxc/%d", nodeName, containerID), data)
+	body := params{}.opt("force", force)
+	return c.doRequest(ctx, "DELETE", fmt.Sprintf("nodes/%s/lxc/%d", nodeName, containerID), body)
 }
 
 // CloneContainer clones an existing LXC container
 func (c *Client) CloneContainer(ctx context.Context, nodeName string, sourceContainerID int, newContainerID int, newHostname string, full bool) (interface{}, error) {
-	config := map[string]interface{}{
-		"newid":    newContainerID,
-		"hostname": newHostname,
-		"full":     full,
-	}
-	return c.doRequest(ctx, "POST", fmt.Sprintf("nodes/%s/lxc/%d/clone", nodeName, sourceContainerID), config)
+	body := params{}.
+		set("newid", newContainerID).
+		opt("hostname", newHostname).
+		set("full", full)
+	return c.doRequest(ctx, "POST", fmt.Sprintf("nodes/%s/lxc/%d/clone", nodeName, sourceContainerID), body)
 }
 
 // UpdateContainer updates a container's configuration
@@ -0,0 +1,93 @@
+package proxmox
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// diskSizeArg matches the size argument of a resize: an absolute size or, with
+// a leading +, an increment, in bytes or with a K, M, G or T suffix
+var diskSizeArg = regexp.MustCompile(`^\+?\d+(\.\d+)?[KMGT]?$`)
+
+// GetVMDisks lists the disks, CD/DVD drives and unused volumes of a VM,
+// ordered by bus and slot number
+func (c *Client) GetVMDisks(ctx context.Context, nodeName string, vmID int) ([]Disk, error) {
+	config, err := c.GetParsedVMConfig(ctx, nodeName, vmID)
+	if err != nil {
+		return nil, err
+	}
+	if config.Disks == nil {
+		return []Disk{}, nil
+	}
+	return config.Disks, nil
+}
+
+// GetVMDisk returns the disk of a VM in slot, e.g. scsi0
+func (c *Client) GetVMDisk(ctx context.Context, nodeName string, vmID int, slot string) (*Disk, error) {
+	config, err := c.GetParsedVMConfig(ctx, nodeName, vmID)
+	if err != nil {
+		return nil, err
+	}
+	disk := config.Disk(slot)
+	if disk == nil {
+		return nil, fmt.Errorf("VM %d has no disk %s", vmID, slot)
+	}
+	return disk, nil
+}
+
+// ResizeVMDisk grows a disk of a VM. size is the new size, e.g. "50G", or an
+// increment such as "+10G"; disks cannot shrink.
+func (c *Client) ResizeVMDisk(ctx context.Context, nodeName string, vmID int, disk, size string) (interface{}, error) {
+	if !diskSizeArg.MatchString(size) {
+		return nil, fmt.Errorf("invalid disk size %q, expected e.g. 50G or +10G", size)
+	}
+	body := params{}.set("disk", disk).set("size", size)
+	return c.doRequest(ctx, "PUT", fmt.Sprintf("nodes/%s/qemu/%d/resize", nodeName, vmID), body)
+}
+
+// MoveVMDisk moves a disk of a VM to another storage, converting it to
+// format when set. Unless deleteSource is set, the old volume stays attached
+// as an unused disk.
+func (c *Client) MoveVMDisk(ctx context.Context, nodeName string, vmID int, disk, storage, format string, deleteSource bool) (interface{}, error) {
+	body := params{}.
+		set("disk", disk).
+		set("storage", storage).
+		opt("format", format).
+		opt("delete", deleteSource)
+	return c.doRequest(ctx, "POST", fmt.Sprintf("nodes/%s/qemu/%d/move_disk", nodeName, vmID), body)
+}
+
+// UnlinkVMDisks detaches disks from a VM. Detached volumes are kept as unused
+// disks unless deleteVolumes is set, which destroys them.
+func (c *Client) UnlinkVMDisks(ctx context.Context, nodeName string, vmID int, disks []string, deleteVolumes bool) (interface{}, error) {
+	if len(disks) == 0 {
+		return nil, fmt.Errorf("no disks to detach")
+	}
+	body := params{}.
+		set("idlist", strings.Join(disks, ",")).
+		opt("force", deleteVolumes)
+	return c.doRequest(ctx, "PUT", fmt.Sprintf("nodes/%s/qemu/%d/unlink", nodeName, vmID), body)
+}
+
+// ImportVMDisk copies an existing volume, e.g. an image on a storage with
+// import content or a disk of another VM, to storage and attaches the copy to
+// a VM in slot. format sets the format of the copy when not empty. The slot
+// must be free: Proxmox would otherwise replace the disk in it and keep the
+// old volume only as an unused disk.
+func (c *Client) ImportVMDisk(ctx context.Context, nodeName string, vmID int, slot, source, storage, format string) (interface{}, error) {
+	if !IsDiskSlot(slot) || strings.HasPrefix(slot, "unused") {
+		return nil, fmt.Errorf("%s is not a disk slot", slot)
+	}
+	config, err := c.GetParsedVMConfig(ctx, nodeName, vmID)
+	if err != nil {
+		return nil, err
+	}
+	if existing := config.Disk(slot); existing != nil {
+		return nil, fmt.Errorf("VM %d already has %s in %s; detach it first or choose a free slot", vmID, existing.Volume, slot)
+	}
+	disk := Disk{Volume: storage + ":0", Format: format, Extra: map[string]string{"import-from": source}}
+	body := params{}.set(slot, disk.String()).opt("digest", config.Digest)
+	return c.doRequest(ctx, "POST", fmt.Sprintf("nodes/%s/qemu/%d/config", nodeName, vmID), body)
+}
@@ -0,0 +1,237 @@
+package proxmox
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// PlannedRequest is a write request captured in dry-run mode instead of
+// being sent
+type PlannedRequest struct {
+	Method   string                 `json:"method"`
+	Endpoint string                 `json:"endpoint"`
+	Params   map[string]interface{} `json:"params,omitempty"`
+}
+
+// PlanCheck is the outcome of checking a target of a planned request
+type PlanCheck struct {
+	Check  string `json:"check"`
+	Target string `json:"target"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// DryRun collects the write requests made with a dry-run context
+type DryRun struct {
+	mu       sync.Mutex
+	requests []PlannedRequest
+}
+
+type dryRunKey struct{}
+
+// WithDryRun returns a context in which the client sends GET requests as
+// usual but records POST, PUT and DELETE requests in the returned DryRun
+// instead of sending them. The recorded calls return no data.
+func WithDryRun(ctx context.Context) (context.Context, *DryRun) {
+	dryRun := &DryRun{}
+	return context.WithValue(ctx, dryRunKey{}, dryRun), dryRun
+}
+
+// IsDryRun reports whether ctx was created by WithDryRun
+func IsDryRun(ctx context.Context) bool {
+	_, ok := ctx.Value(dryRunKey{}).(*DryRun)
+	return ok
+}
+
+// Requests returns the recorded write requests in the order they were made
+func (d *DryRun) Requests() []PlannedRequest {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]PlannedRequest(nil), d.requests...)
+}
+
+// sensitiveParams are masked in planned requests
+var sensitiveParams = map[string]bool{"password": true, "cipassword": true, "secret": true}
+
+func (d *DryRun) record(method, endpoint string, values url.Values) {
+	request := PlannedRequest{Method: method, Endpoint: endpoint}
+	if len(values) > 0 {
+		request.Params = make(map[string]interface{}, len(values))
+		for key, vals := range values {
+			switch {
+			case sensitiveParams[key]:
+				request.Params[key] = "********"
+			case len(vals) == 1:
+				request.Params[key] = vals[0]
+			default:
+				request.Params[key] = vals
+			}
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.requests = append(d.requests, request)
+}
+
+// dryRunFromContext returns the DryRun of ctx, or nil
+func dryRunFromContext(ctx context.Context) *DryRun {
+	dryRun, _ := ctx.Value(dryRunKey{}).(*DryRun)
+	return dryRun
+}
+
+var (
+	// guestEndpoint matches endpoints of an existing guest, e.g. nodes/pve1/qemu/100/status/start
+	guestEndpoint = regexp.MustCompile(`^nodes/([^/]+)/(qemu|lxc)/(\d+)(?:/|$)`)
+	// createEndpoint matches the endpoints that create or restore a guest
+	createEndpoint = regexp.MustCompile(`^nodes/([^/]+)/(qemu|lxc)$`)
+	// diskSize matches a new volume in a disk option, e.g. local-lvm:32 (GiB)
+	diskSize = regexp.MustCompile(`^([^:,=]+):(\d+(?:\.\d+)?)(?:,|$)`)
+	// diskOption matches the parameters that define guest disks
+	diskOption = regexp.MustCompile(`^(?:scsi|virtio|sata|ide|efidisk|tpmstate|rootfs|mp)\d*$`)
+)
+
+// CheckPlan resolves the targets of planned requests: the nodes they address
+// must exist and be online, addressed guests must exist, new VMIDs must be
+// free and storages must be active with room for new disks
+func (c *Client) CheckPlan(ctx context.Context, requests []PlannedRequest) []PlanCheck {
+	var checks []PlanCheck
+	seen := map[string]bool{}
+	add := func(check PlanCheck) {
+		key := check.Check + " " + check.Target
+		if !seen[key] {
+			seen[key] = true
+			checks = append(checks, check)
+		}
+	}
+
+	nodes, nodesErr := c.GetNodes(ctx)
+	checkNode := func(name string) {
+		if nodesErr != nil {
+			add(PlanCheck{Check: "node", Target: name, Detail: fmt.Sprintf("could not list nodes: %v", nodesErr)})
+			return
+		}
+		for _, node := range nodes {
+			if node.Node == name {
+				add(PlanCheck{Check: "node", Target: name, OK: node.Status == "online", Detail: "status " + node.Status})
+				return
+			}
+		}
+		add(PlanCheck{Check: "node", Target: name, Detail: "node does not exist"})
+	}
+
+	// Guests created earlier in the plan do not exist yet
+	created := map[string]bool{}
+	for _, request := range requests {
+		node := endpointNode(request.Endpoint)
+		if node != "" {
+			checkNode(node)
+		}
+		if target, ok := request.Params["target"].(string); ok && target != "" {
+			checkNode(target)
+		}
+
+		if match := guestEndpoint.FindStringSubmatch(request.Endpoint); match != nil && !created[match[3]] {
+			target := fmt.Sprintf("%s/%s/%s", match[1], match[2], match[3])
+			status, err := c.doRequest(ctx, "GET", fmt.Sprintf("nodes/%s/%s/%s/status/current", match[1], match[2], match[3]), nil)
+			if err != nil {
+				add(PlanCheck{Check: "guest exists", Target: target, Detail: err.Error()})
+			} else {
+				fields, _ := status.(map[string]interface{})
+				state, _ := fields["status"].(string)
+				add(PlanCheck{Check: "guest exists", Target: target, OK: true, Detail: "status " + state})
+			}
+		}
+
+		newID, _ := request.Params["newid"].(string)
+		if createEndpoint.MatchString(request.Endpoint) {
+			newID, _ = request.Params["vmid"].(string)
+			created[newID] = true
+		}
+		if newID != "" {
+			if _, err := c.doRequest(ctx, "GET", "cluster/nextid", params{}.set("vmid", newID)); err != nil {
+				add(PlanCheck{Check: "vmid free", Target: newID, Detail: err.Error()})
+			} else {
+				add(PlanCheck{Check: "vmid free", Target: newID, OK: true})
+			}
+		}
+
+		if node != "" {
+			needs := storageNeeds(request.Params)
+			storages := make([]string, 0, len(needs))
+			for storage := range needs {
+				storages = append(storages, storage)
+			}
+			sort.Strings(storages)
+			for _, storage := range storages {
+				add(c.checkStorage(ctx, node, storage, needs[storage]))
+			}
+		}
+	}
+	return checks
+}
+
+// storageNeeds returns the storages a request uses and the bytes its new
+// disks need on each
+func storageNeeds(requestParams map[string]interface{}) map[string]int64 {
+	needs := map[string]int64{}
+	for _, key := range []string{"storage", "target-storage", "targetstorage"} {
+		if storage, ok := requestParams[key].(string); ok && storage != "" {
+			needs[storage] += 0
+		}
+	}
+
+	for key, param := range requestParams {
+		value, ok := param.(string)
+		if !ok || !diskOption.MatchString(key) {
+			continue
+		}
+		if match := diskSize.FindStringSubmatch(value); match != nil {
+			gib, _ := strconv.ParseFloat(match[2], 64)
+			needs[match[1]] += int64(gib * (1 << 30))
+		}
+	}
+	return needs
+}
+
+// checkStorage checks that a storage is active on node and has needed bytes free
+func (c *Client) checkStorage(ctx context.Context, node, storage string, needed int64) PlanCheck {
+	check := PlanCheck{Check: "storage", Target: node + "/" + storage}
+	data, err := c.doRequest(ctx, "GET", fmt.Sprintf("nodes/%s/storage/%s/status", node, storage), nil)
+	if err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+
+	var status struct {
+		Active int   `json:"active"`
+		Avail  int64 `json:"avail"`
+		Total  int64 `json:"total"`
+	}
+	if err := c.unmarshalData(data, &status); err != nil {
+		check.Detail = fmt.Sprintf("failed to parse storage status: %v", err)
+		return check
+	}
+
+	var detail []string
+	detail = append(detail, fmt.Sprintf("%.1f GiB free of %.1f GiB", float64(status.Avail)/(1<<30), float64(status.Total)/(1<<30)))
+	check.OK = status.Active == 1
+	if status.Active != 1 {
+		detail = append(detail, "storage is not active")
+	}
+	if needed > 0 {
+		detail = append(detail, fmt.Sprintf("%.1f GiB needed", float64(needed)/(1<<30)))
+		if needed > status.Avail {
+			check.OK = false
+			detail = append(detail, "not enough space")
+		}
+	}
+	check.Detail = strings.Join(detail, ", ")
+	return check
+}
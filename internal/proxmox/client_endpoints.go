@@ -0,0 +1,234 @@
+package proxmox
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// healthCheckTimeout bounds a single endpoint health check
+const healthCheckTimeout = 5 * time.Second
+
+// EndpointStatus reports the health of one API endpoint of a cluster
+type EndpointStatus struct {
+	URL         string    `json:"url"`
+	Healthy     bool      `json:"healthy"`
+	Active      bool      `json:"active"`
+	LastError   string    `json:"last_error,omitempty"`
+	LastChecked time.Time `json:"last_checked,omitempty"`
+}
+
+// endpointPool holds the API URLs of the members of one cluster. Every member
+// proxies nodes/{node}/... requests to the addressed node, so any healthy
+// member can serve any request.
+type endpointPool struct {
+	mu        sync.Mutex
+	endpoints []*endpointState
+	active    int
+	logger    *logrus.Entry
+}
+
+type endpointState struct {
+	url         string
+	healthy     bool
+	lastError   string
+	lastChecked time.Time
+}
+
+func newEndpointPool(baseURLs []string) *endpointPool {
+	pool := &endpointPool{logger: logrus.WithField("component", "ProxmoxClient")}
+	for _, baseURL := range baseURLs {
+		pool.endpoints = append(pool.endpoints, &endpointState{url: strings.TrimRight(baseURL, "/"), healthy: true})
+	}
+	return pool
+}
+
+// primary returns the URL requests are currently sent to
+func (p *endpointPool) primary() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.endpoints[p.active].url
+}
+
+// order returns the endpoint URLs in the order they should be tried: the
+// active endpoint, the other healthy ones, then the unhealthy ones as a last
+// resort
+func (p *endpointPool) order() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	urls := make([]string, 0, len(p.endpoints))
+	var unhealthy []string
+	for i := range p.endpoints {
+		endpoint := p.endpoints[(p.active+i)%len(p.endpoints)]
+		if endpoint.healthy {
+			urls = append(urls, endpoint.url)
+		} else {
+			unhealthy = append(unhealthy, endpoint.url)
+		}
+	}
+	return append(urls, unhealthy...)
+}
+
+// markUp records a successful exchange with an endpoint and makes it the
+// active endpoint if the active one is down
+func (p *endpointPool) markUp(url string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i, endpoint := range p.endpoints {
+		if endpoint.url != url {
+			continue
+		}
+		endpoint.healthy = true
+		endpoint.lastError = ""
+		endpoint.lastChecked = time.Now()
+		if !p.endpoints[p.active].healthy {
+			p.active = i
+		}
+		return
+	}
+}
+
+// markDown records that an endpoint could not be reached
+func (p *endpointPool) markDown(url string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, endpoint := range p.endpoints {
+		if endpoint.url == url {
+			endpoint.healthy = false
+			endpoint.lastError = err.Error()
+			endpoint.lastChecked = time.Now()
+			return
+		}
+	}
+}
+
+// statuses returns the state of every endpoint
+func (p *endpointPool) statuses() []EndpointStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	statuses := make([]EndpointStatus, len(p.endpoints))
+	for i, endpoint := range p.endpoints {
+		statuses[i] = EndpointStatus{
+			URL:         endpoint.url,
+			Healthy:     endpoint.healthy,
+			Active:      i == p.active,
+			LastError:   endpoint.lastError,
+			LastChecked: endpoint.lastChecked,
+		}
+	}
+	return statuses
+}
+
+// do calls fn with each endpoint in order of preference until it succeeds or
+// fails with an error for which failover returns false
+func (p *endpointPool) do(fn func(baseURL string) error, failover func(error) bool) error {
+	var err error
+	for _, baseURL := range p.order() {
+		err = fn(baseURL)
+		if err == nil || !isTransportFailure(err) {
+			// Any HTTP response, even an error, shows the endpoint is up
+			p.markUp(baseURL)
+			return err
+		}
+		p.markDown(baseURL, err)
+		if !failover(err) {
+			return err
+		}
+		p.logger.WithError(err).Warnf("Proxmox endpoint %s unreachable, failing over", baseURL)
+	}
+	return err
+}
+
+// canFailover reports whether a request that failed with err may be resent to
+// another endpoint. Reads fail over on any transport failure; writes only when
+// the connection was never established, as they may otherwise have been applied.
+func canFailover(method string, err error) bool {
+	if method == http.MethodGet {
+		return isTransportFailure(err)
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// endpointUser is implemented by authenticators that talk to the API
+// themselves and should follow the client's endpoints
+type endpointUser interface {
+	useEndpoints(pool *endpointPool)
+}
+
+// SetEndpoints sets the API URLs of the cluster members the client may use.
+// Requests go to the first URL and fail over to the others when it cannot be
+// reached.
+func (c *Client) SetEndpoints(baseURLs []string) {
+	if len(baseURLs) == 0 {
+		return
+	}
+	c.endpoints = newEndpointPool(baseURLs)
+	if user, ok := c.auth.(endpointUser); ok {
+		user.useEndpoints(c.endpoints)
+	}
+}
+
+// BaseURL returns the API URL requests are currently sent to
+func (c *Client) BaseURL() string {
+	return c.endpoints.primary()
+}
+
+// Endpoints returns the health of the client's API endpoints
+func (c *Client) Endpoints() []EndpointStatus {
+	return c.endpoints.statuses()
+}
+
+// CheckEndpoints probes every endpoint with a version request and updates
+// their health
+func (c *Client) CheckEndpoints(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, status := range c.endpoints.statuses() {
+		wg.Add(1)
+		go func(baseURL string) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+			defer cancel()
+
+			_, err := c.send(checkCtx, baseURL, http.MethodGet, "version", nil)
+			if err == nil || !isTransportFailure(err) {
+				c.endpoints.markUp(baseURL)
+				return
+			}
+			if ctx.Err() == nil {
+				c.endpoints.markDown(baseURL, err)
+			}
+		}(status.URL)
+	}
+	wg.Wait()
+}
+
+// StartHealthChecks checks the endpoints every interval until ctx is done.
+// Clients with a single endpoint are not checked.
+func (c *Client) StartHealthChecks(ctx context.Context, interval time.Duration) {
+	if interval <= 0 || len(c.endpoints.statuses()) < 2 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			c.CheckEndpoints(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
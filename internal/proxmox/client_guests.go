@@ -0,0 +1,196 @@
+package proxmox
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Guest is a VM or container as listed by cluster/resources
+type Guest struct {
+	VMID     int      `json:"vmid"`
+	Name     string   `json:"name,omitempty"`
+	Node     string   `json:"node"`
+	Type     string   `json:"type"` // qemu or lxc
+	Status   string   `json:"status,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Template bool     `json:"template,omitempty"`
+	HAState  string   `json:"hastate,omitempty"`
+	CPU      float64  `json:"cpu,omitempty"`
+	MaxCPU   int      `json:"maxcpu,omitempty"`
+	Mem      int64    `json:"mem,omitempty"`
+	MaxMem   int64    `json:"maxmem,omitempty"`
+}
+
+// Kind returns "VM" or "container"
+func (g Guest) Kind() string {
+	if g.Type == "lxc" {
+		return "container"
+	}
+	return "VM"
+}
+
+// String describes the guest, e.g. "VM 100 (web) on pve1"
+func (g Guest) String() string {
+	if g.Name == "" {
+		return fmt.Sprintf("%s %d on %s", g.Kind(), g.VMID, g.Node)
+	}
+	return fmt.Sprintf("%s %d (%s) on %s", g.Kind(), g.VMID, g.Name, g.Node)
+}
+
+// HasTag reports whether the guest carries tag, ignoring case as Proxmox does
+func (g Guest) HasTag(tag string) bool {
+	for _, t := range g.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// GuestSelector identifies a guest by VMID, name or tag, in that order of
+// precedence. Type (qemu or lxc) and Node narrow the candidates when set.
+type GuestSelector struct {
+	VMID int
+	Name string
+	Tag  string
+	Type string
+	Node string
+}
+
+// String describes the selector for error messages, e.g. `VM named "web"`
+func (s GuestSelector) String() string {
+	kind := "guest"
+	switch s.Type {
+	case "qemu":
+		kind = "VM"
+	case "lxc":
+		kind = "container"
+	}
+
+	var b strings.Builder
+	switch {
+	case s.VMID > 0:
+		fmt.Fprintf(&b, "%s %d", kind, s.VMID)
+	case s.Name != "":
+		fmt.Fprintf(&b, "%s named %q", kind, s.Name)
+	case s.Tag != "":
+		fmt.Fprintf(&b, "%s tagged %q", kind, s.Tag)
+	default:
+		b.WriteString(kind)
+	}
+	if s.Node != "" {
+		fmt.Fprintf(&b, " on %s", s.Node)
+	}
+	return b.String()
+}
+
+// matches reports whether g is selected
+func (s GuestSelector) matches(g Guest) bool {
+	if s.Type != "" && g.Type != s.Type {
+		return false
+	}
+	if s.Node != "" && g.Node != s.Node {
+		return false
+	}
+	switch {
+	case s.VMID > 0:
+		return g.VMID == s.VMID
+	case s.Name != "":
+		return g.Name == s.Name
+	case s.Tag != "":
+		return g.HasTag(s.Tag)
+	}
+	return false
+}
+
+// GetGuests lists the VMs and containers of the cluster with the node each
+// one currently runs on
+func (c *Client) GetGuests(ctx context.Context) ([]Guest, error) {
+	data, err := c.doRequest(ctx, "GET", "cluster/resources", params{}.set("type", "vm"))
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []struct {
+		VMID     int     `json:"vmid"`
+		Name     string  `json:"name"`
+		Node     string  `json:"node"`
+		Type     string  `json:"type"`
+		Status   string  `json:"status"`
+		Tags     string  `json:"tags"`
+		Template int     `json:"template"`
+		HAState  string  `json:"hastate"`
+		CPU      float64 `json:"cpu"`
+		MaxCPU   int     `json:"maxcpu"`
+		Mem      int64   `json:"mem"`
+		MaxMem   int64   `json:"maxmem"`
+	}
+	if err := c.unmarshalData(data, &resources); err != nil {
+		return nil, fmt.Errorf("failed to parse cluster resources: %w", err)
+	}
+
+	guests := make([]Guest, 0, len(resources))
+	for _, r := range resources {
+		if r.Type != "qemu" && r.Type != "lxc" {
+			continue
+		}
+		guest := Guest{
+			VMID:     r.VMID,
+			Name:     r.Name,
+			Node:     r.Node,
+			Type:     r.Type,
+			Status:   r.Status,
+			Template: r.Template == 1,
+			HAState:  r.HAState,
+			CPU:      r.CPU,
+			MaxCPU:   r.MaxCPU,
+			Mem:      r.Mem,
+			MaxMem:   r.MaxMem,
+		}
+		// Proxmox separates tags with semicolons; older versions also
+		// accept commas and spaces
+		guest.Tags = strings.FieldsFunc(r.Tags, func(r rune) bool { return r == ';' || r == ',' || r == ' ' })
+		guests = append(guests, guest)
+	}
+	sort.Slice(guests, func(i, j int) bool { return guests[i].VMID < guests[j].VMID })
+	return guests, nil
+}
+
+// FindGuests returns the guests selected by sel, ordered by VMID
+func (c *Client) FindGuests(ctx context.Context, sel GuestSelector) ([]Guest, error) {
+	guests, err := c.GetGuests(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []Guest
+	for _, guest := range guests {
+		if sel.matches(guest) {
+			found = append(found, guest)
+		}
+	}
+	return found, nil
+}
+
+// ResolveGuest returns the single guest selected by sel. It fails with an
+// error matching ErrGuestNotFound when no guest matches and with an
+// *AmbiguousGuestError when a name or tag matches several.
+func (c *Client) ResolveGuest(ctx context.Context, sel GuestSelector) (*Guest, error) {
+	if sel.VMID <= 0 && sel.Name == "" && sel.Tag == "" {
+		return nil, fmt.Errorf("a VMID, name or tag is required to identify the %s", GuestSelector{Type: sel.Type})
+	}
+
+	found, err := c.FindGuests(ctx, sel)
+	if err != nil {
+		return nil, err
+	}
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("%w: %s", ErrGuestNotFound, sel)
+	case 1:
+		return &found[0], nil
+	}
+	return nil, &AmbiguousGuestError{Selector: sel, Matches: found}
+}
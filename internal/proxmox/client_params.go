@@ -0,0 +1,178 @@
+package proxmox
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// params collects the parameters of a Proxmox API request. Values are
+// encoded the way the API expects them when the request is sent: booleans
+// as 0/1, slices as comma-separated lists, maps as property strings
+// (key=value,...) and repeated values as one form field per element.
+type params map[string]interface{}
+
+// repeated is a list parameter that the API expects as repeated form fields
+// rather than a single comma-separated value
+type repeated []string
+
+// set adds a parameter unconditionally
+func (p params) set(key string, value interface{}) params {
+	p[key] = value
+	return p
+}
+
+// opt adds a parameter only when value is not the zero value of its type
+// (empty string, 0, false, nil or an empty slice or map)
+func (p params) opt(key string, value interface{}) params {
+	if !isZeroParam(value) {
+		p[key] = value
+	}
+	return p
+}
+
+func isZeroParam(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return rv.IsNil()
+	default:
+		return rv.IsZero()
+	}
+}
+
+// encodeForm converts a request body into form values. The body may be nil,
+// url.Values, params, a map or a struct with json tags.
+func encodeForm(body interface{}) (url.Values, error) {
+	switch b := body.(type) {
+	case nil:
+		return nil, nil
+	case url.Values:
+		return b, nil
+	case params:
+		return encodeParamMap(b)
+	case map[string]interface{}:
+		return encodeParamMap(b)
+	case map[string]string:
+		values := url.Values{}
+		for key, value := range b {
+			values.Set(key, value)
+		}
+		return values, nil
+	}
+
+	// Structs and other maps go through their JSON representation so that
+	// json tags and omitempty decide the parameter names and presence
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request parameters: %w", err)
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	var m map[string]interface{}
+	if err := decoder.Decode(&m); err != nil {
+		return nil, fmt.Errorf("request body must be an object: %w", err)
+	}
+	return encodeParamMap(m)
+}
+
+func encodeParamMap(m map[string]interface{}) (url.Values, error) {
+	values := url.Values{}
+	for key, value := range m {
+		if value == nil {
+			continue
+		}
+		if list, ok := value.(repeated); ok {
+			for _, item := range list {
+				values.Add(key, item)
+			}
+			continue
+		}
+		encoded, err := encodeParamValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %s: %w", key, err)
+		}
+		values.Set(key, encoded)
+	}
+	return values, nil
+}
+
+// encodeParamValue encodes a single parameter value in Proxmox format
+func encodeParamValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.Itoa(boolToInt(v)), nil
+	case json.Number:
+		return v.String(), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case fmt.Stringer:
+		return v.String(), nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Slice, reflect.Array:
+		items := make([]string, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			item, err := encodeParamValue(rv.Index(i).Interface())
+			if err != nil {
+				return "", err
+			}
+			items = append(items, item)
+		}
+		return strings.Join(items, ","), nil
+	case reflect.Map:
+		return encodePropertyString(rv)
+	}
+	return "", fmt.Errorf("unsupported parameter type %T", value)
+}
+
+// encodePropertyString encodes a map as a Proxmox property string with keys
+// in sorted order, e.g. {"model": "virtio", "bridge": "vmbr0"} becomes
+// "bridge=vmbr0,model=virtio"
+func encodePropertyString(rv reflect.Value) (string, error) {
+	keys := make([]string, 0, rv.Len())
+	entries := make(map[string]string, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		key := fmt.Sprint(iter.Key().Interface())
+		value := iter.Value().Interface()
+		if value == nil {
+			continue
+		}
+		if rv := reflect.ValueOf(value); rv.Kind() == reflect.Map || rv.Kind() == reflect.Slice {
+			return "", fmt.Errorf("property %s: nested lists and maps are not supported in property strings", key)
+		}
+		encoded, err := encodeParamValue(value)
+		if err != nil {
+			return "", err
+		}
+		keys = append(keys, key)
+		entries[key] = encoded
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, key+"="+entries[key])
+	}
+	return strings.Join(parts, ","), nil
+}
@@ -0,0 +1,155 @@
+package proxmox
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestEncodeParamValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    string
+		wantErr bool
+	}{
+		{"string", "vmbr0", "vmbr0", false},
+		{"true", true, "1", false},
+		{"false", false, "0", false},
+		{"int", 2048, "2048", false},
+		{"int64", int64(1 << 40), "1099511627776", false},
+		{"uint", uint(8), "8", false},
+		{"float", 0.5, "0.5", false},
+		{"whole float", float64(100), "100", false},
+		{"JSON number", json.Number("42"), "42", false},
+		{"stringer", Disk{Volume: "local-lvm:vm-100-disk-0", Size: "32G"}, "local-lvm:vm-100-disk-0,size=32G", false},
+		{"string slice", []string{"scsi0", "unused0"}, "scsi0,unused0", false},
+		{"int slice", []int{100, 101}, "100,101", false},
+		{"mixed slice", []interface{}{"a", 1, true}, "a,1,1", false},
+		{"map as property string", map[string]interface{}{"model": "virtio", "bridge": "vmbr0", "firewall": true, "tag": 10}, "bridge=vmbr0,firewall=1,model=virtio,tag=10", false},
+		{"string map", map[string]string{"ip": "dhcp"}, "ip=dhcp", false},
+		{"nil map values are left out", map[string]interface{}{"ip": "dhcp", "gw": nil}, "ip=dhcp", false},
+		{"nested map", map[string]interface{}{"net": map[string]string{"model": "virtio"}}, "", true},
+		{"list in property string", map[string]interface{}{"trunks": []int{10, 20}}, "", true},
+		{"unsupported type", struct{}{}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := encodeParamValue(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("encodeParamValue(%v) = %q, want an error", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("encodeParamValue(%v) error = %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("encodeParamValue(%v) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeForm(t *testing.T) {
+	type createArgs struct {
+		VMID   int    `json:"vmid"`
+		Name   string `json:"name,omitempty"`
+		Start  bool   `json:"start"`
+		Pool   string `json:"pool,omitempty"`
+		Memory int    `json:"memory,omitempty"`
+	}
+
+	tests := []struct {
+		name string
+		body interface{}
+		want url.Values
+	}{
+		{"nil", nil, nil},
+		{
+			"params",
+			params{}.set("vmid", 100).opt("name", "").opt("full", true).set("delete", []string{"net1", "scsi1"}),
+			url.Values{"vmid": {"100"}, "full": {"1"}, "delete": {"net1,scsi1"}},
+		},
+		{
+			"repeated values",
+			params{}.set("vmid", 100).set("sshkeys", repeated{"ssh-ed25519 AAAA a", "ssh-ed25519 BBBB b"}),
+			url.Values{"vmid": {"100"}, "sshkeys": {"ssh-ed25519 AAAA a", "ssh-ed25519 BBBB b"}},
+		},
+		{
+			"struct with json tags",
+			createArgs{VMID: 100, Name: "web", Start: true},
+			url.Values{"vmid": {"100"}, "name": {"web"}, "start": {"1"}},
+		},
+		{
+			"string map",
+			map[string]string{"name": "web"},
+			url.Values{"name": {"web"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := encodeForm(tt.body)
+			if err != nil {
+				t.Fatalf("encodeForm() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("encodeForm() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOptSkipsZeroValues(t *testing.T) {
+	p := params{}.
+		opt("name", "").
+		opt("memory", 0).
+		opt("force", false).
+		opt("delete", []string{}).
+		opt("net", map[string]string{}).
+		opt("digest", nil).
+		opt("cores", 2)
+	if want := (params{"cores": 2}); !reflect.DeepEqual(p, want) {
+		t.Errorf("params = %v, want %v", p, want)
+	}
+}
+
+func TestRequestParameterPlacement(t *testing.T) {
+	type request struct {
+		method, query, body, contentType string
+	}
+	var got request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = request{r.Method, r.URL.RawQuery, string(body), r.Header.Get("Content-Type")}
+		w.Write([]byte(`{"data":null}`))
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "root@pam!test=secret", false)
+
+	tests := []struct {
+		method string
+		want   request
+	}{
+		{"GET", request{method: "GET", query: "purge=1&vmid=100"}},
+		{"DELETE", request{method: "DELETE", query: "purge=1&vmid=100"}},
+		{"POST", request{method: "POST", body: "purge=1&vmid=100", contentType: "application/x-www-form-urlencoded"}},
+		{"PUT", request{method: "PUT", body: "purge=1&vmid=100", contentType: "application/x-www-form-urlencoded"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			if _, err := client.doRequest(context.Background(), tt.method, "nodes/pve1/qemu/100", params{}.set("vmid", 100).set("purge", true)); err != nil {
+				t.Fatalf("doRequest() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("request = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,8 @@ package proxmox
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 )
 
 // Pool represents a Proxmox resource pool
@@ -46,33 +48,18 @@ func (c *Client) GetPool(ctx context.Context, poolID string) (*Pool, error) {
 
 // CreatePool creates a new resource pool
 func (c *Client) CreatePool(ctx context.Context, poolID, comment string, members []string) (interface{}, error) {
-	body := map[string]interface{}{
-		"poolid": poolID,
-	}
-	if comment != "" {
-		body["comment"] = comment
-	}
-	if len(members) > 0 {
-		body["members"] = members
-	}
+	body := poolMemberParams(members).
+		set("poolid", poolID).
+		opt("comment", comment)
 
 	return c.doRequest(ctx, "POST", "pools", body)
 }
 
 // UpdatePool modifies an existing resource pool
 func (c *Client) UpdatePool(ctx context.Context, poolID, comment string, members []string, delete bool) (interface{}, error) {
-	body := map[string]interface{}{
-		"poolid": poolID,
-	}
-	if comment != "" {
-		body["comment"] = comment
-	}
-	if len(members) > 0 {
-		body["members"] = members
-	}
-	if delete {
-		body["delete"] = 1
-	}
+	body := poolMemberParams(members).
+		opt("comment", comment).
+		opt("delete", delete)
 
 	return c.doRequest(ctx, "PUT", fmt.Sprintf("pools/%s", poolID), body)
 }
@@ -96,3 +83,17 @@ func (c *Client) GetPoolMembers(ctx context.Context, poolID string) (map[string]
 
 	return members, nil
 }
+
+// poolMemberParams splits pool members into the vms and storage lists the API
+// expects; numeric members are guest IDs, all others storage IDs
+func poolMemberParams(members []string) params {
+	var vms, storage []string
+	for _, member := range members {
+		if _, err := strconv.Atoi(member); err == nil {
+			vms = append(vms, member)
+		} else {
+			storage = append(storage, strings.TrimPrefix(member, "storage/"))
+		}
+	}
+	return params{}.opt("vms", vms).opt("storage", storage)
+}
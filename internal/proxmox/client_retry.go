@@ -0,0 +1,178 @@
+package proxmox
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNodeUnavailable is returned without contacting Proxmox while the circuit
+// breaker of a node is open
+var ErrNodeUnavailable = errors.New("node unavailable")
+
+// RetryPolicy controls how failed requests are retried
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts per request; 1 disables retries
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry
+	InitialBackoff time.Duration
+	// MaxBackoff caps the exponentially growing delay
+	MaxBackoff time.Duration
+	// RetryMutating allows POST, PUT and DELETE requests to be retried when
+	// the failure shows the request never reached the target node
+	RetryMutating bool
+}
+
+// DefaultRetryPolicy returns the retry policy used by NewClient
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		RetryMutating:  true,
+	}
+}
+
+// shouldRetry reports whether a request that failed with err may be sent again
+func (p RetryPolicy) shouldRetry(method string, err error) bool {
+	if method == http.MethodGet {
+		return IsRetryable(err)
+	}
+	return p.RetryMutating && notDelivered(err)
+}
+
+// backoff returns the delay before retry number attempt (starting at 1),
+// with jitter between half and the full exponential delay
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.InitialBackoff << (attempt - 1)
+	if delay <= 0 || delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
+
+// notDelivered reports whether err proves the request was never processed,
+// which makes it safe to resend a non-idempotent request
+func notDelivered(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusServiceUnavailable,
+			595: // pveproxy could not connect to the target node
+			return true
+		}
+		return false
+	}
+
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// nodeUnreachable reports whether err indicates that the node serving the
+// request is down, as opposed to the request itself being invalid
+func nodeUnreachable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == 595 || apiErr.StatusCode == 596 ||
+			apiErr.StatusCode == http.StatusBadGateway || apiErr.StatusCode == http.StatusGatewayTimeout
+	}
+	return isTransportFailure(err)
+}
+
+// endpointNode returns the node an endpoint is addressed to, or "" for
+// cluster-wide endpoints
+func endpointNode(endpoint string) string {
+	rest, ok := strings.CutPrefix(endpoint, "nodes/")
+	if !ok {
+		return ""
+	}
+	node, _, _ := strings.Cut(rest, "/")
+	node, _, _ = strings.Cut(node, "?")
+	return node
+}
+
+// circuitBreakers holds one circuit breaker per node
+type circuitBreakers struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	nodes     map[string]*circuitBreaker
+}
+
+// circuitBreaker opens after threshold consecutive failures and lets a single
+// trial request through once the cooldown has passed
+type circuitBreaker struct {
+	failures  int
+	openUntil time.Time
+	trial     bool
+}
+
+func newCircuitBreakers(threshold int, cooldown time.Duration) *circuitBreakers {
+	return &circuitBreakers{
+		threshold: threshold,
+		cooldown:  cooldown,
+		nodes:     make(map[string]*circuitBreaker),
+	}
+}
+
+// allow returns an error wrapping ErrNodeUnavailable when requests to node
+// must fail fast
+func (cb *circuitBreakers) allow(node string) error {
+	if cb.threshold <= 0 {
+		return nil
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	breaker, ok := cb.nodes[node]
+	if !ok || breaker.failures < cb.threshold {
+		return nil
+	}
+	if wait := time.Until(breaker.openUntil); wait > 0 || breaker.trial {
+		name := node
+		if name == "" {
+			name = "cluster API"
+		}
+		return fmt.Errorf("%w: %s failed %d consecutive requests, retrying after %s",
+			ErrNodeUnavailable, name, breaker.failures, max(wait, 0).Round(time.Second))
+	}
+	// Half-open: let one request probe the node
+	breaker.trial = true
+	return nil
+}
+
+// record updates the breaker of node with the outcome of a request
+func (cb *circuitBreakers) record(node string, err error) {
+	if cb.threshold <= 0 {
+		return
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	breaker, ok := cb.nodes[node]
+	if !ok {
+		breaker = &circuitBreaker{}
+		cb.nodes[node] = breaker
+	}
+	breaker.trial = false
+
+	if !nodeUnreachable(err) {
+		breaker.failures = 0
+		return
+	}
+	breaker.failures++
+	if breaker.failures >= cb.threshold {
+		breaker.openUntil = time.Now().Add(cb.cooldown)
+	}
+}
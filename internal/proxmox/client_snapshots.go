@@ -7,12 +7,9 @@ import (
 
 // CreateVMSnapshot creates a snapshot of a virtual machine
 func (c *Client) CreateVMSnapshot(ctx context.Context, nodeName string, vmID int, snapName string, description string) (interface{}, error) {
-	data := map[string]string{
-		"snapname": snapName,
-	}
-	if description != "" {
-		data["description"] = description
-	}
+	data := params{}.
+		set("snapname", snapName).
+		opt("description", description)
 
 	return c.doRequest(ctx, "POST", fmt.Sprintf("nodes/%s/qemu/%d/snapshot", nodeName, vmID), data)
 }
@@ -41,9 +38,7 @@ func (c *Client) ListVMSnapshots(ctx context.Context, nodeName string, vmID int)
 
 // DeleteVMSnapshot deletes a snapshot from a virtual machine
 func (c *Client) DeleteVMSnapshot(ctx context.Context, nodeName string, vmID int, snapName string, force bool) (interface{}, error) {
-	data := map[string]interface{}{
-		"force": force,
-	}
+	data := params{}.opt("force", force)
 
 	return c.doRequest(ctx, "DELETE", fmt.Sprintf("nodes/%s/qemu/%d/snapshot/%s", nodeName, vmID, snapName), data)
 }
@@ -77,24 +72,18 @@ func (c *Client) GetVMFirewallRules(ctx context.Context, nodeName string, vmID i
 
 // MigrateVM migrates a virtual machine to another node
 func (c *Client) MigrateVM(ctx context.Context, nodeName string, vmID int, targetNode string, online bool) (interface{}, error) {
-	config := map[string]interface{}{
-		"target": targetNode,
-	}
-	if online {
-		config["online"] = 1
-	}
+	config := params{}.
+		set("target", targetNode).
+		opt("online", online)
 
 	return c.doRequest(ctx, "POST", fmt.Sprintf("nodes/%s/qemu/%d/migrate", nodeName, vmID), config)
 }
 
 // CreateContainerSnapshot creates a snapshot of a container
 func (c *Client) CreateContainerSnapshot(ctx context.Context, nodeName string, containerID int, snapName string, description string) (interface{}, error) {
-	data := map[string]string{
-		"snapname": snapName,
-	}
-	if description != "" {
-		data["description"] = description
-	}
+	data := params{}.
+		set("snapname", snapName).
+		opt("description", description)
 
 	return c.doRequest(ctx, "POST", fmt.Sprintf("nodes/%s/lxc/%d/snapshot", nodeName, containerID), data)
 }
@@ -123,9 +112,7 @@ func (c *Client) ListContainerSnapshots(ctx context.Context, nodeName string, co
 
 // DeleteContainerSnapshot deletes a snapshot from a container
 func (c *Client) DeleteContainerSnapshot(ctx context.Context, nodeName string, containerID int, snapName string, force bool) (interface{}, error) {
-	data := map[string]interface{}{
-		"force": force,
-	}
+	data := params{}.opt("force", force)
 
 	return c.doRequest(ctx, "DELETE", fmt.Sprintf("nodes/%s/lxc/%d/snapshot/%s", nodeName, containerID, snapName), data)
 }
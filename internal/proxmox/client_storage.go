@@ -43,11 +43,10 @@ func (c *Client) GetStorageContent(ctx context.Context, storage string) ([]map[s
 
 // CreateStorage creates a new storage mount
 func (c *Client) CreateStorage(ctx context.Context, storage, storageType, content string, config map[string]interface{}) (interface{}, error) {
-	body := map[string]interface{}{
-		"storage": storage,
-		"type":    storageType,
-		"content": content,
-	}
+	body := params{}.
+		set("storage", storage).
+		set("type", storageType).
+		opt("content", content)
 	// Merge additional config
 	for k, v := range config {
 		body[k] = v
@@ -63,8 +62,10 @@ func (c *Client) DeleteStorage(ctx context.Context, storage string) (interface{}
 
 // UpdateStorage modifies storage configuration
 func (c *Client) UpdateStorage(ctx context.Context, storage string, config map[string]interface{}) (interface{}, error) {
-	body := config
-	body["storage"] = storage
+	body := params{}
+	for k, v := range config {
+		body[k] = v
+	}
 	return c.doRequest(ctx, "PUT", fmt.Sprintf("storage/%s", storage), body)
 }
 
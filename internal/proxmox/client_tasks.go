@@ -3,6 +3,10 @@ package proxmox
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // ListTasks lists all background tasks
@@ -20,10 +24,60 @@ func (c *Client) ListTasks(ctx context.Context) ([]Task, error) {
 	return tasks, nil
 }
 
+// ParseUPID parses a task identifier of the form
+// UPID:node:pid:pstart:starttime:type:id:user:
+func ParseUPID(upid string) (*UPID, error) {
+	parts := strings.Split(strings.TrimSuffix(upid, ":"), ":")
+	if len(parts) != 8 || parts[0] != "UPID" || parts[1] == "" {
+		return nil, fmt.Errorf("invalid UPID %q", upid)
+	}
+
+	pid, err := strconv.ParseInt(parts[2], 16, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid UPID %q: bad pid: %w", upid, err)
+	}
+	pstart, err := strconv.ParseInt(parts[3], 16, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid UPID %q: bad pstart: %w", upid, err)
+	}
+	starttime, err := strconv.ParseInt(parts[4], 16, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid UPID %q: bad starttime: %w", upid, err)
+	}
+
+	return &UPID{
+		Node:      parts[1],
+		PID:       int(pid),
+		PStart:    pstart,
+		StartTime: time.Unix(starttime, 0).UTC(),
+		Type:      parts[5],
+		ID:        parts[6],
+		User:      parts[7],
+		raw:       upid,
+	}, nil
+}
+
+// String returns the UPID in Proxmox format
+func (u *UPID) String() string {
+	if u.raw != "" {
+		return u.raw
+	}
+	return fmt.Sprintf("UPID:%s:%08X:%08X:%08X:%s:%s:%s:", u.Node, u.PID, u.PStart, u.StartTime.Unix(), u.Type, u.ID, u.User)
+}
+
+// taskEndpoint returns the node-scoped API path of a task
+func (u *UPID) taskEndpoint() string {
+	return fmt.Sprintf("nodes/%s/tasks/%s", u.Node, url.PathEscape(u.String()))
+}
+
 // GetTaskStatus retrieves detailed status and progress of a task
 func (c *Client) GetTaskStatus(ctx context.Context, taskID string) (map[string]interface{}, error) {
-	// TaskID format is typically "UPID:node:pid:start_time:type:user"
-	data, err := c.doRequest(ctx, "GET", fmt.Sprintf("cluster/tasks/%s", taskID), nil)
+	upid, err := ParseUPID(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := c.doRequest(ctx, "GET", upid.taskEndpoint()+"/status", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -37,7 +91,20 @@ func (c *Client) GetTaskStatus(ctx context.Context, taskID string) (map[string]i
 }
 
 // GetTaskLog retrieves the log output for a task
-func (c *Client) GetTaskLog(ctx context.Context, taskID string, start, limit int) (map[string]interface{}, error) {
+func (c *Client) GetTaskLog(ctx context.Context, taskID string, start, limit int) ([]TaskLogLine, error) {
+	lines, _, err := c.GetTaskLogPage(ctx, taskID, start, limit)
+	return lines, err
+}
+
+// GetTaskLogPage retrieves up to limit log lines of a task from line start
+// on, with the total number of lines in the log. Proxmox returns 50 lines
+// when limit is 0.
+func (c *Client) GetTaskLogPage(ctx context.Context, taskID string, start, limit int) ([]TaskLogLine, int, error) {
+	upid, err := ParseUPID(taskID)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	params := map[string]interface{}{}
 	if start > 0 {
 		params["start"] = start
@@ -46,43 +113,224 @@ func (c *Client) GetTaskLog(ctx context.Context, taskID string, start, limit int
 		params["limit"] = limit
 	}
 
-	data, err := c.doRequest(ctx, "GET", fmt.Sprintf("cluster/tasks/%s/log", taskID), params)
+	resp, err := c.doRequestResponse(ctx, "GET", upid.taskEndpoint()+"/log", params)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	log, ok := data.(map[string]interface{})
-	if !ok {
-		return nil, fmt.Errorf("unexpected task log format")
+	lines := []TaskLogLine{}
+	if err := c.unmarshalData(resp.Data, &lines); err != nil {
+		return nil, 0, err
 	}
 
-	return log, nil
+	return lines, resp.Total, nil
+}
+
+// taskLogPageSize is the number of lines GetFullTaskLog requests at a time
+const taskLogPageSize = 500
+
+// GetFullTaskLog retrieves every line of a task log, paging through it until
+// the total Proxmox reports has been read
+func (c *Client) GetFullTaskLog(ctx context.Context, taskID string) ([]TaskLogLine, error) {
+	all := []TaskLogLine{}
+	for {
+		lines, total, err := c.GetTaskLogPage(ctx, taskID, len(all), taskLogPageSize)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, lines...)
+		if len(lines) < taskLogPageSize || len(all) >= total {
+			return all, nil
+		}
+	}
 }
 
 // CancelTask cancels a running task
 func (c *Client) CancelTask(ctx context.Context, taskID string) (interface{}, error) {
-	return c.doRequest(ctx, "DELETE", fmt.Sprintf("cluster/tasks/%s", taskID), nil)
+	upid, err := ParseUPID(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.doRequest(ctx, "DELETE", upid.taskEndpoint(), nil)
 }
 
-// GetNodeTasks gets all tasks for a specific node
-func (c *Client) GetNodeTasks(ctx context.Context, nodeName string) ([]Task, error) {
-	tasks, err := c.ListTasks(ctx)
+// GetNodeTasks lists tasks of a node, filtered server-side by filter
+func (c *Client) GetNodeTasks(ctx context.Context, nodeName string, filter TaskFilter) ([]Task, error) {
+	params := map[string]interface{}{}
+	if filter.TypeFilter != "" {
+		params["typefilter"] = filter.TypeFilter
+	}
+	if filter.VMID > 0 {
+		params["vmid"] = filter.VMID
+	}
+	if filter.Errors {
+		params["errors"] = 1
+	}
+	if !filter.Since.IsZero() {
+		params["since"] = filter.Since.Unix()
+	}
+	if !filter.Until.IsZero() {
+		params["until"] = filter.Until.Unix()
+	}
+	if filter.Source != "" {
+		params["source"] = filter.Source
+	}
+	if filter.UserFilter != "" {
+		params["userfilter"] = filter.UserFilter
+	}
+	if filter.Start > 0 {
+		params["start"] = filter.Start
+	}
+	if filter.Limit > 0 {
+		params["limit"] = filter.Limit
+	}
+
+	data, err := c.doRequest(ctx, "GET", fmt.Sprintf("nodes/%s/tasks", nodeName), params)
 	if err != nil {
 		return nil, err
 	}
 
-	// Filter tasks for the specific node
-	var nodeTasks []Task
-	for _, task := range tasks {
-		if task.Node == nodeName {
-			nodeTasks = append(nodeTasks, task)
-		}
+	tasks := []Task{}
+	if err := c.unmarshalData(data, &tasks); err != nil {
+		return nil, err
 	}
 
-	return nodeTasks, nil
+	return tasks, nil
 }
 
 // GetClusterTasks gets all cluster tasks
 func (c *Client) GetClusterTasks(ctx context.Context) ([]Task, error) {
 	return c.ListTasks(ctx)
 }
+
+// TaskWaitOptions controls how WaitForTask polls a task
+type TaskWaitOptions struct {
+	// Timeout bounds the total wait; zero waits until ctx is done
+	Timeout time.Duration
+	// PollInterval is the initial delay between status checks (default 1s)
+	PollInterval time.Duration
+	// MaxPollInterval caps the exponential backoff (default 10s)
+	MaxPollInterval time.Duration
+	// LogLines is the number of trailing log lines returned (default 50)
+	LogLines int
+}
+
+// TaskResult is the outcome of a task awaited with WaitForTask
+type TaskResult struct {
+	UPID       string   `json:"upid"`
+	Node       string   `json:"node"`
+	Status     string   `json:"status"`
+	ExitStatus string   `json:"exitstatus,omitempty"`
+	Success    bool     `json:"success"`
+	Warnings   int      `json:"warnings,omitempty"` // warnings of a task that succeeded with WARNINGS: n
+	TimedOut   bool     `json:"timed_out,omitempty"`
+	Duration   string   `json:"duration"`
+	Log        []string `json:"log,omitempty"`
+}
+
+// WaitForTask polls a task until it stops or the timeout elapses. A task that
+// is still running when the timeout elapses is returned with TimedOut set.
+func (c *Client) WaitForTask(ctx context.Context, upid string, opts TaskWaitOptions) (*TaskResult, error) {
+	parsed, err := ParseUPID(upid)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+	if opts.MaxPollInterval <= 0 {
+		opts.MaxPollInterval = 10 * time.Second
+	}
+	if opts.LogLines <= 0 {
+		opts.LogLines = 50
+	}
+
+	started := time.Now()
+	waitCtx := ctx
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	result := &TaskResult{UPID: upid, Node: parsed.Node}
+	endpoint := parsed.taskEndpoint() + "/status"
+	interval := opts.PollInterval
+	for {
+		data, err := c.doRequest(waitCtx, "GET", endpoint, nil)
+		if err != nil && waitCtx.Err() == nil {
+			return nil, fmt.Errorf("get task status: %w", err)
+		}
+		if err == nil {
+			var status struct {
+				Status     string `json:"status"`
+				ExitStatus string `json:"exitstatus"`
+			}
+			if err := c.unmarshalData(data, &status); err != nil {
+				return nil, err
+			}
+			result.Status = status.Status
+			result.ExitStatus = status.ExitStatus
+			if status.Status == "stopped" {
+				break
+			}
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-waitCtx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		if waitCtx.Err() != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			result.TimedOut = true
+			break
+		}
+		interval = min(interval*3/2, opts.MaxPollInterval)
+	}
+
+	if result.Status == "stopped" {
+		result.Success, result.Warnings = taskExitStatus(result.ExitStatus)
+	}
+	result.Duration = time.Since(started).Round(time.Second).String()
+
+	logLines, err := c.taskLogTail(ctx, upid, opts.LogLines)
+	if err != nil {
+		c.logger.WithError(err).Debugf("Failed to fetch log of task %s", upid)
+	}
+	for _, line := range logLines {
+		result.Log = append(result.Log, line.T)
+	}
+
+	return result, nil
+}
+
+// taskExitStatus interprets the exit status of a stopped task. Tasks that
+// finished with warnings, e.g. a backup that skipped a file, end with
+// "WARNINGS: n" and count as successful.
+func taskExitStatus(exitStatus string) (bool, int) {
+	if exitStatus == "OK" {
+		return true, 0
+	}
+	if count, found := strings.CutPrefix(exitStatus, "WARNINGS:"); found {
+		n, _ := strconv.Atoi(strings.TrimSpace(count))
+		return true, n
+	}
+	return false, 0
+}
+
+// taskLogTail returns the last n lines of a task log. The log endpoint pages
+// from the start, so the first page tells the total and, for longer logs, a
+// second request fetches the tail.
+func (c *Client) taskLogTail(ctx context.Context, upid string, n int) ([]TaskLogLine, error) {
+	lines, total, err := c.GetTaskLogPage(ctx, upid, 0, n)
+	if err != nil || total <= len(lines) {
+		return lines, err
+	}
+	return c.GetTaskLog(ctx, upid, total-n, n)
+}
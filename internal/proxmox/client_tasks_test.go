@@ -0,0 +1,92 @@
+package proxmox
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTaskExitStatus(t *testing.T) {
+	tests := []struct {
+		exitStatus string
+		success    bool
+		warnings   int
+	}{
+		{"OK", true, 0},
+		{"WARNINGS: 3", true, 3},
+		{"WARNINGS:1", true, 1},
+		{"unable to find configuration file for VM 100 - no such machine", false, 0},
+		{"job errors", false, 0},
+		{"", false, 0},
+	}
+	for _, tt := range tests {
+		success, warnings := taskExitStatus(tt.exitStatus)
+		if success != tt.success || warnings != tt.warnings {
+			t.Errorf("taskExitStatus(%q) = %v, %d, want %v, %d", tt.exitStatus, success, warnings, tt.success, tt.warnings)
+		}
+	}
+}
+
+func TestParseUPID(t *testing.T) {
+	tests := []struct {
+		name    string
+		upid    string
+		want    UPID
+		wantErr bool
+	}{
+		{
+			name: "VM start",
+			upid: "UPID:pve1:0000ABCD:00001234:65A1F0C3:qmstart:100:root@pam:",
+			want: UPID{Node: "pve1", PID: 0xABCD, PStart: 0x1234, StartTime: time.Unix(0x65A1F0C3, 0).UTC(), Type: "qmstart", ID: "100", User: "root@pam"},
+		},
+		{
+			name: "empty ID",
+			upid: "UPID:pve2:003A1B2C:0A2D6871:65A20000:aptupdate::automation@pve!ci:",
+			want: UPID{Node: "pve2", PID: 0x3A1B2C, PStart: 0xA2D6871, StartTime: time.Unix(0x65A20000, 0).UTC(), Type: "aptupdate", User: "automation@pve!ci"},
+		},
+		{name: "missing fields", upid: "UPID:pve1:0000ABCD:00001234:65A1F0C3:qmstart:", wantErr: true},
+		{name: "too many fields", upid: "UPID:pve1:0000ABCD:00001234:65A1F0C3:qmstart:100:root@pam:extra:", wantErr: true},
+		{name: "not a UPID", upid: "TASK:pve1:0000ABCD:00001234:65A1F0C3:qmstart:100:root@pam:", wantErr: true},
+		{name: "empty node", upid: "UPID::0000ABCD:00001234:65A1F0C3:qmstart:100:root@pam:", wantErr: true},
+		{name: "bad pid", upid: "UPID:pve1:0000XYZ0:00001234:65A1F0C3:qmstart:100:root@pam:", wantErr: true},
+		{name: "bad pstart", upid: "UPID:pve1:0000ABCD:-:65A1F0C3:qmstart:100:root@pam:", wantErr: true},
+		{name: "bad starttime", upid: "UPID:pve1:0000ABCD:00001234:yesterday:qmstart:100:root@pam:", wantErr: true},
+		{name: "empty", upid: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseUPID(tt.upid)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseUPID(%q) = %+v, want an error", tt.upid, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseUPID(%q) error = %v", tt.upid, err)
+			}
+			if got.String() != tt.upid {
+				t.Errorf("String() = %q, want %q", got.String(), tt.upid)
+			}
+			got.raw = ""
+			if *got != tt.want {
+				t.Errorf("ParseUPID(%q) = %+v, want %+v", tt.upid, *got, tt.want)
+			}
+			// Without the original text, String formats the fields the way
+			// Proxmox does
+			if got.String() != tt.upid {
+				t.Errorf("String() of the fields = %q, want %q", got.String(), tt.upid)
+			}
+		})
+	}
+}
+
+func TestUPIDTaskEndpoint(t *testing.T) {
+	upid, err := ParseUPID("UPID:pve1:0000ABCD:00001234:65A1F0C3:vzdump:100:backup@pbs!nightly:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "nodes/pve1/tasks/UPID:pve1:0000ABCD:00001234:65A1F0C3:vzdump:100:backup@pbs%21nightly:"
+	if got := upid.taskEndpoint(); got != want {
+		t.Errorf("taskEndpoint() = %q, want %q", got, want)
+	}
+}
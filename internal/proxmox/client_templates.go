@@ -0,0 +1,295 @@
+package proxmox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TemplateFromImageOptions describes a VM template built from a cloud image
+// by CreateTemplateFromImage
+type TemplateFromImageOptions struct {
+	Node     string
+	VMID     int    // zero picks the next free VMID
+	Name     string // defaults to template-<vmid>
+	ImageURL string
+	// ImageStorage receives the downloaded image and must allow import
+	// content; an image already there under the same file name is reused
+	ImageStorage string
+	// ImageFile is the file name of the image on ImageStorage, by default
+	// the last element of ImageURL with .img renamed to .qcow2
+	ImageFile         string
+	Checksum          string
+	ChecksumAlgorithm string // md5, sha1, sha224, sha256, sha384 or sha512
+	DiskStorage       string // storage of the template disk and cloud-init drive
+	DiskSize          string // grows the imported disk, e.g. "20G"
+	Memory            int    // MiB, default 2048
+	Cores             int    // default 2
+	Bridge            string // default vmbr0
+	CloudInit         *CloudInitConfig
+	// TaskTimeout bounds the wait for each task (default 10 minutes)
+	TaskTimeout time.Duration
+}
+
+// TemplateStep is a completed step of CreateTemplateFromImage
+type TemplateStep struct {
+	Step     string `json:"step"`
+	UPID     string `json:"upid,omitempty"`
+	Duration string `json:"duration"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+// TemplateResult is the outcome of CreateTemplateFromImage. On failure it
+// lists the steps completed before the failing one and whether the partial
+// VM was removed again.
+type TemplateResult struct {
+	Node        string         `json:"node"`
+	VMID        int            `json:"vmid"`
+	Name        string         `json:"name"`
+	ImageVolume string         `json:"image_volume"`
+	Steps       []TemplateStep `json:"steps"`
+	RolledBack  bool           `json:"rolled_back,omitempty"`
+}
+
+// DefaultTemplateTaskTimeout bounds each task of CreateTemplateFromImage
+const DefaultTemplateTaskTimeout = 10 * time.Minute
+
+// CreateTemplateFromImage downloads a cloud image, creates a VM importing it
+// as its boot disk with a cloud-init drive and serial console, optionally
+// grows the disk and sets cloud-init defaults, and converts the VM into a
+// template. Each task is awaited before the next step. When the creation task
+// or a later step fails, the VM is destroyed again; the downloaded image is
+// kept so a retry does not download it twice.
+func (c *Client) CreateTemplateFromImage(ctx context.Context, opts TemplateFromImageOptions) (*TemplateResult, error) {
+	if opts.Node == "" || opts.ImageURL == "" || opts.ImageStorage == "" || opts.DiskStorage == "" {
+		return nil, fmt.Errorf("node, image URL, image storage and disk storage are required")
+	}
+	imageFile, err := templateImageFile(opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Memory <= 0 {
+		opts.Memory = 2048
+	}
+	if opts.Cores <= 0 {
+		opts.Cores = 2
+	}
+	if opts.Bridge == "" {
+		opts.Bridge = "vmbr0"
+	}
+	if opts.TaskTimeout <= 0 {
+		opts.TaskTimeout = DefaultTemplateTaskTimeout
+	}
+
+	result := &TemplateResult{
+		Node:        opts.Node,
+		VMID:        opts.VMID,
+		Name:        opts.Name,
+		ImageVolume: fmt.Sprintf("%s:import/%s", opts.ImageStorage, imageFile),
+	}
+	step := func(name string, run func() (interface{}, error)) error {
+		started := time.Now()
+		data, err := run()
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		done := TemplateStep{Step: name}
+		if upid, ok := data.(string); ok && strings.HasPrefix(upid, "UPID:") {
+			done.UPID = upid
+			if err := c.awaitTemplateTask(ctx, upid, opts.TaskTimeout); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		}
+		done.Duration = time.Since(started).Round(time.Second).String()
+		result.Steps = append(result.Steps, done)
+		return nil
+	}
+
+	if result.VMID <= 0 {
+		result.VMID, err = c.NextVMID(ctx)
+		if err != nil {
+			return result, err
+		}
+	} else if err := c.checkVMIDFree(ctx, result.VMID); err != nil {
+		return result, err
+	}
+	if result.Name == "" {
+		result.Name = fmt.Sprintf("template-%d", result.VMID)
+	}
+
+	present, err := c.hasImportVolume(ctx, opts.Node, opts.ImageStorage, result.ImageVolume)
+	if err != nil {
+		return result, fmt.Errorf("listing images on %s: %w", opts.ImageStorage, err)
+	}
+	if present {
+		result.Steps = append(result.Steps, TemplateStep{Step: "download image", Duration: "0s", Detail: "reused " + result.ImageVolume})
+	} else if err := step("download image", func() (interface{}, error) {
+		body := params{}.
+			set("content", "import").
+			set("filename", imageFile).
+			set("url", opts.ImageURL).
+			opt("checksum", opts.Checksum).
+			opt("checksum-algorithm", opts.ChecksumAlgorithm)
+		return c.doRequest(ctx, "POST", fmt.Sprintf("nodes/%s/storage/%s/download-url", opts.Node, opts.ImageStorage), body)
+	}); err != nil {
+		return result, err
+	}
+
+	// Once the creation task started, a failure leaves a half-built VM
+	// behind, so it is removed again
+	created := false
+	err = step("create VM", func() (interface{}, error) {
+		data, err := c.CreateVM(ctx, opts.Node, params{}.
+			set("vmid", result.VMID).
+			set("name", result.Name).
+			set("memory", opts.Memory).
+			set("cores", opts.Cores).
+			set("ostype", "l26").
+			set("scsihw", "virtio-scsi-pci").
+			set("scsi0", Disk{Volume: opts.DiskStorage + ":0", Extra: map[string]string{"import-from": result.ImageVolume}}.String()).
+			set("ide2", opts.DiskStorage+":cloudinit").
+			set("net0", NetworkDevice{Model: "virtio", Bridge: opts.Bridge}.String()).
+			set("serial0", "socket").
+			set("vga", "serial0").
+			set("boot", BootOrder{Order: []string{"scsi0"}}.String()).
+			set("agent", "enabled=1"))
+		created = err == nil
+		return data, err
+	})
+	if err == nil {
+		err = c.finishTemplate(ctx, opts, result, step)
+	}
+	if err != nil && created {
+		rollbackErr := c.removeTemplateVM(ctx, opts.Node, result.VMID, opts.TaskTimeout)
+		if rollbackErr != nil && !IsNotFound(rollbackErr) {
+			return result, fmt.Errorf("%w (removing VM %d failed too: %v)", err, result.VMID, rollbackErr)
+		}
+		result.RolledBack = true
+	}
+	return result, err
+}
+
+// finishTemplate runs the steps of CreateTemplateFromImage after the VM was
+// created
+func (c *Client) finishTemplate(ctx context.Context, opts TemplateFromImageOptions, result *TemplateResult, step func(string, func() (interface{}, error)) error) error {
+	if opts.DiskSize != "" {
+		if err := step("resize disk", func() (interface{}, error) {
+			return c.ResizeVMDisk(ctx, opts.Node, result.VMID, "scsi0", opts.DiskSize)
+		}); err != nil {
+			return err
+		}
+	}
+	if opts.CloudInit != nil {
+		if err := step("configure cloud-init", func() (interface{}, error) {
+			return c.SetVMCloudInit(ctx, opts.Node, result.VMID, *opts.CloudInit)
+		}); err != nil {
+			return err
+		}
+	}
+	return step("convert to template", func() (interface{}, error) {
+		return c.doRequest(ctx, "POST", fmt.Sprintf("nodes/%s/qemu/%d/template", opts.Node, result.VMID), nil)
+	})
+}
+
+// templateImageFile returns the file name the image is stored under. Import
+// content only accepts disk image extensions, and cloud images named .img
+// are qcow2 files.
+func templateImageFile(opts TemplateFromImageOptions) (string, error) {
+	if opts.ImageFile != "" {
+		return opts.ImageFile, nil
+	}
+	parsed, err := url.Parse(opts.ImageURL)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return "", fmt.Errorf("invalid image URL %q", opts.ImageURL)
+	}
+	name := path.Base(parsed.Path)
+	if name == "." || name == "/" {
+		return "", fmt.Errorf("image URL %q does not name a file", opts.ImageURL)
+	}
+	if strings.HasSuffix(name, ".img") {
+		name = strings.TrimSuffix(name, ".img") + ".qcow2"
+	}
+	return name, nil
+}
+
+// NextVMID returns the lowest free VMID of the cluster
+func (c *Client) NextVMID(ctx context.Context) (int, error) {
+	data, err := c.doRequest(ctx, "GET", "cluster/nextid", nil)
+	if err != nil {
+		return 0, err
+	}
+	vmid, err := strconv.Atoi(fmt.Sprint(data))
+	if err != nil {
+		return 0, fmt.Errorf("unexpected next VMID %v", data)
+	}
+	return vmid, nil
+}
+
+// checkVMIDFree fails when a guest already uses vmid
+func (c *Client) checkVMIDFree(ctx context.Context, vmid int) error {
+	guest, err := c.ResolveGuest(ctx, GuestSelector{VMID: vmid})
+	if errors.Is(err, ErrGuestNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("VMID %d is already in use by %s", vmid, guest)
+}
+
+// hasImportVolume reports whether volume exists on a storage of node
+func (c *Client) hasImportVolume(ctx context.Context, node, storage, volume string) (bool, error) {
+	data, err := c.doRequest(ctx, "GET", fmt.Sprintf("nodes/%s/storage/%s/content", node, storage), params{}.set("content", "import"))
+	if err != nil {
+		return false, err
+	}
+	var content []struct {
+		VolID string `json:"volid"`
+	}
+	if err := c.unmarshalData(data, &content); err != nil {
+		return false, fmt.Errorf("failed to parse storage content: %w", err)
+	}
+	for _, item := range content {
+		if item.VolID == volume {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// awaitTemplateTask waits for a task and fails unless it succeeded
+func (c *Client) awaitTemplateTask(ctx context.Context, upid string, timeout time.Duration) error {
+	task, err := c.WaitForTask(ctx, upid, TaskWaitOptions{Timeout: timeout})
+	if err != nil {
+		return err
+	}
+	if task.TimedOut {
+		return fmt.Errorf("task %s still running after %s", upid, timeout)
+	}
+	if !task.Success {
+		return fmt.Errorf("task %s failed: %s\n%s", upid, task.ExitStatus, strings.Join(task.Log, "\n"))
+	}
+	return nil
+}
+
+// removeTemplateVM destroys a partially built template VM with its disks. It
+// runs even when ctx was cancelled, which may be what failed the build.
+func (c *Client) removeTemplateVM(ctx context.Context, node string, vmid int, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+
+	body := params{}.set("purge", true).set("destroy-unreferenced-disks", true)
+	data, err := c.doRequest(ctx, "DELETE", fmt.Sprintf("nodes/%s/qemu/%d", node, vmid), body)
+	if err != nil {
+		return err
+	}
+	if upid, ok := data.(string); ok && strings.HasPrefix(upid, "UPID:") {
+		return c.awaitTemplateTask(ctx, upid, timeout)
+	}
+	return nil
+}
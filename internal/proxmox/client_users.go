@@ -40,15 +40,10 @@ func (c *Client) GetUser(ctx context.Context, userID string) (*User, error) {
 // Note: Password setting may fail with API tokens (requires session ticket for PAM realm).
 func (c *Client) CreateUser(ctx context.Context, userID, password, email, comment string) (interface{}, error) {
 	// Create user without password first
-	body := map[string]interface{}{
-		"userid": userID,
-	}
-	if email != "" {
-		body["email"] = email
-	}
-	if comment != "" {
-		body["comment"] = comment
-	}
+	body := params{}.
+		set("userid", userID).
+		opt("email", email).
+		opt("comment", comment)
 
 	result, err := c.doRequest(ctx, "POST", "access/users", body)
 	if err != nil {
@@ -72,25 +67,13 @@ func (c *Client) CreateUser(ctx context.Context, userID, password, email, commen
 
 // UpdateUser updates user properties
 func (c *Client) UpdateUser(ctx context.Context, userID, email, comment, firstName, lastName string, enable bool, expire int64) (interface{}, error) {
-	body := map[string]interface{}{
-		"userid": userID,
-	}
-	if email != "" {
-		body["email"] = email
-	}
-	if comment != "" {
-		body["comment"] = comment
-	}
-	if firstName != "" {
-		body["firstname"] = firstName
-	}
-	if lastName != "" {
-		body["lastname"] = lastName
-	}
-	body["enable"] = boolToInt(enable)
-	if expire > 0 {
-		body["expire"] = expire
-	}
+	body := params{}.
+		opt("email", email).
+		opt("comment", comment).
+		opt("firstname", firstName).
+		opt("lastname", lastName).
+		set("enable", enable).
+		opt("expire", expire)
 
 	return c.doRequest(ctx, "PUT", fmt.Sprintf("access/users/%s", userID), body)
 }
@@ -102,10 +85,9 @@ func (c *Client) DeleteUser(ctx context.Context, userID string) (interface{}, er
 
 // ChangePassword changes a user's password
 func (c *Client) ChangePassword(ctx context.Context, userID, password string) (interface{}, error) {
-	body := map[string]interface{}{
-		"userid":   userID,
-		"password": password,
-	}
+	body := params{}.
+		set("userid", userID).
+		set("password", password)
 	return c.doRequest(ctx, "PUT", "access/password", body)
 }
 
@@ -126,12 +108,9 @@ func (c *Client) ListGroups(ctx context.Context) ([]Group, error) {
 
 // CreateGroup creates a new group
 func (c *Client) CreateGroup(ctx context.Context, groupID, comment string) (interface{}, error) {
-	body := map[string]interface{}{
-		"groupid": groupID,
-	}
-	if comment != "" {
-		body["comment"] = comment
-	}
+	body := params{}.
+		set("groupid", groupID).
+		opt("comment", comment)
 
 	return c.doRequest(ctx, "POST", "access/groups", body)
 }
@@ -158,21 +137,9 @@ func (c *Client) ListRoles(ctx context.Context) ([]Role, error) {
 
 // CreateRole creates a new role with specified privileges
 func (c *Client) CreateRole(ctx context.Context, roleID string, privs []string) (interface{}, error) {
-	// Convert privileges array to comma-separated string
-	privsStr := ""
-	if len(privs) > 0 {
-		for i, priv := range privs {
-			if i > 0 {
-				privsStr += ","
-			}
-			privsStr += priv
-		}
-	}
-
-	body := map[string]interface{}{
-		"roleid": roleID,
-		"privs":  privsStr,
-	}
+	body := params{}.
+		set("roleid", roleID).
+		opt("privs", privs)
 
 	return c.doRequest(ctx, "POST", "access/roles", body)
 }
@@ -199,20 +166,13 @@ func (c *Client) ListACLs(ctx context.Context) ([]ACLEntry, error) {
 
 // SetACL creates or updates an ACL entry
 func (c *Client) SetACL(ctx context.Context, path, role, userID, groupID, tokenID string, propagate bool) (interface{}, error) {
-	body := map[string]interface{}{
-		"path":  path,
-		"roles": role,
-	}
-	if userID != "" {
-		body["users"] = userID
-	}
-	if groupID != "" {
-		body["groups"] = groupID
-	}
-	if tokenID != "" {
-		body["tokens"] = tokenID
-	}
-	body["propagate"] = boolToInt(propagate)
+	body := params{}.
+		set("path", path).
+		set("roles", role).
+		opt("users", userID).
+		opt("groups", groupID).
+		opt("tokens", tokenID).
+		set("propagate", propagate)
 
 	return c.doRequest(ctx, "PUT", "access/acl", body)
 }
@@ -234,13 +194,9 @@ func (c *Client) ListAPITokens(ctx context.Context, userID string) ([]APIToken,
 
 // CreateAPIToken creates a new API token for a user
 func (c *Client) CreateAPIToken(ctx context.Context, userID, tokenID string, expire int64, privSep bool) (interface{}, error) {
-	body := map[string]interface{}{
-		"tokenid": tokenID,
-	}
-	if expire > 0 {
-		body["expire"] = expire
-	}
-	body["privsep"] = boolToInt(privSep)
+	body := params{}.
+		opt("expire", expire).
+		set("privsep", privSep)
 
 	return c.doRequest(ctx, "POST", fmt.Sprintf("access/users/%s/tokens/%s", userID, tokenID), body)
 }
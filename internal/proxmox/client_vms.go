@@ -51,6 +51,16 @@ func (c *Client) GetVMConfig(ctx context.Context, nodeName string, vmID int) (ma
 	return config, nil
 }
 
+// GetParsedVMConfig retrieves the configuration of a virtual machine with the
+// property strings of its devices parsed
+func (c *Client) GetParsedVMConfig(ctx context.Context, nodeName string, vmID int) (*VMConfig, error) {
+	config, err := c.GetVMConfig(ctx, nodeName, vmID)
+	if err != nil {
+		return nil, err
+	}
+	return ParseVMConfig(config)
+}
+
 // StartVM powers on a virtual machine
 func (c *Client) StartVM(ctx context.Context, nodeName string, vmID int) (interface{}, error) {
 	return c.doRequest(ctx, "POST", fmt.Sprintf("nodes/%s/qemu/%d/status/start", nodeName, vmID), nil)
@@ -88,33 +98,28 @@ func (c *Client) CreateVM(ctx context.Context, nodeName string, config map[strin
 
 // CreateVMFull creates a new virtual machine with full configuration
 func (c *Client) CreateVMFull(ctx context.Context, nodeName string, vmID int, name string, memory int, cores int, sockets int) (interface{}, error) {
-	config := map[string]interface{}{
-		"vmid":    vmID,
-		"name":    name,
-		"memory":  memory,
-		"cores":   cores,
-		"sockets": sockets,
-	}
+	config := params{}.
+		set("vmid", vmID).
+		set("name", name).
+		opt("memory", memory).
+		opt("cores", cores).
+		opt("sockets", sockets)
 	return c.CreateVM(ctx, nodeName, config)
 }
 
 // DeleteVM deletes a virtual machine
 func (c *Client) DeleteVM(ctx context.Context, nodeName string, vmID int, force bool) (interface{}, error) {
-	endpoint := fmt.Sprintf("nodes/%s/qemu/%d", nodeName, vmID)
-	if force {
-		endpoint += "?force=1"
-	}
-	return c.doRequest(ctx, "DELETE", endpoint, nil)
+	body := params{}.opt("force", force)
+	return c.doRequest(ctx, "DELETE", fmt.Sprintf("nodes/%s/qemu/%d", nodeName, vmID), body)
 }
 
 // CloneVM clones an existing virtual machine
 func (c *Client) CloneVM(ctx context.Context, nodeName string, sourceVMID int, newVMID int, newName string, full bool) (interface{}, error) {
-	config := map[string]interface{}{
-		"newid": newVMID,
-		"name":  newName,
-		"full":  full,
-	}
-	return c.doRequest(ctx, "POST", fmt.Sprintf("nodes/%s/qemu/%d/clone", nodeName, sourceVMID), config)
+	body := params{}.
+		set("newid", newVMID).
+		opt("name", newName).
+		set("full", full)
+	return c.doRequest(ctx, "POST", fmt.Sprintf("nodes/%s/qemu/%d/clone", nodeName, sourceVMID), body)
 }
 
 // UpdateVM updates a virtual machine's configuration
@@ -122,6 +127,44 @@ func (c *Client) UpdateVM(ctx context.Context, nodeName string, vmID int, config
 	return c.doRequest(ctx, "PUT", fmt.Sprintf("nodes/%s/qemu/%d/config", nodeName, vmID), config)
 }
 
+// UpdateParsedVMConfig writes the changes from original, as read with
+// GetParsedVMConfig, to updated. Only changed settings are sent, and the
+// update fails if the config was modified since original was read.
+func (c *Client) UpdateParsedVMConfig(ctx context.Context, nodeName string, vmID int, original, updated *VMConfig) (interface{}, error) {
+	changed, removed := original.Changes(updated)
+	if len(changed) == 0 && len(removed) == 0 {
+		return nil, fmt.Errorf("no configuration changes")
+	}
+	body := params{}
+	for key, value := range changed {
+		body.set(key, value)
+	}
+	body.opt("delete", removed).opt("digest", original.Digest)
+	return c.doRequest(ctx, "PUT", fmt.Sprintf("nodes/%s/qemu/%d/config", nodeName, vmID), body)
+}
+
+// EditVMConfig reads the parsed config of a VM, lets edit change a copy of
+// it and writes the changes back with UpdateParsedVMConfig. It returns the
+// edited config and the result of the update.
+func (c *Client) EditVMConfig(ctx context.Context, nodeName string, vmID int, edit func(config *VMConfig) error) (*VMConfig, interface{}, error) {
+	original, err := c.GetParsedVMConfig(ctx, nodeName, vmID)
+	if err != nil {
+		return nil, nil, err
+	}
+	updated, err := original.Clone()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := edit(updated); err != nil {
+		return nil, nil, err
+	}
+	result, err := c.UpdateParsedVMConfig(ctx, nodeName, vmID, original, updated)
+	if err != nil {
+		return nil, nil, err
+	}
+	return updated, result, nil
+}
+
 // GetVMConsole gets console access information for a VM
 func (c *Client) GetVMConsole(ctx context.Context, nodeName string, vmID int) (map[string]interface{}, error) {
 	data, err := c.doRequest(ctx, "GET", fmt.Sprintf("nodes/%s/qemu/%d/status/current", nodeName, vmID), nil)
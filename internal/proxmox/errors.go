@@ -0,0 +1,168 @@
+package proxmox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// APIError is returned for non-2xx responses of the Proxmox API
+type APIError struct {
+	// StatusCode is the HTTP status code
+	StatusCode int `json:"status"`
+	// Message is the reason Proxmox reported, taken from the status line or
+	// the response body
+	Message string `json:"message"`
+	// Errors holds per-parameter validation messages
+	Errors map[string]string `json:"errors,omitempty"`
+	// Method and Endpoint identify the failed request
+	Method   string `json:"method"`
+	Endpoint string `json:"endpoint"`
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "API error (status %d) on %s %s", e.StatusCode, e.Method, e.Endpoint)
+	if e.Message != "" {
+		b.WriteString(": ")
+		b.WriteString(e.Message)
+	}
+	if len(e.Errors) > 0 {
+		keys := make([]string, 0, len(e.Errors))
+		for key := range e.Errors {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(&b, "; %s: %s", key, strings.TrimSpace(e.Errors[key]))
+		}
+	}
+	return b.String()
+}
+
+// newAPIError builds an APIError from a failed response
+func newAPIError(method, endpoint string, resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Method:     method,
+		Endpoint:   endpoint,
+	}
+
+	// pveproxy puts the error reason in the status line, e.g. "500 VM 100 not running"
+	apiErr.Message = strings.TrimSpace(strings.TrimPrefix(resp.Status, strconv.Itoa(resp.StatusCode)))
+
+	var payload struct {
+		Message string            `json:"message"`
+		Errors  map[string]string `json:"errors"`
+	}
+	if json.Unmarshal(body, &payload) == nil {
+		if payload.Message != "" {
+			apiErr.Message = strings.TrimSpace(payload.Message)
+		}
+		apiErr.Errors = payload.Errors
+	} else if text := strings.TrimSpace(string(body)); text != "" && apiErr.Message == "" {
+		apiErr.Message = text
+	}
+
+	return apiErr
+}
+
+// ErrGuestNotFound is returned when no VM or container matches a GuestSelector
+var ErrGuestNotFound = errors.New("guest not found")
+
+// AmbiguousGuestError is returned when a name or tag selects several guests
+type AmbiguousGuestError struct {
+	Selector GuestSelector
+	Matches  []Guest
+}
+
+// Error implements the error interface
+func (e *AmbiguousGuestError) Error() string {
+	matches := make([]string, len(e.Matches))
+	for i, guest := range e.Matches {
+		matches[i] = guest.String()
+	}
+	return fmt.Sprintf("%s is ambiguous, it matches %d guests: %s; use the VMID instead",
+		e.Selector, len(e.Matches), strings.Join(matches, ", "))
+}
+
+// IsNotFound reports whether err means the requested object does not exist
+func IsNotFound(err error) bool {
+	if errors.Is(err, ErrGuestNotFound) {
+		return true
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	if apiErr.StatusCode == http.StatusNotFound {
+		return true
+	}
+	// Proxmox reports missing guests and storage as 500 with a descriptive message
+	message := strings.ToLower(apiErr.Message)
+	return apiErr.StatusCode == http.StatusInternalServerError &&
+		(strings.Contains(message, "does not exist") || strings.Contains(message, "not found") || strings.Contains(message, "no such"))
+}
+
+// isUnauthorized reports whether the API rejected the request's credentials
+func isUnauthorized(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized
+}
+
+// IsPermissionDenied reports whether err was caused by missing credentials or privileges
+func IsPermissionDenied(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode == http.StatusUnauthorized ||
+		apiErr.StatusCode == http.StatusForbidden ||
+		strings.Contains(strings.ToLower(apiErr.Message), "permission check failed")
+}
+
+// IsRetryable reports whether the failed request may succeed if sent again,
+// e.g. during a pveproxy restart or while a node is unreachable
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout,
+			595, // no route to node / connection refused by pveproxy
+			596: // connection timed out to node
+			return true
+		case http.StatusInternalServerError:
+			message := strings.ToLower(apiErr.Message)
+			return strings.Contains(message, "got timeout") || strings.Contains(message, "no quorum") ||
+				strings.Contains(message, "can't lock file")
+		}
+		return false
+	}
+
+	return isTransportFailure(err)
+}
+
+// isTransportFailure reports whether err is a network-level failure such as a
+// refused connection or a timeout, as opposed to e.g. a TLS verification error
+func isTransportFailure(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout() && !errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,150 @@
+package proxmox
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Proxmox encodes structured config values such as disks and network devices
+// as property strings: comma-separated key=value pairs, optionally led by a
+// bare value for the format's default key, e.g.
+// "local-lvm:vm-100-disk-0,iothread=1,size=32G".
+
+// parsePropertyString splits a property string into its values by key. A
+// leading value without a key is stored under defaultKey.
+func parsePropertyString(s, defaultKey string) (map[string]string, error) {
+	values := make(map[string]string)
+	if strings.TrimSpace(s) == "" {
+		return values, nil
+	}
+	for i, part := range strings.Split(s, ",") {
+		key, value, found := strings.Cut(part, "=")
+		if !found {
+			if i > 0 || defaultKey == "" {
+				return nil, fmt.Errorf("invalid property %q in %q", part, s)
+			}
+			key, value = defaultKey, part
+		}
+		if _, duplicate := values[key]; duplicate {
+			return nil, fmt.Errorf("duplicate property %q in %q", key, s)
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+// formatPropertyString joins values into a property string the way Proxmox
+// prints them: the value of defaultKey first without its key, then the other
+// keys in alphabetical order. Empty values are left out.
+func formatPropertyString(values map[string]string, defaultKey string) string {
+	keys := make([]string, 0, len(values))
+	for key, value := range values {
+		if key != defaultKey && value != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys)+1)
+	if value := values[defaultKey]; defaultKey != "" && value != "" {
+		parts = append(parts, value)
+	}
+	for _, key := range keys {
+		parts = append(parts, key+"="+values[key])
+	}
+	return strings.Join(parts, ",")
+}
+
+// takeString removes key from values and returns its value
+func takeString(values map[string]string, key string) string {
+	value := values[key]
+	delete(values, key)
+	return value
+}
+
+// takeBool removes a 0/1 flag from values. It returns nil when the flag is
+// not set, so that an explicit 0 survives a round trip.
+func takeBool(values map[string]string, key string) (*bool, error) {
+	value, ok := values[key]
+	if !ok {
+		return nil, nil
+	}
+	delete(values, key)
+	switch value {
+	case "1", "on", "yes", "true":
+		return boolPtr(true), nil
+	case "0", "off", "no", "false":
+		return boolPtr(false), nil
+	}
+	return nil, fmt.Errorf("invalid value %q for %s", value, key)
+}
+
+// takeBools removes the 0/1 flags by key from values into flags
+func takeBools(values map[string]string, flags map[string]**bool) error {
+	for key, flag := range flags {
+		var err error
+		if *flag, err = takeBool(values, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// putBool stores a 0/1 flag set with takeBool
+func putBool(values map[string]string, key string, flag *bool) {
+	if flag != nil {
+		values[key] = strconv.Itoa(boolToInt(*flag))
+	}
+}
+
+// takeInt removes a number from values. It returns 0 when the number is not
+// set.
+func takeInt(values map[string]string, key string) (int, error) {
+	value, ok := values[key]
+	if !ok {
+		return 0, nil
+	}
+	delete(values, key)
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q for %s", value, key)
+	}
+	return n, nil
+}
+
+// putInt stores a number set with takeInt
+func putInt(values map[string]string, key string, n int) {
+	if n != 0 {
+		values[key] = strconv.Itoa(n)
+	}
+}
+
+// takeList removes a semicolon-separated list such as "scsi0;net0" from
+// values
+func takeList(values map[string]string, key string) []string {
+	value := takeString(values, key)
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ";")
+}
+
+// putList stores a list set with takeList
+func putList(values map[string]string, key string, list []string) {
+	values[key] = strings.Join(list, ";")
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// withExtra returns a copy of extra to add the typed fields to
+func withExtra(extra map[string]string) map[string]string {
+	values := make(map[string]string, len(extra)+8)
+	for key, value := range extra {
+		values[key] = value
+	}
+	return values
+}
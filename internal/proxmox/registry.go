@@ -0,0 +1,119 @@
+package proxmox
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ClusterInfo describes a cluster registered in a Registry
+type ClusterInfo struct {
+	Name        string `json:"name"`
+	BaseURL     string `json:"base_url"`
+	Description string `json:"description,omitempty"`
+	Default     bool   `json:"default"`
+	// Endpoints lists the health of each member API URL when the cluster has
+	// more than one
+	Endpoints []EndpointStatus `json:"endpoints,omitempty"`
+}
+
+// Registry holds the clients of several named Proxmox clusters
+type Registry struct {
+	mu          sync.RWMutex
+	clients     map[string]*Client
+	info        map[string]ClusterInfo
+	defaultName string
+}
+
+// NewRegistry creates an empty cluster registry
+func NewRegistry() *Registry {
+	return &Registry{
+		clients: make(map[string]*Client),
+		info:    make(map[string]ClusterInfo),
+	}
+}
+
+// Add registers the client of a cluster. The first cluster added becomes the
+// default until SetDefault is called.
+func (r *Registry) Add(name, description string, client *Client) error {
+	if name == "" {
+		return fmt.Errorf("cluster name is required")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.clients[name]; exists {
+		return fmt.Errorf("cluster %q is already registered", name)
+	}
+	r.clients[name] = client
+	r.info[name] = ClusterInfo{Name: name, BaseURL: client.BaseURL(), Description: description}
+	if r.defaultName == "" {
+		r.defaultName = name
+	}
+	return nil
+}
+
+// SetDefault selects the cluster used when no cluster is named
+func (r *Registry) SetDefault(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.clients[name]; !ok {
+		return fmt.Errorf("unknown cluster %q", name)
+	}
+	r.defaultName = name
+	return nil
+}
+
+// Get returns the client of the named cluster, or of the default cluster when
+// name is empty
+func (r *Registry) Get(name string) (*Client, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if name == "" {
+		name = r.defaultName
+	}
+	client, ok := r.clients[name]
+	if !ok {
+		if len(r.clients) == 0 {
+			return nil, fmt.Errorf("no clusters configured")
+		}
+		return nil, fmt.Errorf("unknown cluster %q (available: %v)", name, r.namesLocked())
+	}
+	return client, nil
+}
+
+// Default returns the client of the default cluster
+func (r *Registry) Default() *Client {
+	client, _ := r.Get("")
+	return client
+}
+
+// Clusters lists the registered clusters sorted by name
+func (r *Registry) Clusters() []ClusterInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	clusters := make([]ClusterInfo, 0, len(r.info))
+	for _, name := range r.namesLocked() {
+		info := r.info[name]
+		info.Default = name == r.defaultName
+		info.BaseURL = r.clients[name].BaseURL()
+		if endpoints := r.clients[name].Endpoints(); len(endpoints) > 1 {
+			info.Endpoints = endpoints
+		}
+		clusters = append(clusters, info)
+	}
+	return clusters
+}
+
+func (r *Registry) namesLocked() []string {
+	names := make([]string, 0, len(r.clients))
+	for name := range r.clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -1,8 +1,19 @@
 package proxmox
 
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
 // APIResponse represents a standard Proxmox API response
 type APIResponse struct {
 	Data interface{} `json:"data"`
+	// Total is the number of items of a paged list, such as a task log
+	Total int `json:"total,omitempty"`
 }
 
 // Node represents a Proxmox node
@@ -104,16 +115,51 @@ type NodeStatus struct {
 
 // Task represents a background task
 type Task struct {
+	UPID      string `json:"upid,omitempty"`
 	ID        string `json:"id"`
 	Node      string `json:"node"`
 	PID       int    `json:"pid,omitempty"`
 	PPID      int    `json:"ppid,omitempty"`
+	PStart    int64  `json:"pstart,omitempty"`
 	Starttime int64  `json:"starttime,omitempty"`
+	EndTime   int64  `json:"endtime,omitempty"`
 	Type      string `json:"type,omitempty"`
 	User      string `json:"user,omitempty"`
 	Status    string `json:"status,omitempty"`
 }
 
+// UPID is a parsed Proxmox task identifier
+// (UPID:node:pid:pstart:starttime:type:id:user:)
+type UPID struct {
+	Node      string    `json:"node"`
+	PID       int       `json:"pid"`
+	PStart    int64     `json:"pstart"`
+	StartTime time.Time `json:"starttime"`
+	Type      string    `json:"type"`
+	ID        string    `json:"id,omitempty"`
+	User      string    `json:"user"`
+	raw       string
+}
+
+// TaskLogLine is a single line of a task log
+type TaskLogLine struct {
+	N int    `json:"n"`
+	T string `json:"t"`
+}
+
+// TaskFilter narrows the task list of a node
+type TaskFilter struct {
+	TypeFilter string    // task type, e.g. "qmstart" or "vzdump"
+	VMID       int       // only tasks of this guest
+	Errors     bool      // only failed tasks
+	Since      time.Time // only tasks started at or after this time
+	Until      time.Time // only tasks started at or before this time
+	Source     string    // "archive" (default), "active" or "all"
+	UserFilter string    // only tasks of this user
+	Start      int       // offset into the list
+	Limit      int       // maximum number of tasks (Proxmox default: 50)
+}
+
 // Cluster represents cluster information
 type Cluster struct {
 	Name       string `json:"name"`
@@ -170,3 +216,651 @@ type ACLEntry struct {
 	IssueTime   int64  `json:"issuetime,omitempty"`
 	ExpiresTime int64  `json:"expirestime,omitempty"`
 }
+
+// diskSlot matches the config keys of VM disks: ide0, sata1, scsi2, virtio3
+// and the unused volumes unused0, unused1, ...
+var diskSlot = regexp.MustCompile(`^(ide|sata|scsi|virtio|unused)(\d+)$`)
+
+// IsDiskSlot reports whether key is the config key of a VM disk
+func IsDiskSlot(key string) bool {
+	return diskSlot.MatchString(key)
+}
+
+// Disk is a drive of a VM config, parsed from a property string such as
+// "local-lvm:vm-100-disk-0,cache=writeback,iothread=1,size=32G". Options
+// without a field of their own are kept in Extra, so String reproduces the
+// value Proxmox returned.
+type Disk struct {
+	Slot      string            `json:"slot"`              // config key, e.g. scsi0
+	Bus       string            `json:"bus"`               // ide, sata, scsi, virtio or unused
+	Index     int               `json:"index"`             // number of the slot on its bus
+	Volume    string            `json:"volume"`            // volume ID, or none or cdrom for empty drives
+	Storage   string            `json:"storage,omitempty"` // storage of the volume
+	Size      string            `json:"size,omitempty"`    // e.g. 32G
+	Media     string            `json:"media,omitempty"`   // disk or cdrom
+	Cache     string            `json:"cache,omitempty"`   // none, writethrough, writeback, unsafe or directsync
+	Format    string            `json:"format,omitempty"`  // raw, qcow2 or vmdk
+	Discard   string            `json:"discard,omitempty"` // on or ignore
+	IOThread  *bool             `json:"iothread,omitempty"`
+	SSD       *bool             `json:"ssd,omitempty"`
+	Backup    *bool             `json:"backup,omitempty"`
+	Replicate *bool             `json:"replicate,omitempty"`
+	ReadOnly  *bool             `json:"ro,omitempty"`
+	Extra     map[string]string `json:"extra,omitempty"`
+}
+
+// ParseDisk parses the value of the disk config key slot
+func ParseDisk(slot, value string) (Disk, error) {
+	match := diskSlot.FindStringSubmatch(slot)
+	if match == nil {
+		return Disk{}, fmt.Errorf("%s is not a disk slot", slot)
+	}
+	values, err := parsePropertyString(value, "file")
+	if err != nil {
+		return Disk{}, fmt.Errorf("%s: %w", slot, err)
+	}
+
+	disk := Disk{Slot: slot, Bus: match[1]}
+	disk.Index, _ = strconv.Atoi(match[2])
+	disk.Volume = takeString(values, "file")
+	disk.Size = takeString(values, "size")
+	disk.Media = takeString(values, "media")
+	disk.Cache = takeString(values, "cache")
+	disk.Format = takeString(values, "format")
+	disk.Discard = takeString(values, "discard")
+	if err := takeBools(values, map[string]**bool{
+		"iothread":  &disk.IOThread,
+		"ssd":       &disk.SSD,
+		"backup":    &disk.Backup,
+		"replicate": &disk.Replicate,
+		"ro":        &disk.ReadOnly,
+	}); err != nil {
+		return Disk{}, fmt.Errorf("%s: %w", slot, err)
+	}
+	disk.Storage = volumeStorage(disk.Volume)
+	if len(values) > 0 {
+		disk.Extra = values
+	}
+	return disk, nil
+}
+
+// String returns the disk as a config value
+func (d Disk) String() string {
+	values := withExtra(d.Extra)
+	values["file"] = d.Volume
+	values["size"] = d.Size
+	values["media"] = d.Media
+	values["cache"] = d.Cache
+	values["format"] = d.Format
+	values["discard"] = d.Discard
+	putBool(values, "iothread", d.IOThread)
+	putBool(values, "ssd", d.SSD)
+	putBool(values, "backup", d.Backup)
+	putBool(values, "replicate", d.Replicate)
+	putBool(values, "ro", d.ReadOnly)
+	return formatPropertyString(values, "file")
+}
+
+// IsCDROM reports whether the drive is a CD/DVD drive rather than a disk
+func (d Disk) IsCDROM() bool {
+	return d.Media == "cdrom" || d.Volume == "cdrom"
+}
+
+// volumeStorage returns the storage of a volume ID such as
+// local-lvm:vm-100-disk-0
+func volumeStorage(volume string) string {
+	if storage, _, found := strings.Cut(volume, ":"); found {
+		return storage
+	}
+	return ""
+}
+
+// deviceSlot matches the config keys of network devices and PCI and USB
+// passthrough: net0, hostpci1, usb2, ...
+var deviceSlot = regexp.MustCompile(`^(net|hostpci|usb)(\d+)$`)
+
+// netOptions are the keys of a network device property string other than
+// the model, which Proxmox writes as model=MAC
+var netOptions = map[string]bool{
+	"bridge": true, "firewall": true, "link_down": true, "macaddr": true, "model": true,
+	"mtu": true, "queues": true, "rate": true, "tag": true, "trunks": true,
+}
+
+// NetworkDevice is a network device of a VM config, parsed from a property
+// string such as "virtio=BC:24:11:2B:3C:4D,bridge=vmbr0,firewall=1,tag=10"
+type NetworkDevice struct {
+	Slot       string            `json:"slot"` // config key, e.g. net0
+	Index      int               `json:"index"`
+	Model      string            `json:"model"` // virtio, e1000, rtl8139, vmxnet3, ...
+	MACAddress string            `json:"macaddr,omitempty"`
+	Bridge     string            `json:"bridge,omitempty"`
+	Tag        int               `json:"tag,omitempty"`    // VLAN tag
+	Trunks     []string          `json:"trunks,omitempty"` // VLAN IDs or ranges passed through, e.g. 10-20
+	Firewall   *bool             `json:"firewall,omitempty"`
+	LinkDown   *bool             `json:"link_down,omitempty"`
+	MTU        int               `json:"mtu,omitempty"`
+	Queues     int               `json:"queues,omitempty"`
+	Rate       string            `json:"rate,omitempty"` // limit in MB/s
+	Extra      map[string]string `json:"extra,omitempty"`
+}
+
+// ParseNetworkDevice parses the value of the network device config key slot
+func ParseNetworkDevice(slot, value string) (NetworkDevice, error) {
+	match := deviceSlot.FindStringSubmatch(slot)
+	if match == nil || match[1] != "net" {
+		return NetworkDevice{}, fmt.Errorf("%s is not a network device slot", slot)
+	}
+	values, err := parsePropertyString(value, "model")
+	if err != nil {
+		return NetworkDevice{}, fmt.Errorf("%s: %w", slot, err)
+	}
+	if _, ok := values["model"]; !ok {
+		first, _, _ := strings.Cut(value, ",")
+		if model, mac, found := strings.Cut(first, "="); found && !netOptions[model] {
+			delete(values, model)
+			values["model"] = model
+			values["macaddr"] = mac
+		}
+	}
+
+	net := NetworkDevice{Slot: slot}
+	net.Index, _ = strconv.Atoi(match[2])
+	net.Model = takeString(values, "model")
+	net.MACAddress = takeString(values, "macaddr")
+	net.Bridge = takeString(values, "bridge")
+	net.Trunks = takeList(values, "trunks")
+	net.Rate = takeString(values, "rate")
+	for key, n := range map[string]*int{"tag": &net.Tag, "mtu": &net.MTU, "queues": &net.Queues} {
+		if *n, err = takeInt(values, key); err != nil {
+			return NetworkDevice{}, fmt.Errorf("%s: %w", slot, err)
+		}
+	}
+	if err := takeBools(values, map[string]**bool{"firewall": &net.Firewall, "link_down": &net.LinkDown}); err != nil {
+		return NetworkDevice{}, fmt.Errorf("%s: %w", slot, err)
+	}
+	if len(values) > 0 {
+		net.Extra = values
+	}
+	return net, nil
+}
+
+// String returns the network device as a config value, led by model=MAC
+func (n NetworkDevice) String() string {
+	values := withExtra(n.Extra)
+	values["bridge"] = n.Bridge
+	values["rate"] = n.Rate
+	putList(values, "trunks", n.Trunks)
+	putInt(values, "tag", n.Tag)
+	putInt(values, "mtu", n.MTU)
+	putInt(values, "queues", n.Queues)
+	putBool(values, "firewall", n.Firewall)
+	putBool(values, "link_down", n.LinkDown)
+
+	model := n.Model
+	switch {
+	case model != "" && n.MACAddress != "":
+		model += "=" + n.MACAddress
+	case model == "":
+		values["macaddr"] = n.MACAddress
+	}
+	if options := formatPropertyString(values, ""); options != "" {
+		if model == "" {
+			return options
+		}
+		return model + "," + options
+	}
+	return model
+}
+
+// CPU is the cpu setting of a VM config, e.g. "host" or
+// "x86-64-v2-AES,flags=+pcid;-spec-ctrl"
+type CPU struct {
+	Type          string            `json:"cputype,omitempty"` // e.g. host, x86-64-v2-AES or a custom model
+	Flags         []string          `json:"flags,omitempty"`   // CPU flags turned on or off, e.g. +aes or -pcid
+	Hidden        *bool             `json:"hidden,omitempty"`  // hide the KVM signature from the guest
+	HVVendorID    string            `json:"hv-vendor-id,omitempty"`
+	PhysBits      string            `json:"phys-bits,omitempty"` // a number of bits or host
+	ReportedModel string            `json:"reported-model,omitempty"`
+	Extra         map[string]string `json:"extra,omitempty"`
+}
+
+// ParseCPU parses the value of the cpu config key
+func ParseCPU(value string) (CPU, error) {
+	values, err := parsePropertyString(value, "cputype")
+	if err != nil {
+		return CPU{}, fmt.Errorf("cpu: %w", err)
+	}
+	cpu := CPU{
+		Type:          takeString(values, "cputype"),
+		Flags:         takeList(values, "flags"),
+		HVVendorID:    takeString(values, "hv-vendor-id"),
+		PhysBits:      takeString(values, "phys-bits"),
+		ReportedModel: takeString(values, "reported-model"),
+	}
+	if cpu.Hidden, err = takeBool(values, "hidden"); err != nil {
+		return CPU{}, fmt.Errorf("cpu: %w", err)
+	}
+	if len(values) > 0 {
+		cpu.Extra = values
+	}
+	return cpu, nil
+}
+
+// String returns the CPU setting as a config value
+func (c CPU) String() string {
+	values := withExtra(c.Extra)
+	values["cputype"] = c.Type
+	putList(values, "flags", c.Flags)
+	putBool(values, "hidden", c.Hidden)
+	values["hv-vendor-id"] = c.HVVendorID
+	values["phys-bits"] = c.PhysBits
+	values["reported-model"] = c.ReportedModel
+	return formatPropertyString(values, "cputype")
+}
+
+// BootOrder is the boot setting of a VM config, e.g. "order=scsi0;ide2;net0"
+type BootOrder struct {
+	Order  []string          `json:"order,omitempty"`  // devices tried in turn, e.g. scsi0
+	Legacy string            `json:"legacy,omitempty"` // deprecated drive letters, e.g. cdn
+	Extra  map[string]string `json:"extra,omitempty"`
+}
+
+// ParseBootOrder parses the value of the boot config key
+func ParseBootOrder(value string) (BootOrder, error) {
+	values, err := parsePropertyString(value, "legacy")
+	if err != nil {
+		return BootOrder{}, fmt.Errorf("boot: %w", err)
+	}
+	boot := BootOrder{
+		Order:  takeList(values, "order"),
+		Legacy: takeString(values, "legacy"),
+	}
+	if len(values) > 0 {
+		boot.Extra = values
+	}
+	return boot, nil
+}
+
+// String returns the boot order as a config value
+func (b BootOrder) String() string {
+	values := withExtra(b.Extra)
+	values["legacy"] = b.Legacy
+	putList(values, "order", b.Order)
+	return formatPropertyString(values, "legacy")
+}
+
+// EFIDisk is the efidisk0 setting of a VM config, the disk holding the EFI
+// variables, e.g. "local-lvm:vm-100-disk-1,efitype=4m,pre-enrolled-keys=1,size=4M"
+type EFIDisk struct {
+	Volume          string            `json:"volume"`
+	Storage         string            `json:"storage,omitempty"`
+	Size            string            `json:"size,omitempty"`
+	Format          string            `json:"format,omitempty"`
+	EFIType         string            `json:"efitype,omitempty"` // 2m or 4m
+	PreEnrolledKeys *bool             `json:"pre-enrolled-keys,omitempty"`
+	Extra           map[string]string `json:"extra,omitempty"`
+}
+
+// ParseEFIDisk parses the value of the efidisk0 config key
+func ParseEFIDisk(value string) (EFIDisk, error) {
+	values, err := parsePropertyString(value, "file")
+	if err != nil {
+		return EFIDisk{}, fmt.Errorf("efidisk0: %w", err)
+	}
+	disk := EFIDisk{
+		Volume:  takeString(values, "file"),
+		Size:    takeString(values, "size"),
+		Format:  takeString(values, "format"),
+		EFIType: takeString(values, "efitype"),
+	}
+	disk.Storage = volumeStorage(disk.Volume)
+	if disk.PreEnrolledKeys, err = takeBool(values, "pre-enrolled-keys"); err != nil {
+		return EFIDisk{}, fmt.Errorf("efidisk0: %w", err)
+	}
+	if len(values) > 0 {
+		disk.Extra = values
+	}
+	return disk, nil
+}
+
+// String returns the EFI disk as a config value
+func (d EFIDisk) String() string {
+	values := withExtra(d.Extra)
+	values["file"] = d.Volume
+	values["size"] = d.Size
+	values["format"] = d.Format
+	values["efitype"] = d.EFIType
+	putBool(values, "pre-enrolled-keys", d.PreEnrolledKeys)
+	return formatPropertyString(values, "file")
+}
+
+// TPMState is the tpmstate0 setting of a VM config, the disk holding the
+// state of the virtual TPM, e.g. "local-lvm:vm-100-disk-2,size=4M,version=v2.0"
+type TPMState struct {
+	Volume  string            `json:"volume"`
+	Storage string            `json:"storage,omitempty"`
+	Size    string            `json:"size,omitempty"`
+	Version string            `json:"version,omitempty"` // v1.2 or v2.0
+	Extra   map[string]string `json:"extra,omitempty"`
+}
+
+// ParseTPMState parses the value of the tpmstate0 config key
+func ParseTPMState(value string) (TPMState, error) {
+	values, err := parsePropertyString(value, "file")
+	if err != nil {
+		return TPMState{}, fmt.Errorf("tpmstate0: %w", err)
+	}
+	tpm := TPMState{
+		Volume:  takeString(values, "file"),
+		Size:    takeString(values, "size"),
+		Version: takeString(values, "version"),
+	}
+	tpm.Storage = volumeStorage(tpm.Volume)
+	if len(values) > 0 {
+		tpm.Extra = values
+	}
+	return tpm, nil
+}
+
+// String returns the TPM state as a config value
+func (t TPMState) String() string {
+	values := withExtra(t.Extra)
+	values["file"] = t.Volume
+	values["size"] = t.Size
+	values["version"] = t.Version
+	return formatPropertyString(values, "file")
+}
+
+// HostPCI is a PCI device passed through to a VM, parsed from a property
+// string such as "0000:01:00,pcie=1,x-vga=1"
+type HostPCI struct {
+	Slot    string            `json:"slot"` // config key, e.g. hostpci0
+	Index   int               `json:"index"`
+	Host    []string          `json:"host,omitempty"`    // PCI IDs of the host devices, e.g. 0000:01:00.0
+	Mapping string            `json:"mapping,omitempty"` // cluster resource mapping used instead of host
+	MDev    string            `json:"mdev,omitempty"`    // mediated device type
+	PCIe    *bool             `json:"pcie,omitempty"`
+	ROMBar  *bool             `json:"rombar,omitempty"`
+	ROMFile string            `json:"romfile,omitempty"`
+	XVGA    *bool             `json:"x-vga,omitempty"` // primary GPU of the VM
+	Extra   map[string]string `json:"extra,omitempty"`
+}
+
+// ParseHostPCI parses the value of the PCI passthrough config key slot
+func ParseHostPCI(slot, value string) (HostPCI, error) {
+	match := deviceSlot.FindStringSubmatch(slot)
+	if match == nil || match[1] != "hostpci" {
+		return HostPCI{}, fmt.Errorf("%s is not a PCI passthrough slot", slot)
+	}
+	values, err := parsePropertyString(value, "host")
+	if err != nil {
+		return HostPCI{}, fmt.Errorf("%s: %w", slot, err)
+	}
+	pci := HostPCI{
+		Slot:    slot,
+		Host:    takeList(values, "host"),
+		Mapping: takeString(values, "mapping"),
+		MDev:    takeString(values, "mdev"),
+		ROMFile: takeString(values, "romfile"),
+	}
+	pci.Index, _ = strconv.Atoi(match[2])
+	if err := takeBools(values, map[string]**bool{"pcie": &pci.PCIe, "rombar": &pci.ROMBar, "x-vga": &pci.XVGA}); err != nil {
+		return HostPCI{}, fmt.Errorf("%s: %w", slot, err)
+	}
+	if len(values) > 0 {
+		pci.Extra = values
+	}
+	return pci, nil
+}
+
+// String returns the PCI device as a config value
+func (p HostPCI) String() string {
+	values := withExtra(p.Extra)
+	putList(values, "host", p.Host)
+	values["mapping"] = p.Mapping
+	values["mdev"] = p.MDev
+	values["romfile"] = p.ROMFile
+	putBool(values, "pcie", p.PCIe)
+	putBool(values, "rombar", p.ROMBar)
+	putBool(values, "x-vga", p.XVGA)
+	return formatPropertyString(values, "host")
+}
+
+// USBDevice is a USB device passed through to a VM, parsed from a property
+// string such as "host=046d:c52b,usb3=1" or "spice"
+type USBDevice struct {
+	Slot    string            `json:"slot"` // config key, e.g. usb0
+	Index   int               `json:"index"`
+	Host    string            `json:"host,omitempty"`    // vendor:product ID, bus-port, or spice for SPICE redirection
+	Mapping string            `json:"mapping,omitempty"` // cluster resource mapping used instead of host
+	USB3    *bool             `json:"usb3,omitempty"`
+	Extra   map[string]string `json:"extra,omitempty"`
+}
+
+// ParseUSBDevice parses the value of the USB passthrough config key slot
+func ParseUSBDevice(slot, value string) (USBDevice, error) {
+	match := deviceSlot.FindStringSubmatch(slot)
+	if match == nil || match[1] != "usb" {
+		return USBDevice{}, fmt.Errorf("%s is not a USB passthrough slot", slot)
+	}
+	values, err := parsePropertyString(value, "host")
+	if err != nil {
+		return USBDevice{}, fmt.Errorf("%s: %w", slot, err)
+	}
+	usb := USBDevice{
+		Slot:    slot,
+		Host:    takeString(values, "host"),
+		Mapping: takeString(values, "mapping"),
+	}
+	usb.Index, _ = strconv.Atoi(match[2])
+	if usb.USB3, err = takeBool(values, "usb3"); err != nil {
+		return USBDevice{}, fmt.Errorf("%s: %w", slot, err)
+	}
+	if len(values) > 0 {
+		usb.Extra = values
+	}
+	return usb, nil
+}
+
+// String returns the USB device as a config value. The host is written with
+// its key, as the web interface does.
+func (u USBDevice) String() string {
+	values := withExtra(u.Extra)
+	values["host"] = u.Host
+	values["mapping"] = u.Mapping
+	putBool(values, "usb3", u.USB3)
+	return formatPropertyString(values, "")
+}
+
+// VMConfig is the configuration of a VM with the property strings of its
+// devices parsed. All other settings, e.g. name, memory and cores, are kept
+// as returned in Settings. Values turns the config back into the settings
+// Proxmox expects, and ParseVMConfig of those yields the same VMConfig.
+type VMConfig struct {
+	Digest   string            `json:"digest,omitempty"` // identifies the config version read
+	Disks    []Disk            `json:"disks,omitempty"`
+	Networks []NetworkDevice   `json:"networks,omitempty"`
+	CPU      *CPU              `json:"cpu,omitempty"`
+	Boot     *BootOrder        `json:"boot,omitempty"`
+	EFIDisk  *EFIDisk          `json:"efidisk,omitempty"`
+	TPMState *TPMState         `json:"tpmstate,omitempty"`
+	HostPCI  []HostPCI         `json:"hostpci,omitempty"`
+	USB      []USBDevice       `json:"usb,omitempty"`
+	Settings map[string]string `json:"settings,omitempty"`
+}
+
+// ParseVMConfig parses a VM config as returned by GetVMConfig. Devices are
+// ordered by slot.
+func ParseVMConfig(config map[string]interface{}) (*VMConfig, error) {
+	vm := &VMConfig{Settings: make(map[string]string)}
+	for key, raw := range config {
+		value := configValue(raw)
+		switch match := deviceSlot.FindStringSubmatch(key); {
+		case key == "digest":
+			vm.Digest = value
+		case IsDiskSlot(key):
+			disk, err := ParseDisk(key, value)
+			if err != nil {
+				return nil, err
+			}
+			vm.Disks = append(vm.Disks, disk)
+		case match != nil && match[1] == "net":
+			net, err := ParseNetworkDevice(key, value)
+			if err != nil {
+				return nil, err
+			}
+			vm.Networks = append(vm.Networks, net)
+		case match != nil && match[1] == "hostpci":
+			pci, err := ParseHostPCI(key, value)
+			if err != nil {
+				return nil, err
+			}
+			vm.HostPCI = append(vm.HostPCI, pci)
+		case match != nil && match[1] == "usb":
+			usb, err := ParseUSBDevice(key, value)
+			if err != nil {
+				return nil, err
+			}
+			vm.USB = append(vm.USB, usb)
+		case key == "cpu":
+			cpu, err := ParseCPU(value)
+			if err != nil {
+				return nil, err
+			}
+			vm.CPU = &cpu
+		case key == "boot":
+			boot, err := ParseBootOrder(value)
+			if err != nil {
+				return nil, err
+			}
+			vm.Boot = &boot
+		case key == "efidisk0":
+			disk, err := ParseEFIDisk(value)
+			if err != nil {
+				return nil, err
+			}
+			vm.EFIDisk = &disk
+		case key == "tpmstate0":
+			tpm, err := ParseTPMState(value)
+			if err != nil {
+				return nil, err
+			}
+			vm.TPMState = &tpm
+		default:
+			vm.Settings[key] = value
+		}
+	}
+
+	sort.Slice(vm.Disks, func(i, j int) bool {
+		if vm.Disks[i].Bus != vm.Disks[j].Bus {
+			return vm.Disks[i].Bus < vm.Disks[j].Bus
+		}
+		return vm.Disks[i].Index < vm.Disks[j].Index
+	})
+	sort.Slice(vm.Networks, func(i, j int) bool { return vm.Networks[i].Index < vm.Networks[j].Index })
+	sort.Slice(vm.HostPCI, func(i, j int) bool { return vm.HostPCI[i].Index < vm.HostPCI[j].Index })
+	sort.Slice(vm.USB, func(i, j int) bool { return vm.USB[i].Index < vm.USB[j].Index })
+	return vm, nil
+}
+
+// configValue returns a config value as the string Proxmox stores
+func configValue(raw interface{}) string {
+	switch value := raw.(type) {
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// Values returns the config as settings by key, without the digest
+func (c *VMConfig) Values() map[string]string {
+	values := make(map[string]string, len(c.Settings)+len(c.Disks)+len(c.Networks)+4)
+	for key, value := range c.Settings {
+		values[key] = value
+	}
+	for _, disk := range c.Disks {
+		values[disk.Slot] = disk.String()
+	}
+	for _, net := range c.Networks {
+		values[net.Slot] = net.String()
+	}
+	for _, pci := range c.HostPCI {
+		values[pci.Slot] = pci.String()
+	}
+	for _, usb := range c.USB {
+		values[usb.Slot] = usb.String()
+	}
+	if c.CPU != nil {
+		values["cpu"] = c.CPU.String()
+	}
+	if c.Boot != nil {
+		values["boot"] = c.Boot.String()
+	}
+	if c.EFIDisk != nil {
+		values["efidisk0"] = c.EFIDisk.String()
+	}
+	if c.TPMState != nil {
+		values["tpmstate0"] = c.TPMState.String()
+	}
+	return values
+}
+
+// Changes compares c with updated, a modified copy, and returns the
+// settings to write and the keys to delete to turn c into updated
+func (c *VMConfig) Changes(updated *VMConfig) (map[string]string, []string) {
+	current, target := c.Values(), updated.Values()
+	changed := make(map[string]string)
+	for key, value := range target {
+		if previous, ok := current[key]; !ok || previous != value {
+			changed[key] = value
+		}
+	}
+	var removed []string
+	for key := range current {
+		if _, ok := target[key]; !ok {
+			removed = append(removed, key)
+		}
+	}
+	sort.Strings(removed)
+	return changed, removed
+}
+
+// Clone returns a deep copy of c, e.g. to edit and pass to Changes
+func (c *VMConfig) Clone() (*VMConfig, error) {
+	values := c.Values()
+	config := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		config[key] = value
+	}
+	clone, err := ParseVMConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	clone.Digest = c.Digest
+	return clone, nil
+}
+
+// Disk returns the disk in slot, e.g. scsi0, or nil
+func (c *VMConfig) Disk(slot string) *Disk {
+	for i := range c.Disks {
+		if c.Disks[i].Slot == slot {
+			return &c.Disks[i]
+		}
+	}
+	return nil
+}
+
+// Network returns the network device in slot, e.g. net0, or nil
+func (c *VMConfig) Network(slot string) *NetworkDevice {
+	for i := range c.Networks {
+		if c.Networks[i].Slot == slot {
+			return &c.Networks[i]
+		}
+	}
+	return nil
+}
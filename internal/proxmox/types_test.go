@@ -0,0 +1,370 @@
+package proxmox
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// roundTripCase is a config value as Proxmox returns it, the value it parses
+// into and, when it differs from value, the value String writes back
+type roundTripCase[T fmt.Stringer] struct {
+	name      string
+	value     string
+	want      T
+	canonical string
+}
+
+// testRoundTrip checks that each value parses into want, that String writes
+// it back and that parsing that output yields want again
+func testRoundTrip[T fmt.Stringer](t *testing.T, parse func(string) (T, error), tests []roundTripCase[T]) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parse(tt.value)
+			if err != nil {
+				t.Fatalf("parse(%q) error = %v", tt.value, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("parse(%q) = %#v, want %#v", tt.value, got, tt.want)
+			}
+
+			canonical := tt.canonical
+			if canonical == "" {
+				canonical = tt.value
+			}
+			written := got.String()
+			if written != canonical {
+				t.Errorf("String() = %q, want %q", written, canonical)
+			}
+
+			again, err := parse(written)
+			if err != nil {
+				t.Fatalf("parse(%q) error = %v", written, err)
+			}
+			if !reflect.DeepEqual(again, tt.want) {
+				t.Errorf("parse(String()) = %#v, want %#v", again, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiskRoundTrip(t *testing.T) {
+	testRoundTrip(t, func(value string) (Disk, error) { return ParseDisk("scsi0", value) }, []roundTripCase[Disk]{
+		{
+			name:  "disk",
+			value: "local-lvm:vm-100-disk-0,cache=writeback,discard=on,iothread=1,size=32G,ssd=1",
+			want: Disk{
+				Slot: "scsi0", Bus: "scsi", Volume: "local-lvm:vm-100-disk-0", Storage: "local-lvm",
+				Size: "32G", Cache: "writeback", Discard: "on", IOThread: boolPtr(true), SSD: boolPtr(true),
+			},
+		},
+		{
+			name:  "explicit off flags and unknown options",
+			value: "ceph:vm-100-disk-1,aio=native,backup=0,format=raw,replicate=0,size=100G",
+			want: Disk{
+				Slot: "scsi0", Bus: "scsi", Volume: "ceph:vm-100-disk-1", Storage: "ceph", Size: "100G", Format: "raw",
+				Backup: boolPtr(false), Replicate: boolPtr(false), Extra: map[string]string{"aio": "native"},
+			},
+		},
+		{
+			name:  "cdrom",
+			value: "local:iso/ubuntu-24.04-live-server-amd64.iso,media=cdrom,size=2690412K",
+			want: Disk{
+				Slot: "scsi0", Bus: "scsi", Volume: "local:iso/ubuntu-24.04-live-server-amd64.iso", Storage: "local",
+				Size: "2690412K", Media: "cdrom",
+			},
+		},
+		{
+			name:  "empty cdrom drive",
+			value: "none,media=cdrom",
+			want:  Disk{Slot: "scsi0", Bus: "scsi", Volume: "none", Media: "cdrom"},
+		},
+		{
+			name:      "flags written as 0/1",
+			value:     "local-lvm:vm-100-disk-0,ro=on",
+			want:      Disk{Slot: "scsi0", Bus: "scsi", Volume: "local-lvm:vm-100-disk-0", Storage: "local-lvm", ReadOnly: boolPtr(true)},
+			canonical: "local-lvm:vm-100-disk-0,ro=1",
+		},
+	})
+}
+
+func TestNetworkDeviceRoundTrip(t *testing.T) {
+	testRoundTrip(t, func(value string) (NetworkDevice, error) { return ParseNetworkDevice("net0", value) }, []roundTripCase[NetworkDevice]{
+		{
+			name:  "model=MAC",
+			value: "virtio=BC:24:11:2B:3C:4D,bridge=vmbr0,firewall=1,tag=10",
+			want: NetworkDevice{
+				Slot: "net0", Model: "virtio", MACAddress: "BC:24:11:2B:3C:4D", Bridge: "vmbr0",
+				Tag: 10, Firewall: boolPtr(true),
+			},
+		},
+		{
+			name:  "all options",
+			value: "e1000=BC:24:11:00:00:01,bridge=vmbr1,link_down=1,mtu=1500,queues=4,rate=12.5,trunks=10;20-30",
+			want: NetworkDevice{
+				Slot: "net0", Model: "e1000", MACAddress: "BC:24:11:00:00:01", Bridge: "vmbr1", LinkDown: boolPtr(true),
+				MTU: 1500, Queues: 4, Rate: "12.5", Trunks: []string{"10", "20-30"},
+			},
+		},
+		{
+			name:      "model and macaddr keys",
+			value:     "bridge=vmbr0,macaddr=BC:24:11:2B:3C:4D,model=vmxnet3",
+			want:      NetworkDevice{Slot: "net0", Model: "vmxnet3", MACAddress: "BC:24:11:2B:3C:4D", Bridge: "vmbr0"},
+			canonical: "vmxnet3=BC:24:11:2B:3C:4D,bridge=vmbr0",
+		},
+		{
+			name:  "model without MAC",
+			value: "virtio,bridge=vmbr0",
+			want:  NetworkDevice{Slot: "net0", Model: "virtio", Bridge: "vmbr0"},
+		},
+	})
+}
+
+func TestCPURoundTrip(t *testing.T) {
+	testRoundTrip(t, ParseCPU, []roundTripCase[CPU]{
+		{name: "type only", value: "host", want: CPU{Type: "host"}},
+		{
+			name:  "flags",
+			value: "x86-64-v2-AES,flags=+pcid;-spec-ctrl",
+			want:  CPU{Type: "x86-64-v2-AES", Flags: []string{"+pcid", "-spec-ctrl"}},
+		},
+		{
+			name:  "custom model",
+			value: "custom-epyc,hidden=1,hv-vendor-id=proxmox,phys-bits=host,reported-model=EPYC",
+			want: CPU{
+				Type: "custom-epyc", Hidden: boolPtr(true), HVVendorID: "proxmox",
+				PhysBits: "host", ReportedModel: "EPYC",
+			},
+		},
+	})
+}
+
+func TestBootOrderRoundTrip(t *testing.T) {
+	testRoundTrip(t, ParseBootOrder, []roundTripCase[BootOrder]{
+		{name: "order", value: "order=scsi0;ide2;net0", want: BootOrder{Order: []string{"scsi0", "ide2", "net0"}}},
+		{name: "legacy drive letters", value: "cdn", want: BootOrder{Legacy: "cdn"}},
+		{name: "legacy key", value: "legacy=c", want: BootOrder{Legacy: "c"}, canonical: "c"},
+	})
+}
+
+func TestEFIDiskRoundTrip(t *testing.T) {
+	testRoundTrip(t, ParseEFIDisk, []roundTripCase[EFIDisk]{
+		{
+			name:  "efi disk",
+			value: "local-lvm:vm-100-disk-1,efitype=4m,pre-enrolled-keys=1,size=4M",
+			want: EFIDisk{
+				Volume: "local-lvm:vm-100-disk-1", Storage: "local-lvm", Size: "4M",
+				EFIType: "4m", PreEnrolledKeys: boolPtr(true),
+			},
+		},
+		{
+			name:  "qcow2 without keys",
+			value: "local:100/vm-100-disk-0.qcow2,efitype=4m,format=qcow2,pre-enrolled-keys=0,size=528K",
+			want: EFIDisk{
+				Volume: "local:100/vm-100-disk-0.qcow2", Storage: "local", Size: "528K", Format: "qcow2",
+				EFIType: "4m", PreEnrolledKeys: boolPtr(false),
+			},
+		},
+	})
+}
+
+func TestTPMStateRoundTrip(t *testing.T) {
+	testRoundTrip(t, ParseTPMState, []roundTripCase[TPMState]{
+		{
+			name:  "tpm state",
+			value: "local-lvm:vm-100-disk-2,size=4M,version=v2.0",
+			want:  TPMState{Volume: "local-lvm:vm-100-disk-2", Storage: "local-lvm", Size: "4M", Version: "v2.0"},
+		},
+	})
+}
+
+func TestHostPCIRoundTrip(t *testing.T) {
+	testRoundTrip(t, func(value string) (HostPCI, error) { return ParseHostPCI("hostpci0", value) }, []roundTripCase[HostPCI]{
+		{
+			name:  "GPU",
+			value: "0000:01:00,pcie=1,rombar=0,x-vga=1",
+			want: HostPCI{
+				Slot: "hostpci0", Host: []string{"0000:01:00"},
+				PCIe: boolPtr(true), ROMBar: boolPtr(false), XVGA: boolPtr(true),
+			},
+		},
+		{
+			name:  "several functions",
+			value: "0000:01:00.0;0000:01:00.1,romfile=vbios.bin",
+			want:  HostPCI{Slot: "hostpci0", Host: []string{"0000:01:00.0", "0000:01:00.1"}, ROMFile: "vbios.bin"},
+		},
+		{
+			name:  "mapping with mediated device",
+			value: "mapping=gpu,mdev=nvidia-63,pcie=1",
+			want:  HostPCI{Slot: "hostpci0", Mapping: "gpu", MDev: "nvidia-63", PCIe: boolPtr(true)},
+		},
+	})
+}
+
+func TestUSBDeviceRoundTrip(t *testing.T) {
+	testRoundTrip(t, func(value string) (USBDevice, error) { return ParseUSBDevice("usb0", value) }, []roundTripCase[USBDevice]{
+		{
+			name:  "vendor and product",
+			value: "host=046d:c52b,usb3=1",
+			want:  USBDevice{Slot: "usb0", Host: "046d:c52b", USB3: boolPtr(true)},
+		},
+		{name: "bus and port", value: "host=1-2.3", want: USBDevice{Slot: "usb0", Host: "1-2.3"}},
+		{name: "mapping", value: "mapping=yubikey", want: USBDevice{Slot: "usb0", Mapping: "yubikey"}},
+		{name: "spice", value: "spice", want: USBDevice{Slot: "usb0", Host: "spice"}, canonical: "host=spice"},
+	})
+}
+
+func TestVMConfigRoundTrip(t *testing.T) {
+	config := map[string]interface{}{
+		"digest":    "3f9c1e0d6a2b4c5d7e8f9a0b1c2d3e4f5a6b7c8d",
+		"name":      "web",
+		"memory":    "4096",
+		"cores":     float64(2),
+		"ostype":    "l26",
+		"scsihw":    "virtio-scsi-single",
+		"scsi0":     "local-lvm:vm-100-disk-0,iothread=1,size=32G",
+		"ide2":      "local-lvm:vm-100-cloudinit,media=cdrom",
+		"unused0":   "local-lvm:vm-100-disk-3",
+		"net0":      "virtio=BC:24:11:2B:3C:4D,bridge=vmbr0,firewall=1",
+		"cpu":       "x86-64-v2-AES",
+		"boot":      "order=scsi0;ide2;net0",
+		"efidisk0":  "local-lvm:vm-100-disk-1,efitype=4m,pre-enrolled-keys=1,size=4M",
+		"tpmstate0": "local-lvm:vm-100-disk-2,size=4M,version=v2.0",
+		"hostpci0":  "0000:01:00,pcie=1",
+		"usb0":      "spice",
+	}
+	vm, err := ParseVMConfig(config)
+	if err != nil {
+		t.Fatalf("ParseVMConfig() error = %v", err)
+	}
+	if vm.Digest != config["digest"] {
+		t.Errorf("Digest = %q", vm.Digest)
+	}
+	var slots []string
+	for _, disk := range vm.Disks {
+		slots = append(slots, disk.Slot)
+	}
+	if want := []string{"ide2", "scsi0", "unused0"}; !reflect.DeepEqual(slots, want) {
+		t.Errorf("disk slots = %v, want %v", slots, want)
+	}
+	if net := vm.Network("net0"); net == nil || net.Model != "virtio" || net.MACAddress != "BC:24:11:2B:3C:4D" {
+		t.Errorf("Network(net0) = %+v", net)
+	}
+
+	values := vm.Values()
+	for key, raw := range config {
+		want := configValue(raw)
+		switch key {
+		case "digest":
+			if _, ok := values[key]; ok {
+				t.Error("Values() includes the digest")
+			}
+			continue
+		case "usb0":
+			want = "host=spice"
+		}
+		if values[key] != want {
+			t.Errorf("Values()[%s] = %q, want %q", key, values[key], want)
+		}
+	}
+
+	again, err := ParseVMConfig(asConfig(values))
+	if err != nil {
+		t.Fatalf("ParseVMConfig(Values()) error = %v", err)
+	}
+	again.Digest = vm.Digest
+	if !reflect.DeepEqual(again, vm) {
+		t.Errorf("ParseVMConfig(Values()) = %+v, want %+v", again, vm)
+	}
+	if changed, removed := vm.Changes(again); len(changed) != 0 || len(removed) != 0 {
+		t.Errorf("Changes() of an unchanged config = %v, %v", changed, removed)
+	}
+}
+
+// asConfig turns settings from Values into a config as GetVMConfig returns it
+func asConfig(values map[string]string) map[string]interface{} {
+	config := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		config[key] = value
+	}
+	return config
+}
+
+func TestVMConfigChanges(t *testing.T) {
+	vm, err := ParseVMConfig(map[string]interface{}{
+		"name":    "web",
+		"scsi0":   "local-lvm:vm-100-disk-0,size=32G",
+		"net0":    "virtio=BC:24:11:2B:3C:4D,bridge=vmbr0",
+		"unused0": "local-lvm:vm-100-disk-3",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated, err := ParseVMConfig(asConfig(vm.Values()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated.Network("net0").Tag = 20
+	updated.Disk("scsi0").Cache = "none"
+	updated.Disks = updated.Disks[:1]
+	updated.Settings["description"] = "web server"
+
+	changed, removed := vm.Changes(updated)
+	want := map[string]string{
+		"net0":        "virtio=BC:24:11:2B:3C:4D,bridge=vmbr0,tag=20",
+		"scsi0":       "local-lvm:vm-100-disk-0,cache=none,size=32G",
+		"description": "web server",
+	}
+	if !reflect.DeepEqual(changed, want) {
+		t.Errorf("Changes() changed = %v, want %v", changed, want)
+	}
+	if !reflect.DeepEqual(removed, []string{"unused0"}) {
+		t.Errorf("Changes() removed = %v, want [unused0]", removed)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"not a disk slot", func() error { _, err := ParseDisk("efidisk0", "local-lvm:vm-100-disk-1"); return err }()},
+		{"option without key", func() error { _, err := ParseDisk("scsi0", "local-lvm:vm-100-disk-0,writeback"); return err }()},
+		{"duplicate option", func() error { _, err := ParseDisk("scsi0", "local-lvm:vm-100-disk-0,size=1G,size=2G"); return err }()},
+		{"invalid flag", func() error { _, err := ParseDisk("scsi0", "local-lvm:vm-100-disk-0,ssd=maybe"); return err }()},
+		{"invalid VLAN tag", func() error { _, err := ParseNetworkDevice("net0", "virtio,bridge=vmbr0,tag=ten"); return err }()},
+		{"not a network slot", func() error { _, err := ParseNetworkDevice("usb0", "virtio"); return err }()},
+	}
+	for _, tt := range tests {
+		if tt.err == nil {
+			t.Errorf("%s: no error", tt.name)
+		}
+	}
+}
+
+func TestVMConfigClone(t *testing.T) {
+	vm, err := ParseVMConfig(map[string]interface{}{
+		"digest": "3f9c1e0d",
+		"scsi0":  "local-lvm:vm-100-disk-0,iothread=1,size=32G",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clone, err := vm.Clone()
+	if err != nil {
+		t.Fatalf("Clone() error = %v", err)
+	}
+	if clone.Digest != vm.Digest {
+		t.Errorf("Clone() digest = %q, want %q", clone.Digest, vm.Digest)
+	}
+	clone.Disk("scsi0").SSD = boolPtr(true)
+
+	if vm.Disk("scsi0").SSD != nil {
+		t.Error("editing the clone changed the original")
+	}
+	changed, removed := vm.Changes(clone)
+	if want := map[string]string{"scsi0": "local-lvm:vm-100-disk-0,iothread=1,size=32G,ssd=1"}; !reflect.DeepEqual(changed, want) || len(removed) != 0 {
+		t.Errorf("Changes() = %v, %v, want %v", changed, removed, want)
+	}
+}